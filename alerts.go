@@ -0,0 +1,187 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// alertURL is where the failure and recovery alerts are POSTed to,
+	// no alerts are sent if empty
+	alertURL string
+	// alertAfter is how many refreshes of a brand in a row have to
+	// fail for the alert to be sent
+	alertAfter = 3
+	// staleAfter is how long a brand that is refreshed fine may go
+	// without a new episode before the alert is sent, no limit if zero
+	staleAfter time.Duration
+
+	// alerts track the failure streaks, nil unless alerting
+	alerts *alerter
+)
+
+// alertsFile is where the failure streaks are kept between the runs
+func alertsFile(path string) string {
+	return filepath.Join(path, ".alerts.json")
+}
+
+// alertState is the brand's streak of failed refreshes, and whether
+// the brand is stale
+type alertState struct {
+	Failures int       `json:"failures,omitempty"`
+	Since    time.Time `json:"since,omitempty"`
+	Alerted  bool      `json:"alerted,omitempty"`
+	Stale    bool      `json:"stale,omitempty"`
+}
+
+// alert is the JSON POSTed to -alert-url; text is there for the chat
+// webhooks that show it as is
+type alert struct {
+	Brand    string    `json:"brand"`
+	Event    string    `json:"event"`
+	Failures int       `json:"failures"`
+	Since    time.Time `json:"since"`
+	Error    string    `json:"error,omitempty"`
+	Text     string    `json:"text"`
+}
+
+// alerter sends an alert once the brand fails -alert-after times in a
+// row, and another one when it recovers; and the same when the brand
+// goes stale and gets a new episode again
+type alerter struct {
+	url   string
+	after int
+	file  string
+
+	mu     sync.Mutex
+	states map[string]alertState
+}
+
+func newAlerter(url string, after int, file string) *alerter {
+	a := &alerter{url: url, after: after, file: file, states: make(map[string]alertState)}
+	if b, err := ioutil.ReadFile(file); err == nil {
+		if err := json.Unmarshal(b, &a.states); err != nil {
+			log.Printf("ignoring %s: %v", file, err)
+		}
+	}
+	return a
+}
+
+// record accounts for the outcome of the brand's refresh, alerting if
+// the streak of failures is long enough or is over, or if the latest
+// episode is too old or is not anymore
+func (a *alerter) record(bc brandConfig, err error, latest, now time.Time) {
+	brand := bc.Brand
+	a.mu.Lock()
+	s := a.states[brand]
+	changed := false
+	var msgs []alert
+	switch {
+	case err != nil:
+		if s.Failures == 0 {
+			s.Since = now
+		}
+		s.Failures++
+		changed = true
+		if s.Failures >= a.after && !s.Alerted {
+			s.Alerted = true
+			msgs = append(msgs, alert{Brand: brand, Event: "failing", Failures: s.Failures, Since: s.Since, Error: err.Error(),
+				Text: fmt.Sprintf("brand %s: %d refreshes in a row failed since %s, last error: %v", brand, s.Failures, s.Since.In(moscow).Format("02.01.2006 15:04"), err)})
+		}
+	case s.Failures != 0:
+		if s.Alerted {
+			msgs = append(msgs, alert{Brand: brand, Event: "recovered", Failures: s.Failures, Since: s.Since,
+				Text: fmt.Sprintf("brand %s: recovered after %d failed refreshes", brand, s.Failures)})
+		}
+		s.Failures, s.Since, s.Alerted = 0, time.Time{}, false
+		changed = true
+	}
+	if limit := bc.staleAfter(); err == nil && limit > 0 && !latest.IsZero() {
+		stale := now.Sub(latest) > limit
+		switch {
+		case stale && !s.Stale:
+			msgs = append(msgs, alert{Brand: brand, Event: "stale", Since: latest,
+				Text: fmt.Sprintf("brand %s: no new episodes since %s", brand, latest.In(moscow).Format("02.01.2006"))})
+		case !stale && s.Stale:
+			msgs = append(msgs, alert{Brand: brand, Event: "fresh", Since: latest,
+				Text: fmt.Sprintf("brand %s: new episode of %s", brand, latest.In(moscow).Format("02.01.2006"))})
+		}
+		changed = changed || stale != s.Stale
+		s.Stale = stale
+	}
+	if !changed {
+		a.mu.Unlock()
+		return
+	}
+	if s == (alertState{}) {
+		delete(a.states, brand)
+	} else {
+		a.states[brand] = s
+	}
+	b, jerr := json.MarshalIndent(a.states, "", "  ")
+	a.mu.Unlock()
+
+	if jerr == nil {
+		jerr = writeFileAtomic(a.file, b, 0644)
+	}
+	if jerr != nil {
+		log.Printf("could not save the alerts state: %v", jerr)
+	}
+	for _, msg := range msgs {
+		if err := a.send(msg); err != nil {
+			log.Printf("could not send the alert: %v", err)
+		}
+	}
+}
+
+// latestEpisode is when the newest of the feed's episodes aired
+func latestEpisode(feed *brandFeed) (latest time.Time) {
+	if feed == nil {
+		return
+	}
+	for _, item := range feed.Items {
+		if item.Created.After(latest) {
+			latest = item.Created
+		}
+	}
+	return
+}
+
+func (a *alerter) send(msg alert) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c := http.Client{Timeout: 30 * time.Second}
+	res, err := c.Post(a.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", a.url, res.Status)
+	}
+	return nil
+}
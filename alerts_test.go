@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestAlerter(t *testing.T) {
+	var got []alert
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Error(err)
+		}
+		got = append(got, a)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, ".alerts.json")
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, moscow)
+	fail := errors.New("server returned 503 Service Unavailable")
+	a := newAlerter(ts.URL, 3, file)
+	a.record(brandConfig{Brand: "57083"}, fail, time.Time{}, start)
+	a.record(brandConfig{Brand: "57083"}, nil, time.Time{}, start.Add(time.Hour))
+	a.record(brandConfig{Brand: "57083"}, fail, time.Time{}, start.Add(2*time.Hour))
+	a.record(brandConfig{Brand: "57083"}, fail, time.Time{}, start.Add(3*time.Hour))
+	if len(got) != 0 {
+		t.Fatalf("alerted early: %+v", got)
+	}
+
+	// the streak survives the restart
+	a = newAlerter(ts.URL, 3, file)
+	a.record(brandConfig{Brand: "57083"}, fail, time.Time{}, start.Add(4*time.Hour))
+	a.record(brandConfig{Brand: "57083"}, fail, time.Time{}, start.Add(5*time.Hour))
+	a.record(brandConfig{Brand: "59798"}, nil, time.Time{}, start.Add(5*time.Hour))
+	if len(got) != 1 {
+		t.Fatalf("want 1 alert, got %+v", got)
+	}
+	want := alert{
+		Brand:    "57083",
+		Event:    "failing",
+		Failures: 3,
+		Since:    start.Add(2 * time.Hour),
+		Error:    fail.Error(),
+		Text:     "brand 57083: 3 refreshes in a row failed since 01.03.2026 11:00, last error: server returned 503 Service Unavailable",
+	}
+	if g := got[0]; g.Brand != want.Brand || g.Event != want.Event || g.Failures != want.Failures || !g.Since.Equal(want.Since) || g.Error != want.Error || g.Text != want.Text {
+		t.Errorf("want %+v, got %+v", want, g)
+	}
+
+	a.record(brandConfig{Brand: "57083"}, nil, time.Time{}, start.Add(6*time.Hour))
+	a.record(brandConfig{Brand: "57083"}, nil, time.Time{}, start.Add(7*time.Hour))
+	if len(got) != 2 || got[1].Event != "recovered" || got[1].Failures != 4 {
+		t.Fatalf("got %+v", got)
+	}
+	if got[1].Text != "brand 57083: recovered after 4 failed refreshes" {
+		t.Errorf("got %q", got[1].Text)
+	}
+	if b, err := ioutil.ReadFile(file); err != nil || string(b) != "{}" {
+		t.Errorf("got state %q, %v", b, err)
+	}
+}
+
+func TestAlerterStale(t *testing.T) {
+	var got []alert
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Error(err)
+		}
+		got = append(got, a)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d time.Duration) { staleAfter = d }(staleAfter)
+	staleAfter = 30 * 24 * time.Hour
+
+	a := newAlerter(ts.URL, 3, filepath.Join(dir, ".alerts.json"))
+	monthly := brandConfig{Brand: "57083", StaleAfter: "1200h"}
+	weekly := brandConfig{Brand: "59798"}
+	aired := time.Date(2026, 3, 1, 9, 0, 0, 0, moscow)
+	day := 24 * time.Hour
+
+	a.record(weekly, nil, aired, aired.Add(29*day))
+	a.record(monthly, nil, aired, aired.Add(31*day))
+	a.record(weekly, errors.New("timeout"), time.Time{}, aired.Add(31*day))
+	if len(got) != 0 {
+		t.Fatalf("alerted early: %+v", got)
+	}
+	a.record(weekly, nil, aired, aired.Add(31*day))
+	a.record(weekly, nil, aired, aired.Add(32*day))
+	if len(got) != 1 || got[0].Event != "stale" || got[0].Brand != "59798" || !got[0].Since.Equal(aired) {
+		t.Fatalf("got %+v", got)
+	}
+	if want := "brand 59798: no new episodes since 01.03.2026"; got[0].Text != want {
+		t.Errorf("want %q, got %q", want, got[0].Text)
+	}
+
+	a.record(weekly, nil, aired.Add(33*day), aired.Add(33*day))
+	if len(got) != 2 || got[1].Event != "fresh" {
+		t.Fatalf("got %+v", got)
+	}
+	a.record(monthly, nil, aired, aired.Add(51*day))
+	if len(got) != 3 || got[2].Event != "stale" || got[2].Brand != "57083" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestLatestEpisode(t *testing.T) {
+	early, late := time.Now().Add(-time.Hour), time.Now()
+	feed := &brandFeed{Items: []*feedItem{{Item: &feeds.Item{Created: early}}, {Item: &feeds.Item{Created: late}}, {Item: &feeds.Item{}}}}
+	if got := latestEpisode(feed); !got.Equal(late) {
+		t.Errorf("want %v, got %v", late, got)
+	}
+	if got := latestEpisode(nil); !got.IsZero() {
+		t.Errorf("got %v", got)
+	}
+}
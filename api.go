@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// apiBrand is a brand as listed by /api/brands
+type apiBrand struct {
+	Brand       string    `json:"brand"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	Image       string    `json:"image,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	Feed        string    `json:"feed"`
+	Generated   time.Time `json:"generated"`
+	Episodes    int       `json:"episodes"`
+}
+
+// apiEpisode is an episode as served by /api/episodes/{id}
+type apiEpisode struct {
+	Brand string `json:"brand"`
+	episodeMeta
+}
+
+// api serves the data scraped by the latest generations as JSON:
+//
+//	/api/brands                  the generated brands
+//	/api/brands/{brand}/episodes the episodes of the brand
+//	/api/episodes/{id}           the episode by its number on the site
+//
+// the brands protected with a token are only available with the
+// -basic-auth credentials
+func (d *daemon) api() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		metas := d.apiMetas(r)
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/")
+		switch {
+		case len(parts) == 1 && parts[0] == "brands":
+			brands := make([]apiBrand, 0, len(metas))
+			for _, bc := range d.brands {
+				if m, ok := metas[bc.Brand]; ok {
+					brands = append(brands, apiBrand{
+						Brand:       m.Brand,
+						Title:       m.Title,
+						Description: m.Description,
+						URL:         m.URL,
+						Image:       m.Image,
+						Author:      m.Author,
+						Feed:        feedURL(feedFilename(outputPath, m.Brand)),
+						Generated:   m.Generated,
+						Episodes:    len(m.Episodes),
+					})
+				}
+			}
+			writeJSON(w, brands)
+		case len(parts) == 3 && parts[0] == "brands" && parts[2] == "episodes":
+			m, ok := metas[parts[1]]
+			if !ok {
+				http.Error(w, "unknown brand "+parts[1], http.StatusNotFound)
+				return
+			}
+			writeJSON(w, m.Episodes)
+		case len(parts) == 2 && parts[0] == "episodes":
+			for _, bc := range d.brands {
+				m, ok := metas[bc.Brand]
+				if !ok {
+					continue
+				}
+				for _, ep := range m.Episodes {
+					if path.Base(strings.TrimSuffix(ep.URL, "/")) == parts[1] {
+						writeJSON(w, apiEpisode{Brand: m.Brand, episodeMeta: ep})
+						return
+					}
+				}
+			}
+			http.Error(w, "unknown episode "+parts[1], http.StatusNotFound)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// apiMetas returns the data of the generated brands the request may see
+func (d *daemon) apiMetas(r *http.Request) map[string]*feedMeta {
+	user, pass, hasAuth := r.BasicAuth()
+	authorized := basicAuth != "" && hasAuth && secretsEqual(user+":"+pass, basicAuth)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	metas := make(map[string]*feedMeta, len(d.results))
+	for _, bc := range d.brands {
+		res, ok := d.results[bc.Brand]
+		if !ok || res.meta == nil || bc.Token != "" && !authorized {
+			continue
+		}
+		metas[bc.Brand] = res.meta
+	}
+	return metas
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
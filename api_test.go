@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	d := newDaemon([]brandConfig{{Brand: "57083"}, {Brand: "59798", Token: "s3cret"}, {Brand: "60000"}})
+	d.results["57083"] = result{meta: &feedMeta{
+		Brand: "57083",
+		Title: "Аэростат",
+		Episodes: []episodeMeta{
+			{URL: "http://www.radiorus.ru/brand/57083/episode/2237849", Title: "Новые имена 27"},
+			{URL: "http://www.radiorus.ru/brand/57083/episode/2237781", Title: "The Cure"},
+		},
+	}}
+	d.results["59798"] = result{meta: &feedMeta{
+		Brand:    "59798",
+		Episodes: []episodeMeta{{URL: "http://www.radiorus.ru/brand/59798/episode/1", Title: "Закрытый"}},
+	}}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/api/brands")
+	var brands []apiBrand
+	if err := json.Unmarshal(w.Body.Bytes(), &brands); err != nil {
+		t.Fatal(err)
+	}
+	if len(brands) != 1 || brands[0].Brand != "57083" || brands[0].Episodes != 2 || brands[0].Feed != "radiorus-57083.rss" {
+		t.Errorf("got brands %+v", brands)
+	}
+
+	w = get("/api/brands/57083/episodes")
+	var eps []episodeMeta
+	if err := json.Unmarshal(w.Body.Bytes(), &eps); err != nil {
+		t.Fatal(err)
+	}
+	if len(eps) != 2 || eps[1].Title != "The Cure" {
+		t.Errorf("got episodes %+v", eps)
+	}
+
+	w = get("/api/episodes/2237781")
+	var ep apiEpisode
+	if err := json.Unmarshal(w.Body.Bytes(), &ep); err != nil {
+		t.Fatal(err)
+	}
+	if ep.Brand != "57083" || ep.Title != "The Cure" {
+		t.Errorf("got episode %+v", ep)
+	}
+
+	for _, path := range []string{
+		"/api/brands/60000/episodes", // not generated yet
+		"/api/brands/59798/episodes", // protected
+		"/api/episodes/1",
+		"/api/episodes/404",
+		"/api/unknown",
+	} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Errorf("%s: want 404, got %d", path, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	d.handler().ServeHTTP(w, httptest.NewRequest("POST", "/api/brands", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: want 405, got %d", w.Code)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/feeds"
+)
+
+var archiveYearRe = regexp.MustCompile(`^radiorus-(.+)-(\d{4})\.rss(?:\.gz)?$`)
+
+// archiveYear returns the year of the brand's archive file, false if
+// the file is not one (a season feed, or an archive of another brand)
+func archiveYear(file, brand string) (int, bool) {
+	m := archiveYearRe.FindStringSubmatch(filepath.Base(file))
+	if m == nil || m[1] != brand {
+		return 0, false
+	}
+	y, _ := strconv.Atoi(m[2])
+	return y, true
+}
+
+// archiveFilename returns the name of the brand's archive file for the year
+func archiveFilename(path, brand string, year int) string {
+	return feedFilename(path, fmt.Sprintf("%s-%d", brand, year))
+}
+
+// feedURL returns the URL the file is published at, relative
+// if the base URL is not known
+func feedURL(file string) string {
+	name := filepath.Base(file)
+	if baseURL == "" {
+		return name
+	}
+	return baseURL + name
+}
+
+// writeArchives adds the feed items to the per-year archive files, merging
+// them with the ones archived previously, and cross-links the archives
+// as per RFC 5005, applying the common extensions to the archives too;
+// returns the extensions for the main feed
+func writeArchives(feed *brandFeed, path, brand string, common ...extension) ([]extension, error) {
+	byYear := make(map[int][]*feedItem)
+	for _, item := range feed.Items {
+		if item.Created.IsZero() {
+			continue
+		}
+		y := item.Created.In(moscow).Year()
+		byYear[y] = append(byYear[y], item)
+	}
+
+	existing, _ := filepath.Glob(feedFilename(path, brand+"-*"))
+	for _, file := range existing {
+		y, ok := archiveYear(file, brand)
+		if !ok {
+			continue
+		}
+		if _, ok := byYear[y]; !ok {
+			byYear[y] = nil
+		}
+	}
+	if len(byYear) == 0 {
+		return nil, nil
+	}
+
+	var years []int
+	for y := range byYear {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+
+	current := feedURL(feedFilename(path, brand))
+	for i, y := range years {
+		file := archiveFilename(path, brand, y)
+		archive := &brandFeed{
+			Feed: feeds.Feed{
+				Title:       fmt.Sprintf("%s (%d)", feed.Title, y),
+				Link:        feed.Link,
+				Description: feed.Description,
+				Image:       feed.Image,
+				Created:     feed.Created,
+			},
+			Items: mergeItems(byYear[y], file),
+		}
+
+		exts := append([]extension{asArchive, withAtomLink("current", current)}, common...)
+		if i > 0 {
+			exts = append(exts, withAtomLink("prev-archive", feedURL(archiveFilename(path, brand, years[i-1]))))
+		}
+		if i < len(years)-1 {
+			exts = append(exts, withAtomLink("next-archive", feedURL(archiveFilename(path, brand, years[i+1]))))
+		}
+		if err := writeOutput(file, createFeed(archive, exts...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return []extension{withAtomLink("prev-archive", feedURL(archiveFilename(path, brand, years[len(years)-1])))}, nil
+}
+
+// mergeItems adds the items previously written to the file that are
+// not among the new ones, newest first
+func mergeItems(items []*feedItem, file string) []*feedItem {
+	merged := append([]*feedItem{}, items...)
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return merged
+	}
+	if old, err := parseFeed(b); err == nil {
+		durations := itemDurations(b)
+		ids := make(map[string]bool, len(items))
+		for _, item := range items {
+			ids[item.Id] = true
+		}
+		for _, ri := range old.Items {
+			if ids[ri.Guid] {
+				continue
+			}
+			item := itemFromRss(ri)
+			if d := durations[ri.Guid]; d != 0 {
+				item.duration = d
+			}
+			merged = append(merged, item)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Created.After(merged[j].Created)
+	})
+	return merged
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestWriteArchives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir += "/"
+
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
+	}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "episodes"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// a season named by the year is not an archive
+	writeFile([]byte("<rss></rss>"), seasonFilename(dir, "57083", 2018))
+
+	exts, err := writeArchives(feed, dir, "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(archiveFilename(dir, "57083", 2018)); err == nil {
+		t.Error("season taken for an archive")
+	}
+	current := string(createFeed(&brandFeed{Feed: feeds.Feed{Link: feed.Link}}, exts...))
+	assertStringContains(t, current, `<atom:link rel="prev-archive" href="radiorus-57083-2020.rss"`)
+
+	b := helperReadFile(t, archiveFilename(dir, "57083", 2020))
+	assertStringContains(t, b, "<fh:archive></fh:archive>")
+	assertStringContains(t, b, `<atom:link rel="current" href="radiorus-57083.rss"`)
+	assertStringContains(t, b, `<atom:link rel="prev-archive" href="radiorus-57083-2019.rss"`)
+	helperAssertItems(t, archiveFilename(dir, "57083", 2020), 4)
+
+	b = helperReadFile(t, archiveFilename(dir, "57083", 2019))
+	assertStringContains(t, b, `<atom:link rel="next-archive" href="radiorus-57083-2020.rss"`)
+	helperAssertItems(t, archiveFilename(dir, "57083", 2019), 6)
+
+	// a later run only sees the latest items, the archive must keep the rest
+	feed.Items = feed.Items[:2]
+	if _, err := writeArchives(feed, dir, "57083"); err != nil {
+		t.Fatal(err)
+	}
+	helperAssertItems(t, archiveFilename(dir, "57083", 2020), 4)
+	helperAssertItems(t, archiveFilename(dir, "57083", 2019), 6)
+}
+
+func TestArchiveYear(t *testing.T) {
+	for file, want := range map[string]int{
+		"/srv/radiorus-57083-2019.rss":        2019,
+		"/srv/radiorus-57083-2019.rss.gz":     2019,
+		"/srv/radiorus-57083.rss":             0,
+		"/srv/radiorus-57083-season-2020.rss": 0,
+		"/srv/radiorus-157083-2019.rss":       0,
+		"/srv/radiorus-57083-2019.rss.part":   0,
+		"/srv/radiorus-57083-12019.rss":       0,
+	} {
+		if got, ok := archiveYear(file, "57083"); got != want || ok != (want != 0) {
+			t.Errorf("%s: want %d, got %d, %v", file, want, got, ok)
+		}
+	}
+}
+
+func helperReadFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func helperAssertItems(t *testing.T, file string, n int) {
+	t.Helper()
+	rss, err := readFeed(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rss.Items) != n {
+		t.Fatalf("%s: want %d items, got %d", file, n, len(rss.Items))
+	}
+}
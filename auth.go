@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	brandFileRe  = regexp.MustCompile(`^radiorus-(.+?)(?:-\d{4}|-season-\d+)?\.(?:rss|json|html)(?:\.gz)?$`)
+	statusFileRe = regexp.MustCompile(`^/status/([^/]+)\.json$`)
+)
+
+// fileBrand returns the brand the served file belongs to: its feed,
+// archives, seasons, metadata, subscribe page, status, or the files in
+// its subdirectory of the site and of the audio mirror, if those are
+// served; empty string for the files common to all brands
+func fileBrand(urlPath string) string {
+	urlPath = path.Clean("/" + urlPath)
+	if m := statusFileRe.FindStringSubmatch(urlPath); m != nil {
+		return m[1]
+	}
+	for _, dir := range []string{siteDir, mirrorDir} {
+		if prefix, ok := servedDir(dir); ok && strings.HasPrefix(urlPath+"/", prefix) {
+			return strings.SplitN(urlPath[len(prefix)-1:]+"/", "/", 3)[1]
+		}
+	}
+	if m := brandFileRe.FindStringSubmatch(path.Base(urlPath)); m != nil && path.Dir(urlPath) == "/" {
+		return m[1]
+	}
+	return ""
+}
+
+// servedDir returns the URL path prefix the directory is served under,
+// if it is inside the output path
+func servedDir(dir string) (string, bool) {
+	if dir == "" || strings.Contains(dir, "://") {
+		return "", false
+	}
+	rel, err := filepath.Rel(outputPath, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return "/" + filepath.ToSlash(rel) + "/", true
+}
+
+// withAuth protects the files: everything requires the -basic-auth
+// credentials if those are set, and the files of the brands that have
+// a token configured (see fileBrand) require either the credentials
+// or the token, given as the token parameter or as the basic auth
+// password
+func (d *daemon) withAuth(h http.Handler) http.Handler {
+	tokens := make(map[string]string)
+	for _, bc := range d.brands {
+		if bc.Token != "" {
+			tokens[bc.Brand] = bc.Token
+		}
+	}
+	if basicAuth == "" && len(tokens) == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, hasAuth := r.BasicAuth()
+		if basicAuth != "" && hasAuth && secretsEqual(user+":"+pass, basicAuth) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		protected := basicAuth != ""
+		if token, ok := tokens[fileBrand(r.URL.Path)]; ok {
+			if secretsEqual(r.URL.Query().Get("token"), token) || hasAuth && secretsEqual(pass, token) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			protected = true
+		}
+		if !protected {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="radiorus-rss", charset="UTF-8"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func secretsEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// validBasicAuth checks the -basic-auth option format
+func validBasicAuth(s string) bool {
+	i := strings.Index(s, ":")
+	return i > 0 && i < len(s)-1
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(p, a, s, m string) { outputPath, basicAuth, siteDir, mirrorDir = p, a, s, m }(outputPath, basicAuth, siteDir, mirrorDir)
+	outputPath = dir + "/"
+	siteDir = filepath.Join(dir, "site")
+	mirrorDir = filepath.Join(dir, "audio")
+
+	url := func(file string) string {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "/" + filepath.ToSlash(rel)
+	}
+	var (
+		feed     = url(feedFilename(outputPath, "57083"))
+		archive  = url(archiveFilename(outputPath, "57083", 2019))
+		season   = url(seasonFilename(outputPath, "57083", 2))
+		meta     = url(metaFilename(outputPath, "57083"))
+		page     = url(subscribeFilename(outputPath, "57083"))
+		site     = url(filepath.Join(siteDir, "57083")) + "/"
+		audio    = url(filepath.Join(mirrorDir, "57083", "1.mp3"))
+		open     = url(feedFilename(outputPath, "59798"))
+		openSite = url(filepath.Join(siteDir, "59798")) + "/"
+	)
+	for _, file := range []string{feed, feed + ".gz", archive, season, meta, page, site + "index.html", audio, open, openSite + "index.html", "/radiorus.xsl"} {
+		name := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte("<rss></rss>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := newDaemon([]brandConfig{{Brand: "57083", Token: "s3cret"}, {Brand: "59798"}})
+
+	testdata := []struct {
+		auth, path, user, pass string
+		want                   int
+	}{
+		{"", open, "", "", http.StatusOK},
+		{"", openSite, "", "", http.StatusOK},
+		{"", "/radiorus.xsl", "", "", http.StatusOK},
+		{"", feed, "", "", http.StatusUnauthorized},
+		{"", feed + "?token=wrong", "", "", http.StatusUnauthorized},
+		{"", feed + "?token=s3cret", "", "", http.StatusOK},
+		{"", feed + ".gz", "", "", http.StatusUnauthorized},
+		{"", archive + "?token=s3cret", "", "", http.StatusOK},
+		{"", archive, "", "", http.StatusUnauthorized},
+		{"", season, "", "", http.StatusUnauthorized},
+		{"", meta, "", "", http.StatusUnauthorized},
+		{"", page, "", "", http.StatusUnauthorized},
+		{"", site, "", "", http.StatusUnauthorized},
+		{"", site + "?token=s3cret", "", "", http.StatusOK},
+		{"", audio, "", "", http.StatusUnauthorized},
+		{"", "/status/57083.json", "", "", http.StatusUnauthorized},
+		{"", feed, "anyone", "s3cret", http.StatusOK},
+		{"admin:pw", open, "", "", http.StatusUnauthorized},
+		{"admin:pw", "/radiorus.xsl", "admin", "pw", http.StatusOK},
+		{"admin:pw", feed, "admin", "pw", http.StatusOK},
+		{"admin:pw", feed + "?token=s3cret", "", "", http.StatusOK},
+		{"admin:pw", open, "admin", "wrong", http.StatusUnauthorized},
+	}
+
+	for _, tc := range testdata {
+		basicAuth = tc.auth
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, tc.pass)
+		}
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%q %s as %s:%s: want %d, got %d", tc.auth, tc.path, tc.user, tc.pass, tc.want, w.Code)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%q %s: no WWW-Authenticate", tc.auth, tc.path)
+		}
+	}
+}
+
+func TestFileBrand(t *testing.T) {
+	defer func(p, s, m string) { outputPath, siteDir, mirrorDir = p, s, m }(outputPath, siteDir, mirrorDir)
+	outputPath = "/srv/feeds/"
+	siteDir = "/srv/feeds/site"
+	mirrorDir = "/srv/audio"
+
+	for p, want := range map[string]string{
+		"/radiorus-57083.rss":           "57083",
+		"/radiorus-57083.rss.gz":        "57083",
+		"/radiorus-57083-2019.rss":      "57083",
+		"/radiorus-57083-2019.rss.gz":   "57083",
+		"/radiorus-57083-season-12.rss": "57083",
+		"/radiorus-57083.json":          "57083",
+		"/radiorus-57083.html":          "57083",
+		"/status/57083.json":            "57083",
+		"/site/57083/index.html":        "57083",
+		"/site/57083/episode.html":      "57083",
+		"/site/../radiorus-57083.rss":   "57083",
+		"/57083.rss":                    "",
+		"/sub/radiorus-57083.rss":       "",
+		"/site/":                        "",
+		"/audio/57083/1.mp3":            "",
+		"/radiorus.xsl":                 "",
+		"/index.html":                   "",
+	} {
+		if got := fileBrand(p); got != want {
+			t.Errorf("%s: want %q, got %q", p, want, got)
+		}
+	}
+}
+
+func TestValidBasicAuth(t *testing.T) {
+	for s, want := range map[string]bool{
+		"user:pass": true,
+		"user:":     false,
+		":pass":     false,
+		"userpass":  false,
+		"u:p:x":     true,
+	} {
+		if got := validBasicAuth(s); got != want {
+			t.Errorf("%q: want %v, got %v", s, want, got)
+		}
+	}
+}
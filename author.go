@@ -0,0 +1,43 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gorilla/feeds"
+)
+
+const (
+	itunesNS = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	dcNS     = "http://purl.org/dc/elements/1.1/"
+)
+
+// presenters returns the programme presenters listed on the page as
+// the feed author, nil if there are none
+func presenters(doc *goquery.Document) *feeds.Author {
+	var names []string
+	doc.Find(".brand__content_text__persons_item_name p").Each(func(i int, s *goquery.Selection) {
+		if name := strings.Join(strings.Fields(s.Text()), " "); name != "" {
+			names = append(names, name)
+		}
+	})
+	if len(names) == 0 {
+		return nil
+	}
+	return &feeds.Author{Name: strings.Join(names, ", ")}
+}
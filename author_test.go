@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestPresenters(t *testing.T) {
+	doc, err := newDocument(cleanText(helperLoadBytes(t, "about")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := presenters(doc)
+	if a == nil || a.Name != "Борис Гребенщиков" {
+		t.Errorf("want Борис Гребенщиков, got %+v", a)
+	}
+
+	doc, err = newDocument(helperLoadBytes(t, "blues"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := presenters(doc); a != nil {
+		t.Errorf("want no presenters, got %+v", a)
+	}
+}
+
+func TestAuthorRendered(t *testing.T) {
+	feed := &brandFeed{
+		Feed: feeds.Feed{
+			Title:  "f",
+			Link:   &feeds.Link{Href: "l"},
+			Author: &feeds.Author{Name: "Борис Гребенщиков"},
+		},
+		Items: []*feedItem{{Item: &feeds.Item{Title: "t", Link: &feeds.Link{Href: "l"}}}},
+	}
+	got := string(createFeed(feed))
+	for _, want := range []string{
+		"<itunes:author>Борис Гребенщиков</itunes:author>",
+		"<dc:creator>Борис Гребенщиков</dc:creator>",
+		`xmlns:dc="` + dcNS + `"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+	if strings.Contains(got, "managingEditor") {
+		t.Errorf("managingEditor without an email: %s", got)
+	}
+}
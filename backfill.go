@@ -0,0 +1,328 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func backfillCmd(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fs.StringVar(&outputPath, "path", "./", "path to write the archives to")
+	fs.StringVar(&baseURL, "base-url", "", "URL the feeds are published under")
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	smotrim := fs.Bool("smotrim", false, "use smotrim.ru directly")
+	delay := fs.Duration("delay", 5*time.Second, "delay between the requests to the site")
+	pages := fs.Int("pages", 0, "maximum number of listing pages to walk in this run, 0 for all")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s backfill [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if !strings.HasSuffix(outputPath, "/") {
+		outputPath += "/"
+	}
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	if *delay > 0 {
+		fetchLimiter = newLimiter(float64(time.Second) / float64(*delay))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		noticef("%v received, stopping; run backfill again to resume", sig)
+		cancel()
+	}()
+
+	stored, err := backfill(ctx, *brand, brandURL(*brand, *smotrim), outputPath, *pages)
+	noticef("%d episodes archived in this run", stored)
+	if err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
+
+// backfillCheckpoint is the progress of the brand's backfill, kept
+// between the runs so that an interrupted backfill resumes from the page
+// it stopped at
+type backfillCheckpoint struct {
+	Listing string    `json:"listing"`
+	Page    int       `json:"page"`
+	Crawled int       `json:"crawled"`
+	Stored  int       `json:"stored"`
+	Updated time.Time `json:"updated"`
+}
+
+// backfillCheckpointFile returns the file the brand's backfill progress
+// is kept in
+func backfillCheckpointFile(path, brand string) string {
+	return filepath.Join(path, "."+brand+".backfill.json")
+}
+
+// readBackfillCheckpoint returns the checkpoint of the previous backfill
+// of the listing, or a fresh one starting from the first page
+func readBackfillCheckpoint(file, listing string) backfillCheckpoint {
+	cp := backfillCheckpoint{Listing: listing, Page: 1}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return cp
+	}
+	var old backfillCheckpoint
+	if err := json.Unmarshal(b, &old); err != nil || old.Listing != listing || old.Page < 1 {
+		return cp
+	}
+	return old
+}
+
+func (cp backfillCheckpoint) write(file string) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, b, 0644)
+}
+
+var listingPageRe = regexp.MustCompile(`[?&]page=(\d+)`)
+
+// lastListingPage returns the number of the last page of the listing
+// as found in its pagination links, 0 if there are none
+func lastListingPage(page []byte) (last int) {
+	for _, m := range listingPageRe.FindAllSubmatch(page, -1) {
+		if n, _ := strconv.Atoi(string(m[1])); n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+// backfillETA estimates the time left to walk the pages up to the last
+// one, given the time it took to walk the ones done in this run
+func backfillETA(elapsed time.Duration, done, page, last int) time.Duration {
+	if done == 0 || last < page {
+		return 0
+	}
+	return (elapsed / time.Duration(done) * time.Duration(last-page)).Round(time.Second)
+}
+
+// backfill walks all the listing pages of the brand, describing the
+// episodes not archived yet and adding them to the archives page by
+// page; the progress is checkpointed after every page, so that an
+// interrupted backfill resumes from the page it stopped at; it returns
+// the number of the episodes archived in this run
+func backfill(ctx context.Context, brand, listing, path string, maxPages int) (stored int, err error) {
+	page, u, err := fetchPage(ctx, listing)
+	if err != nil {
+		return 0, err
+	}
+	feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: u}}}
+	if _, err := parseProgrammePage(feed, page); err != nil {
+		return 0, fmt.Errorf("could not process %v: %w", u, err)
+	}
+
+	cpFile := backfillCheckpointFile(path, brand)
+	cp := readBackfillCheckpoint(cpFile, listing)
+	if cp.Page > 1 {
+		noticef("resuming backfill from page %d", cp.Page)
+	}
+	last := lastListingPage(page)
+	if maxPages != 0 && (last == 0 || cp.Page+maxPages-1 < last) {
+		last = cp.Page + maxPages - 1
+	}
+
+	known := archivedLinks(path, brand)
+	walked := make(map[string]bool)
+	start := time.Now()
+
+	items := feed.Items
+	first := cp.Page
+	for n := first; maxPages == 0 || n < first+maxPages; n++ {
+		if n > 1 {
+			if items, err = listingPage(ctx, feed, n); err != nil {
+				return stored, err
+			}
+		}
+
+		var unseen, fresh []*feedItem
+		for _, item := range items {
+			link := item.Link.Href
+			if walked[link] {
+				continue
+			}
+			walked[link] = true
+			unseen = append(unseen, item)
+			if !known[link] {
+				fresh = append(fresh, item)
+			}
+		}
+		if len(unseen) == 0 {
+			// past the last page the site either returns nothing or
+			// repeats the last page
+			noticef("backfill done: %d pages crawled, %d episodes stored", cp.Crawled, cp.Stored)
+			if err := os.Remove(cpFile); err != nil && !os.IsNotExist(err) {
+				warnf(ctx, "could not remove %s: %v", cpFile, err)
+			}
+			return stored, nil
+		}
+
+		var described []*feedItem
+		for _, item := range fresh {
+			if err := describeEpisode(ctx, item); err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				warnf(ctx, "could not fetch episode page %v: %v", item.Link.Href, err)
+				continue
+			}
+			described = append(described, item)
+		}
+		if len(described) != 0 {
+			batch := &brandFeed{
+				Feed: feeds.Feed{
+					Title:       feed.Title,
+					Link:        feed.Link,
+					Description: feed.Description,
+					Image:       feed.Image,
+				},
+				Items: described,
+			}
+			if file := guidsFile(path, brand); pinsGUIDs(file) {
+				assignGUIDs(ctx, described, file, feedFilename(path, brand))
+			}
+			normalizeFeed(batch)
+			if typography {
+				typographFeed(batch)
+			}
+			if _, err := writeArchives(batch, path, brand); err != nil {
+				return stored, err
+			}
+			stored += len(described)
+		}
+		if err := ctx.Err(); err != nil {
+			// the page is not done, the resumed backfill starts with it
+			return stored, err
+		}
+
+		cp.Page, cp.Crawled, cp.Stored, cp.Updated = n+1, cp.Crawled+1, cp.Stored+len(described), time.Now()
+		if err := cp.write(cpFile); err != nil {
+			warnf(ctx, "could not write %s: %v", cpFile, err)
+		}
+		progress := fmt.Sprintf("page %d: %d pages crawled, %d episodes stored", n, cp.Crawled, cp.Stored)
+		if eta := backfillETA(time.Since(start), n-first+1, n, last); eta > 0 {
+			progress += fmt.Sprintf(", %d pages left, ETA %v", last-n, eta)
+		}
+		noticef("%s", progress)
+	}
+	return stored, nil
+}
+
+// listingPage fetches the episodes listed on the page n of the brand
+func listingPage(ctx context.Context, feed *brandFeed, n int) ([]*feedItem, error) {
+	page, _, err := fetchPage(ctx, listingPageURL(feed.Link.Href, n))
+	if err != nil {
+		return nil, err
+	}
+	page = unwrapListing(page)
+
+	doc, err := newDocument(page)
+	if err != nil {
+		return nil, err
+	}
+	f := &brandFeed{Feed: feeds.Feed{Link: feed.Link}}
+	if _, err := populateEpisodes(f, doc, page); err != nil {
+		return nil, err
+	}
+	return f.Items, nil
+}
+
+// listingPageURL returns the URL of the page n of the brand's episodes:
+// smotrim.ru pages the listing, radiorus.ru loads more of it as JSON
+func listingPageURL(listing string, n int) string {
+	u, err := url.Parse(listing)
+	if err != nil {
+		return listing
+	}
+	if strings.HasSuffix(u.Hostname(), "smotrim.ru") {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(n))
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/json"
+	u.RawQuery = url.Values{"offset": {strconv.Itoa(n - 1)}}.Encode()
+	return u.String()
+}
+
+// unwrapListing returns the HTML of the listing page that radiorus.ru
+// wraps into a JSON object
+func unwrapListing(page []byte) []byte {
+	if !bytes.HasPrefix(bytes.TrimSpace(page), []byte("{")) {
+		return page
+	}
+	var parts map[string]interface{}
+	if err := json.Unmarshal(page, &parts); err != nil {
+		return page
+	}
+	var keys []string
+	for k := range parts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var html bytes.Buffer
+	for _, k := range keys {
+		if s, ok := parts[k].(string); ok {
+			html.WriteString(s)
+		}
+	}
+	return html.Bytes()
+}
+
+// archivedLinks returns the links of the episodes already in the brand's
+// archives or the feed
+func archivedLinks(path, brand string) map[string]bool {
+	links := make(map[string]bool)
+	for _, file := range append(brandArchives(path, brand), feedFilename(path, brand)) {
+		rss, err := readFeed(file)
+		if err != nil {
+			continue
+		}
+		for _, item := range rss.Items {
+			links[item.Link] = true
+		}
+	}
+	return links
+}
@@ -0,0 +1,180 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackfill(t *testing.T) {
+	entry := func(n int) string {
+		return fmt.Sprintf(`<div class="brand__list--wrap--item">
+<a href="/brand/57083/episode/%d" class="title brand-menu-link">Выпуск %d</a>
+<a href="/brand/57083/episode/%d" class="brand-time">%02d.01.2020 в 14:10</a>
+<div class="audio-count" data-type="audio" data-id="%d"></div>
+</div>`, n, n, n, n, n)
+	}
+	more := func(ns ...int) []byte {
+		var html string
+		for _, n := range ns {
+			html += entry(n)
+		}
+		b, _ := json.Marshal(map[string]string{"content": html})
+		return b
+	}
+
+	var episodes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/brand/57083/episodes":
+			fmt.Fprint(w, `<h2 class="brand-main-item__title">Аэростат</h2>`+entry(5)+entry(4))
+		case r.URL.Path == "/brand/57083/episodes/json":
+			switch r.URL.Query().Get("offset") {
+			case "1":
+				_, _ = w.Write(more(3, 2))
+			default:
+				// the last page is repeated past the end
+				_, _ = w.Write(more(2, 1))
+			}
+		case strings.HasPrefix(r.URL.Path, "/brand/57083/episode/"):
+			episodes++
+			_, _ = w.Write(helperLoadBytes(t, "blues"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "backfill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir += "/"
+
+	listing := server.URL + "/brand/57083/episodes"
+	stored, err := backfill(context.Background(), "57083", listing, dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != 4 || episodes != 4 {
+		t.Fatalf("want 4 episodes archived, got %d of %d fetched", stored, episodes)
+	}
+	cp := readBackfillCheckpoint(backfillCheckpointFile(dir, "57083"), listing)
+	if cp.Page != 3 || cp.Crawled != 2 || cp.Stored != 4 {
+		t.Fatalf("want checkpoint at page 3 after 2 pages and 4 episodes, got %+v", cp)
+	}
+
+	// the interrupted backfill resumes without fetching the episodes again
+	stored, err = backfill(context.Background(), "57083", listing, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != 1 || episodes != 5 {
+		t.Fatalf("want 1 episode archived on resume, got %d of %d fetched", stored, episodes-4)
+	}
+	if _, err := os.Stat(backfillCheckpointFile(dir, "57083")); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after the backfill is done: %v", err)
+	}
+
+	rss, err := readFeed(archiveFilename(dir, "57083", 2020))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rss.Items) != 5 {
+		t.Errorf("want 5 episodes in the archive, got %d", len(rss.Items))
+	}
+}
+
+func TestBackfillCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backfill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := backfillCheckpointFile(dir, "57083")
+
+	if cp := readBackfillCheckpoint(file, "a"); cp.Page != 1 {
+		t.Errorf("want a fresh checkpoint at page 1, got %+v", cp)
+	}
+	if err := (backfillCheckpoint{Listing: "a", Page: 7, Crawled: 6, Stored: 60}).write(file); err != nil {
+		t.Fatal(err)
+	}
+	if cp := readBackfillCheckpoint(file, "a"); cp.Page != 7 || cp.Crawled != 6 || cp.Stored != 60 {
+		t.Errorf("want the checkpoint at page 7, got %+v", cp)
+	}
+	if cp := readBackfillCheckpoint(file, "b"); cp.Page != 1 {
+		t.Errorf("want the checkpoint of another listing ignored, got %+v", cp)
+	}
+}
+
+func TestLastListingPage(t *testing.T) {
+	page := []byte(`<a href="/brand/57083?page=2">2</a><a href="/brand/57083?sort=date&page=12">12</a><a href="?page=3">3</a>`)
+	if got := lastListingPage(page); got != 12 {
+		t.Errorf("want 12, got %d", got)
+	}
+	if got := lastListingPage([]byte(`<a href="/json?offset=1">ещё</a>`)); got != 0 {
+		t.Errorf("want 0, got %d", got)
+	}
+}
+
+func TestBackfillETA(t *testing.T) {
+	tests := []struct {
+		elapsed          time.Duration
+		done, page, last int
+		want             time.Duration
+	}{
+		{time.Minute, 2, 11, 20, 270 * time.Second},
+		{time.Minute, 2, 11, 0, 0},
+		{time.Minute, 0, 1, 20, 0},
+		{time.Minute, 2, 20, 20, 0},
+	}
+	for _, tc := range tests {
+		if got := backfillETA(tc.elapsed, tc.done, tc.page, tc.last); got != tc.want {
+			t.Errorf("for %+v want %v, got %v", tc, tc.want, got)
+		}
+	}
+}
+
+func TestListingPageURL(t *testing.T) {
+	tests := map[string]string{
+		"https://www.radiorus.ru/brand/57083/episodes": "https://www.radiorus.ru/brand/57083/episodes/json?offset=2",
+		"https://smotrim.ru/brand/57083":               "https://smotrim.ru/brand/57083?page=3",
+	}
+	for listing, want := range tests {
+		if got := listingPageURL(listing, 3); got != want {
+			t.Errorf("for %s want %s, got %s", listing, want, got)
+		}
+	}
+}
+
+func TestUnwrapListing(t *testing.T) {
+	if got := string(unwrapListing([]byte(`{"b":"<p>2</p>","a":"<p>1</p>","n":3}`))); got != "<p>1</p><p>2</p>" {
+		t.Errorf("got %q", got)
+	}
+	if got := string(unwrapListing([]byte(`<p>1</p>`))); got != "<p>1</p>" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestParseBytes(t *testing.T) {
+	for s, want := range map[string]int64{
+		"1000": 1000,
+		"500k": 500 << 10,
+		"1.5M": 3 << 19,
+		"2g":   2 << 30,
+		"0":    0,
+	} {
+		got, err := parseBytes(s)
+		if err != nil || got != want {
+			t.Errorf("%s: want %d, got %d, %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"", "k", "fast", "-5k"} {
+		if _, err := parseBytes(s); err == nil {
+			t.Errorf("no error for %q", s)
+		}
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 40<<10)
+	br := bytes.NewReader(data)
+	if r := throttle(context.Background(), br, nil); r != io.Reader(br) {
+		t.Error("reader throttled with no limiter")
+	}
+
+	l := newBandwidthLimiter(200 << 10)
+	start := time.Now()
+	var total int
+	for i := 0; i < 2; i++ {
+		n, err := io.Copy(ioutil.Discard, throttle(context.Background(), bytes.NewReader(data), l))
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += int(n)
+	}
+	// 80k at 200k/s, the first chunk is free
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("%d bytes read in %v", total, elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := io.Copy(ioutil.Discard, throttle(ctx, bytes.NewReader(data), newBandwidthLimiter(1))); err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+type opml struct {
+	XMLName xml.Name  `xml:"opml"`
+	Version string    `xml:"version,attr"`
+	Head    opmlHead  `xml:"head"`
+	Body    []outline `xml:"body>outline"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type outline struct {
+	Type        string `xml:"type,attr"`
+	Text        string `xml:"text,attr"`
+	Title       string `xml:"title,attr"`
+	Description string `xml:"description,attr,omitempty"`
+	XMLURL      string `xml:"xmlUrl,attr"`
+	HTMLURL     string `xml:"htmlUrl,attr"`
+}
+
+func catalogueCmd(args []string) {
+	fs := flag.NewFlagSet("catalogue", flag.ExitOnError)
+	baseURL := fs.String("base-url", "", "URL the feeds are published under")
+	output := fs.String("o", "catalogue.opml", "file to write the OPML to")
+	pages := fs.Int("pages", 100, "maximum number of catalogue pages to crawl")
+	title := fs.String("title", "Радио России", "title of the OPML document")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s catalogue [options] CATALOGUE-URL\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	brands, err := crawlCatalogue(fs.Arg(0), *pages)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(brands) == 0 {
+		log.Fatalf("no programmes found on %s", fs.Arg(0))
+	}
+
+	o := catalogueOPML(brands, *baseURL)
+	o.Head.Title = *title
+	o.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+	b, err := xml.MarshalIndent(o, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	writeFile(append([]byte(xml.Header), b...), *output)
+}
+
+// crawlCatalogue walks the catalogue pages until a page brings no new
+// brands or maxPages is reached
+func crawlCatalogue(catalogueURL string, maxPages int) (brands []foundBrand, err error) {
+	u, err := url.Parse(catalogueURL)
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for p := 1; p <= maxPages; p++ {
+		if p > 1 {
+			q := u.Query()
+			q.Set("page", strconv.Itoa(p))
+			u.RawQuery = q.Encode()
+		}
+		page, _ := getPage(u.String())
+		found, err := parseBrands(page)
+		if err != nil {
+			return brands, err
+		}
+
+		var fresh bool
+		for _, b := range found {
+			if seen[b.Number] {
+				continue
+			}
+			seen[b.Number] = true
+			fresh = true
+			brands = append(brands, b)
+		}
+		if !fresh {
+			break
+		}
+	}
+	return
+}
+
+func catalogueOPML(brands []foundBrand, baseURL string) opml {
+	o := opml{Version: "2.0"}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+	for _, b := range brands {
+		feedURL := *base
+		feedURL.Path = path.Join(base.Path, feedFilename("", b.Number))
+		o.Body = append(o.Body, outline{
+			Type:        "rss",
+			Text:        b.Title,
+			Title:       b.Title,
+			Description: b.Description,
+			XMLURL:      feedURL.String(),
+			HTMLURL:     brandURL(b.Number, true),
+		})
+	}
+	return o
+}
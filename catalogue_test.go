@@ -0,0 +1,54 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrawlCatalogue(t *testing.T) {
+	pages := map[string]string{
+		"":  `<a href="/brand/57083">Аэростат</a>`,
+		"2": `<a href="/brand/57083">Аэростат</a><a href="/brand/59798">Мы очень любим оперу</a>`,
+		"3": `<a href="/brand/59798">Мы очень любим оперу</a>`,
+	}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, pages[r.URL.Query().Get("page")])
+	}))
+	defer server.Close()
+
+	brands, err := crawlCatalogue(server.URL+"/brands", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("want 3 pages crawled, got %d", requests)
+	}
+
+	b, err := xml.MarshalIndent(catalogueOPML(brands, "https://example.com/feeds/"), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", t.Name()+".golden")
+	assertGolden(t, b, golden)
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// httpClient is shared by all the fetches so that connections
+	// to the site are kept alive and reused
+	httpClient = newClient(60*time.Second, true)
+
+	fetchTimeout = 60 * time.Second
+	http2        = true
+
+	// ip4 and ip6 make the connections to the site use that address
+	// family only
+	ip4, ip6 bool
+
+	// bindAddr is the -bind option bindIP is found from
+	bindAddr string
+	// bindIP is the local address to connect to the site from
+	bindIP net.IP
+
+	// extraHeaders are sent with every request to the site on top of
+	// the default ones
+	extraHeaders = make(http.Header)
+
+	// transport is the connection tuning from the -config file
+	transport transportConfig
+
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// transportConfig tunes the connections to the site, for the proxies
+// in front of it that misbehave with some combinations
+type transportConfig struct {
+	// HTTP is "1.1" or "2" to use that version regardless of -http2
+	HTTP                string `json:"http,omitempty"`
+	TLSMinVersion       string `json:"tlsMinVersion,omitempty"`
+	MaxIdleConns        int    `json:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost int    `json:"maxIdleConnsPerHost,omitempty"`
+	IdleConnTimeout     string `json:"idleConnTimeout,omitempty"`
+}
+
+func (tc transportConfig) validate() error {
+	if tc.HTTP != "" && tc.HTTP != "1.1" && tc.HTTP != "2" {
+		return fmt.Errorf("unknown HTTP version %q", tc.HTTP)
+	}
+	if _, ok := tlsVersions[tc.TLSMinVersion]; tc.TLSMinVersion != "" && !ok {
+		return fmt.Errorf("unknown TLS version %q", tc.TLSMinVersion)
+	}
+	if tc.MaxIdleConns < 0 || tc.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("negative idle connection count")
+	}
+	if tc.IdleConnTimeout != "" {
+		if d, err := time.ParseDuration(tc.IdleConnTimeout); err != nil || d < 0 {
+			return fmt.Errorf("bad idle connection timeout %q", tc.IdleConnTimeout)
+		}
+	}
+	return nil
+}
+
+const userAgent = `Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.27 Safari/537.36`
+
+// newClient makes the HTTP client for fetching pages, with the
+// overall request timeout and HTTP/2 enabled or not, tuned as per
+// the transport configuration
+func newClient(timeout time.Duration, h2 bool) *http.Client {
+	switch transport.HTTP {
+	case "1.1":
+		h2 = false
+	case "2":
+		h2 = true
+	}
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dial,
+		ForceAttemptHTTP2:     h2,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if v, ok := tlsVersions[transport.TLSMinVersion]; ok {
+		t.TLSClientConfig = &tls.Config{MinVersion: v}
+	}
+	if transport.MaxIdleConns != 0 {
+		t.MaxIdleConns = transport.MaxIdleConns
+	}
+	if transport.MaxIdleConnsPerHost != 0 {
+		t.MaxIdleConnsPerHost = transport.MaxIdleConnsPerHost
+	}
+	if d, err := time.ParseDuration(transport.IdleConnTimeout); err == nil {
+		t.IdleConnTimeout = d
+	}
+	if !h2 {
+		// a non-nil empty map disables HTTP/2
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return &http.Client{Transport: t, Timeout: timeout}
+}
+
+// dial connects to the site, over IPv4 or IPv6 only if told to
+func dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d := newDialer()
+	if bindIP != nil && strings.HasPrefix(network, "tcp") {
+		d.LocalAddr = &net.TCPAddr{IP: bindIP}
+	}
+	return d.DialContext(ctx, ipNetwork(network), addr)
+}
+
+// parseBind finds the local address to connect from, given either as
+// an IP address or as the name of the network interface; the first
+// global unicast address of the interface is used, IPv6 if -ip6 is
+// set and IPv4 otherwise
+func parseBind(s string) (net.IP, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip, nil
+	}
+	iface, err := net.InterfaceByName(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither an IP address nor a network interface", s)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	return pickAddr(addrs, ip6)
+}
+
+// pickAddr picks the address to bind to out of the interface's ones
+func pickAddr(addrs []net.Addr, v6 bool) (net.IP, error) {
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || !ipnet.IP.IsGlobalUnicast() || (ipnet.IP.To4() == nil) != v6 {
+			continue
+		}
+		return ipnet.IP, nil
+	}
+	family := "IPv4"
+	if v6 {
+		family = "IPv6"
+	}
+	return nil, fmt.Errorf("no %s address to bind to", family)
+}
+
+// ipNetwork narrows the network down to the address family chosen
+// by -ip4 or -ip6
+func ipNetwork(network string) string {
+	switch {
+	case network != "tcp" && network != "udp":
+		return network
+	case ip4:
+		return network + "4"
+	case ip6:
+		return network + "6"
+	}
+	return network
+}
+
+// newDialer makes the dialer for the connections to the site
+func newDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  dnsResolver,
+	}
+}
+
+// newRequest makes a request to the site with the User-Agent and the
+// extra headers set
+func newRequest(ctx context.Context, method, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	for name, values := range extraHeaders {
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// headerFlag collects the -header options into the headers
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	var s []string
+	for name, values := range h {
+		for _, v := range values {
+			s = append(s, name+": "+v)
+		}
+	}
+	sort.Strings(s)
+	return strings.Join(s, ", ")
+}
+
+func (h headerFlag) Set(s string) error {
+	i := strings.Index(s, ":")
+	if i <= 0 {
+		return fmt.Errorf("header %q is not \"Name: value\"", s)
+	}
+	name := strings.TrimSpace(s[:i])
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("bad header name %q", name)
+	}
+	http.Header(h).Add(name, strings.TrimSpace(s[i+1:]))
+	return nil
+}
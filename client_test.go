@@ -0,0 +1,263 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientReusesConnections(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html></html>"))
+	}))
+	server.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, _, err := fetchPage(context.Background(), server.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("want 1 connection for 5 requests, got %d", n)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = newClient(20*time.Millisecond, true)
+	if _, _, err := fetchPage(context.Background(), server.URL); err == nil {
+		t.Error("no timeout")
+	}
+}
+
+func TestClientHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, h2 := range []bool{true, false} {
+		c := newClient(time.Second, h2)
+		roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+		c.Transport.(*http.Transport).TLSClientConfig = &tls.Config{RootCAs: roots}
+		res, err := c.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if got := res.ProtoMajor == 2; got != h2 {
+			t.Errorf("HTTP/2 %v: got %s", h2, res.Proto)
+		}
+	}
+}
+
+func TestTransportConfig(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	defer func(tc transportConfig) { transport = tc }(transport)
+	for _, tt := range []struct {
+		tc transportConfig
+		h2 bool
+	}{
+		{transportConfig{HTTP: "1.1"}, true},
+		{transportConfig{HTTP: "2", TLSMinVersion: "1.2"}, false},
+	} {
+		transport = tt.tc
+		c := newClient(time.Second, tt.h2)
+		tr := c.Transport.(*http.Transport)
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.RootCAs = roots
+		res, err := c.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if got, want := res.ProtoMajor, map[string]int{"1.1": 1, "2": 2}[tt.tc.HTTP]; got != want {
+			t.Errorf("HTTP %s: got %s", tt.tc.HTTP, res.Proto)
+		}
+	}
+
+	transport = transportConfig{TLSMinVersion: "1.3", MaxIdleConns: 5, MaxIdleConnsPerHost: 2, IdleConnTimeout: "5s"}
+	c := newClient(time.Second, true)
+	tr := c.Transport.(*http.Transport)
+	if tr.MaxIdleConns != 5 || tr.MaxIdleConnsPerHost != 2 || tr.IdleConnTimeout != 5*time.Second {
+		t.Errorf("transport not tuned: %d, %d, %v", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	tr.TLSClientConfig.RootCAs = roots
+	if _, err := c.Get(server.URL); err == nil {
+		t.Error("TLS 1.2 server accepted with TLS 1.3 minimum")
+	}
+}
+
+func TestExtraHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Language") + "|" + r.UserAgent()))
+	}))
+	defer server.Close()
+
+	defer func(h http.Header) { extraHeaders = h }(extraHeaders)
+	extraHeaders = make(http.Header)
+	for _, h := range []string{"accept-language: ru", "User-Agent:test"} {
+		if err := headerFlag(extraHeaders).Set(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, _, err := fetchPage(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ru|test"; string(page) != want {
+		t.Errorf("want %q, got %q", want, page)
+	}
+}
+
+func TestHeaderFlag(t *testing.T) {
+	h := make(http.Header)
+	for _, s := range []string{"NoColon", ": value", "Bad Name: value"} {
+		if err := headerFlag(h).Set(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+	if err := headerFlag(h).Set("X-Forwarded-For: 10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "X-Forwarded-For: 10.0.0.1", headerFlag(h).String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestIPNetwork(t *testing.T) {
+	defer func(v4, v6 bool) { ip4, ip6 = v4, v6 }(ip4, ip6)
+	for _, tt := range []struct {
+		v4, v6        bool
+		network, want string
+	}{
+		{false, false, "tcp", "tcp"},
+		{true, false, "tcp", "tcp4"},
+		{false, true, "tcp", "tcp6"},
+		{false, true, "udp", "udp6"},
+		{true, false, "tcp6", "tcp6"},
+		{true, false, "unix", "unix"},
+	} {
+		ip4, ip6 = tt.v4, tt.v6
+		if got := ipNetwork(tt.network); got != tt.want {
+			t.Errorf("%s (ip4 %v, ip6 %v): want %s, got %s", tt.network, tt.v4, tt.v6, tt.want, got)
+		}
+	}
+}
+
+func TestDialIP4(t *testing.T) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	defer func(v4, v6 bool) { ip4, ip6 = v4, v6 }(ip4, ip6)
+	ip4, ip6 = true, false
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	ip4, ip6 = false, true
+	if conn, err := dial(context.Background(), "tcp", net.JoinHostPort("127.0.0.1", port)); err == nil {
+		conn.Close()
+		t.Error("connected to IPv4 address with -ip6")
+	}
+}
+
+func TestBind(t *testing.T) {
+	var remote string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}))
+	defer server.Close()
+
+	defer func(ip net.IP) { bindIP = ip }(bindIP)
+	var err error
+	if bindIP, err = parseBind("127.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(time.Second, true)
+	res, err := c.Get(server.URL)
+	if err != nil {
+		t.Skipf("can not bind to 127.0.0.2: %v", err)
+	}
+	res.Body.Close()
+	if remote != "127.0.0.2" {
+		t.Errorf("want request from 127.0.0.2, got %s", remote)
+	}
+
+	if _, err := parseBind("no-such-interface0"); err == nil {
+		t.Error("no error for unknown interface")
+	}
+}
+
+func TestPickAddr(t *testing.T) {
+	addr := func(s string) net.Addr {
+		ip, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ipnet.IP = ip
+		return ipnet
+	}
+	addrs := []net.Addr{addr("127.0.0.1/8"), addr("fe80::1/64"), addr("192.0.2.10/24"), addr("2001:db8::10/64")}
+
+	if ip, err := pickAddr(addrs, false); err != nil || ip.String() != "192.0.2.10" {
+		t.Errorf("want 192.0.2.10, got %v, %v", ip, err)
+	}
+	if ip, err := pickAddr(addrs, true); err != nil || ip.String() != "2001:db8::10" {
+		t.Errorf("want 2001:db8::10, got %v, %v", ip, err)
+	}
+	if _, err := pickAddr(addrs[:2], false); err == nil {
+		t.Error("no error for loopback and link-local only")
+	}
+}
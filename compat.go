@@ -0,0 +1,248 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/gorilla/feeds"
+)
+
+const (
+	dirApple   = "Apple Podcasts"
+	dirSpotify = "Spotify"
+	dirIndex   = "Podcast Index"
+)
+
+var directories = []string{dirApple, dirSpotify, dirIndex}
+
+// requirement is something a podcast directory expects from a feed,
+// check returns the description of the problem or "" if it is met
+type requirement struct {
+	name        string
+	directories []string
+	check       func(rss *feeds.RssFeed, it *itunesFeed) string
+}
+
+var requirements = []requirement{
+	{"title", directories, func(rss *feeds.RssFeed, _ *itunesFeed) string {
+		if rss.Title == "" {
+			return "channel has no title"
+		}
+		return ""
+	}},
+	{"description", directories, func(rss *feeds.RssFeed, _ *itunesFeed) string {
+		if rss.Description == "" {
+			return "channel has no description"
+		}
+		return ""
+	}},
+	{"artwork", []string{dirApple, dirSpotify}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if it.Channel.Image.Href == "" {
+			return "channel has no itunes:image"
+		}
+		return ""
+	}},
+	{"language", []string{dirApple, dirSpotify}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if it.Channel.Language == "" {
+			return "channel has no language"
+		}
+		return ""
+	}},
+	{"category", []string{dirApple, dirSpotify}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if len(it.Channel.Categories) == 0 {
+			return "channel has no itunes:category"
+		}
+		for _, c := range it.Channel.Categories {
+			if !appleCategories[c.Text] {
+				return fmt.Sprintf("%q is not an Apple Podcasts category", c.Text)
+			}
+		}
+		return ""
+	}},
+	{"explicit", []string{dirApple}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if it.Channel.Explicit == "" {
+			return "channel has no itunes:explicit"
+		}
+		return ""
+	}},
+	{"author", []string{dirSpotify}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if it.Channel.Author == "" {
+			return "channel has no itunes:author"
+		}
+		return ""
+	}},
+	{"owner email", []string{dirSpotify}, func(_ *feeds.RssFeed, it *itunesFeed) string {
+		if it.Channel.OwnerEmail == "" {
+			return "channel has no itunes:owner email, ownership can not be verified"
+		}
+		return ""
+	}},
+	{"episode guids", directories, func(rss *feeds.RssFeed, _ *itunesFeed) string {
+		for i, item := range rss.Items {
+			if item.Guid == "" {
+				return fmt.Sprintf("item %d has no guid", i+1)
+			}
+		}
+		return ""
+	}},
+	{"episode dates", directories, func(rss *feeds.RssFeed, _ *itunesFeed) string {
+		for i, item := range rss.Items {
+			if !isRFC822(item.PubDate) {
+				return fmt.Sprintf("item %d has no valid pubDate", i+1)
+			}
+		}
+		return ""
+	}},
+	{"episode audio", directories, func(rss *feeds.RssFeed, _ *itunesFeed) string {
+		for i, item := range rss.Items {
+			if item.Enclosure == nil || item.Enclosure.Url == "" {
+				return fmt.Sprintf("item %d has no enclosure", i+1)
+			}
+		}
+		return ""
+	}},
+}
+
+type compatReport struct {
+	File        string            `json:"file"`
+	Directories []directoryCompat `json:"directories"`
+}
+
+type directoryCompat struct {
+	Name         string              `json:"name"`
+	Compatible   bool                `json:"compatible"`
+	Requirements []requirementStatus `json:"requirements"`
+}
+
+type requirementStatus struct {
+	Name    string `json:"name"`
+	Met     bool   `json:"met"`
+	Problem string `json:"problem,omitempty"`
+}
+
+func compatCmd(args []string) {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	path := fs.String("path", "./", "path to look for the RSS file in")
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	format := fs.String("format", "text", "report format (text or json)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s compat [options] [file ...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{feedFilename(*path, *brand)}
+	}
+
+	var reports []compatReport
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r, err := checkCompat(b)
+		if err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+		r.File = file
+		reports = append(reports, r)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatal(err)
+		}
+	case "text":
+		for _, r := range reports {
+			printCompat(os.Stdout, r)
+		}
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+}
+
+// checkCompat reports which of the major podcast directories requirements
+// the RSS document meets
+func checkCompat(b []byte) (r compatReport, err error) {
+	rss, err := parseFeed(b)
+	if err != nil {
+		return
+	}
+	var it itunesFeed
+	if err = xml.Unmarshal(b, &it); err != nil {
+		return
+	}
+
+	for _, dir := range directories {
+		dc := directoryCompat{Name: dir, Compatible: true}
+		for _, req := range requirements {
+			if !contains(req.directories, dir) {
+				continue
+			}
+			problem := req.check(rss, &it)
+			dc.Requirements = append(dc.Requirements, requirementStatus{
+				Name:    req.name,
+				Met:     problem == "",
+				Problem: problem,
+			})
+			if problem != "" {
+				dc.Compatible = false
+			}
+		}
+		r.Directories = append(r.Directories, dc)
+	}
+	return
+}
+
+func printCompat(w io.Writer, r compatReport) {
+	fmt.Fprintln(w, r.File)
+	for _, dc := range r.Directories {
+		status := "compatible"
+		if !dc.Compatible {
+			status = "NOT compatible"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", dc.Name, status)
+		for _, req := range dc.Requirements {
+			if req.Met {
+				fmt.Fprintf(w, "    [+] %s\n", req.Name)
+			} else {
+				fmt.Fprintf(w, "    [-] %s: %s\n", req.Name, req.Problem)
+			}
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
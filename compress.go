@@ -0,0 +1,178 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// compressedTypes are the content types worth compressing
+var compressedTypes = []string{"text/", "application/xml", "application/rss+xml", "application/json", "application/xslt+xml"}
+
+// withCompression compresses the responses for the clients that
+// accept gzip or deflate encoding
+func withCompression(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// withPrecompressed serves the -gzip copy of the file to the clients
+// that accept gzip instead of compressing the file on the fly, unless
+// the copy is older than the file
+func withPrecompressed(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if acceptedEncoding(r.Header.Get("Accept-Encoding")) != "gzip" || strings.HasSuffix(r.URL.Path, ".gz") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		name := filepath.Join(outputPath, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		fi, err := os.Stat(name)
+		if err != nil || !fi.Mode().IsRegular() {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gz, err := os.Open(name + ".gz")
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		defer gz.Close()
+		gzi, err := gz.Stat()
+		if err != nil || !gzi.Mode().IsRegular() || gzi.ModTime().Before(fi.ModTime()) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		// the content type is the one of the file, as the file server
+		// would tell it, not of the copy
+		ctype := mime.TypeByExtension(filepath.Ext(name))
+		if ctype == "" {
+			if f, err := os.Open(name); err == nil {
+				var head [512]byte
+				n, _ := io.ReadFull(f, head[:])
+				ctype = http.DetectContentType(head[:n])
+				f.Close()
+			}
+		}
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, gzi.ModTime().UnixNano(), gzi.Size()))
+		http.ServeContent(w, r, name, fi.ModTime(), gz)
+	})
+}
+
+// acceptedEncoding picks the encoding to compress with from
+// the Accept-Encoding header, gzip preferred
+func acceptedEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		refused := false
+		for _, param := range fields[1:] {
+			if p := strings.Replace(param, " ", "", -1); p == "q=0" || strings.HasPrefix(p, "q=0.") && strings.Trim(p[4:], "0") == "" {
+				refused = true
+			}
+		}
+		accepted[name] = !refused
+	}
+
+	for _, enc := range []string{"gzip", "deflate"} {
+		if accepted[enc] {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressWriter compresses the response body if the response
+// turns out to be worth compressing
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	w           io.WriteCloser
+	wroteHeader bool
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	if code == http.StatusOK && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		// the compressed body is not byte-for-byte the same
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		if cw.encoding == "gzip" {
+			cw.w = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.w = zlib.NewWriter(cw.ResponseWriter)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.w == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.w.Write(b)
+}
+
+func (cw *compressWriter) close() {
+	if cw.w != nil {
+		_ = cw.w.Close()
+	}
+}
+
+func compressible(contentType string) bool {
+	for _, t := range compressedTypes {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
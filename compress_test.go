@@ -0,0 +1,170 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	testdata := []struct{ header, want string }{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip;q=1.0, *;q=0.5", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip; q=0.000", ""},
+		{"br", ""},
+		{"GZIP", "gzip"},
+	}
+
+	for _, tc := range testdata {
+		if got := acceptedEncoding(tc.header); got != tc.want {
+			t.Errorf("%q: want %q, got %q", tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestCompressedFeed(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+	h := helperDaemon("57083").handler()
+	want := helperLoadBytes(t, "TestServedFeed.golden")
+
+	for _, encoding := range []string{"", "gzip", "deflate"} {
+		req := httptest.NewRequest("GET", feed, nil)
+		if encoding != "" {
+			req.Header.Set("Accept-Encoding", encoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%q: want Vary: Accept-Encoding, got %q", encoding, got)
+		}
+		if got := w.Header().Get("Content-Encoding"); got != encoding {
+			t.Errorf("want Content-Encoding %q, got %q", encoding, got)
+		}
+
+		var body io.Reader = w.Body
+		switch encoding {
+		case "gzip":
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = zr
+		case "deflate":
+			zr, err := zlib.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = zr
+		}
+		got, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatalf("%q: %v", encoding, err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%q: served feed differs", encoding)
+		}
+	}
+}
+
+func TestCompressedNotModified(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+	h := helperDaemon("57083").handler()
+
+	req := httptest.NewRequest("GET", feed, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+	if etag[:2] != "W/" {
+		t.Errorf("want weak ETag for compressed response, got %s", etag)
+	}
+
+	req = httptest.NewRequest("GET", feed, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("want 304, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Error("304 response has a body")
+	}
+}
+
+func TestPrecompressed(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+	h := helperDaemon("57083").handler()
+	plain, err := ioutil.ReadFile(outputPath + feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzipBytes(plain)
+	if err := ioutil.WriteFile(outputPath+feed+".gz", gz, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", feed, nil)
+		req.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || !bytes.Equal(w.Body.Bytes(), gz) {
+		t.Errorf("want the gzip copy served as is, got %q encoding", w.Header().Get("Content-Encoding"))
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.Contains(ctype, "xml") {
+		t.Errorf("want the type of the feed, got %s", ctype)
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("got Vary %q", w.Header().Get("Vary"))
+	}
+
+	w = get("")
+	if w.Header().Get("Content-Encoding") != "" || !bytes.Equal(w.Body.Bytes(), plain) {
+		t.Error("want the feed served uncompressed")
+	}
+
+	// a stale copy is not served
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(outputPath+feed+".gz", past, past); err != nil {
+		t.Fatal(err)
+	}
+	w = get("gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || bytes.Equal(w.Body.Bytes(), gz) {
+		t.Error("want the stale copy replaced by compression on the fly")
+	}
+}
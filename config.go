@@ -0,0 +1,169 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+// config is the contents of the -config file
+type config struct {
+	Brands    []brandConfig    `json:"brands"`
+	Transport *transportConfig `json:"transport,omitempty"`
+}
+
+// brandConfig is the configuration of a single brand
+type brandConfig struct {
+	Brand   string `json:"brand"`
+	Smotrim bool   `json:"smotrim,omitempty"`
+	Cron    string `json:"cron,omitempty"`
+	Token   string `json:"token,omitempty"`
+
+	// Funding is the podcast:funding of the feed, the -funding-url
+	// one is used if not set
+	Funding *funding `json:"funding,omitempty"`
+
+	// Title, Description, Image and Link replace the scraped ones
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	Link        string `json:"link,omitempty"`
+
+	// Language is the language of the feed, -language if not set
+	Language string `json:"language,omitempty"`
+
+	// Category is the Apple Podcasts category of the feed, as
+	// "Category" or "Category/Subcategory", -apple-category if not set;
+	// Explicit marks the feed as having explicit content
+	Category string `json:"category,omitempty"`
+	Explicit bool   `json:"explicit,omitempty"`
+
+	// StaleAfter is how long the brand may go without a new episode
+	// before the -alert-url alert, -stale-after if not set
+	StaleAfter string `json:"stale_after,omitempty"`
+}
+
+// staleAfter is how long the brand may go without a new episode, no
+// limit if zero
+func (bc brandConfig) staleAfter() time.Duration {
+	if d, err := time.ParseDuration(bc.StaleAfter); err == nil {
+		return d
+	}
+	return staleAfter
+}
+
+// funding is where to support the feed
+type funding struct {
+	URL     string `json:"url"`
+	Message string `json:"message,omitempty"`
+}
+
+func loadConfig(filename string) (cfg config, err error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(cfg.Brands) == 0 {
+		return cfg, fmt.Errorf("%s: no brands configured", filename)
+	}
+	if tc := cfg.Transport; tc != nil {
+		if err := tc.validate(); err != nil {
+			return cfg, fmt.Errorf("%s: transport: %w", filename, err)
+		}
+	}
+	for _, bc := range cfg.Brands {
+		if bc.Brand == "" {
+			return cfg, fmt.Errorf("%s: brand number missing", filename)
+		}
+		if bc.Funding != nil && bc.Funding.URL == "" {
+			return cfg, fmt.Errorf("%s: brand %s: funding URL missing", filename, bc.Brand)
+		}
+		for name, u := range map[string]string{"image": bc.Image, "link": bc.Link} {
+			if u == "" {
+				continue
+			}
+			if p, err := url.Parse(u); err != nil || (p.Scheme != "http" && p.Scheme != "https") {
+				return cfg, fmt.Errorf("%s: brand %s: %s %q is not an HTTP URL", filename, bc.Brand, name, u)
+			}
+		}
+		if bc.Category != "" {
+			if _, err := parseAppleCategory(bc.Category); err != nil {
+				return cfg, fmt.Errorf("%s: brand %s: %w", filename, bc.Brand, err)
+			}
+		}
+		if bc.Cron != "" {
+			if _, err := parseCron(bc.Cron); err != nil {
+				return cfg, fmt.Errorf("%s: brand %s: %w", filename, bc.Brand, err)
+			}
+		}
+		if bc.StaleAfter != "" {
+			if d, err := time.ParseDuration(bc.StaleAfter); err != nil || d < 0 {
+				return cfg, fmt.Errorf("%s: brand %s: bad stale_after %q", filename, bc.Brand, bc.StaleAfter)
+			}
+		}
+	}
+	return
+}
+
+// applyOverrides replaces the scraped programme data with the one
+// configured for the brand
+func applyOverrides(feed *brandFeed, bc brandConfig) {
+	if bc.Title != "" {
+		feed.Title = bc.Title
+		if feed.Image != nil {
+			feed.Image.Title = bc.Title
+		}
+	}
+	if bc.Description != "" {
+		feed.Description = bc.Description
+	}
+	if bc.Link != "" {
+		feed.Link = &feeds.Link{Href: bc.Link}
+		if feed.Image != nil {
+			feed.Image.Link = bc.Link
+		}
+	}
+	if bc.Image != "" {
+		if feed.Image == nil {
+			feed.Image = &feeds.Image{Title: feed.Title}
+			if feed.Link != nil {
+				feed.Image.Link = feed.Link.Href
+			}
+		}
+		feed.Image.Url = bc.Image
+	}
+}
+
+// language is the language of the feeds for the brands that do not
+// configure their own
+var language = "ru"
+
+// feedLanguage returns the language of the brand's feed
+func feedLanguage(bc brandConfig) string {
+	if bc.Language != "" {
+		return bc.Language
+	}
+	return language
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func helperConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "radiorus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return f.Name()
+}
+
+func TestLoadConfig(t *testing.T) {
+	file := helperConfigFile(t, `{"brands": [
+		{"brand": "57083", "smotrim": true, "cron": "15 */2 * * *"},
+		{"brand": "59798", "funding": {"url": "https://example.com/donate", "message": "Поддержать"}},
+		{"brand": "60000", "title": "Передача", "image": "https://example.com/cover.jpg"}
+	], "transport": {"http": "1.1", "tlsMinVersion": "1.2", "maxIdleConnsPerHost": 4, "idleConnTimeout": "30s"}}`)
+	defer os.Remove(file)
+
+	cfg, err := loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []brandConfig{
+		{Brand: "57083", Smotrim: true, Cron: "15 */2 * * *"},
+		{Brand: "59798", Funding: &funding{URL: "https://example.com/donate", Message: "Поддержать"}},
+		{Brand: "60000", Title: "Передача", Image: "https://example.com/cover.jpg"},
+	}
+	if !reflect.DeepEqual(cfg.Brands, want) {
+		t.Fatalf("want %v, got %v", want, cfg.Brands)
+	}
+	wantTransport := &transportConfig{HTTP: "1.1", TLSMinVersion: "1.2", MaxIdleConnsPerHost: 4, IdleConnTimeout: "30s"}
+	if !reflect.DeepEqual(cfg.Transport, wantTransport) {
+		t.Errorf("want %+v, got %+v", wantTransport, cfg.Transport)
+	}
+}
+
+func TestBadConfig(t *testing.T) {
+	for _, contents := range []string{
+		`{"brands": []}`,
+		`{"brands": [{"smotrim": true}]}`,
+		`{"brands": [{"brand": "57083", "cron": "every day"}]}`,
+		`{"brands": [{"brand": "57083", "funding": {"message": "Поддержать"}}]}`,
+		`{"brands": [{"brand": "57083", "image": "cover.jpg"}]}`,
+		`{"brands": [{"brand": "57083", "link": "ftp://example.com/"}]}`,
+		`{"brands": [{"brand": "57083", "stale_after": "month"}]}`,
+		`{"brands": [{"brand": "57083", "category": "Радио"}]}`,
+		`brands: 57083`,
+		`{"brands": [{"brand": "57083"}], "transport": {"http": "3"}}`,
+		`{"brands": [{"brand": "57083"}], "transport": {"tlsMinVersion": "1.4"}}`,
+		`{"brands": [{"brand": "57083"}], "transport": {"maxIdleConns": -1}}`,
+		`{"brands": [{"brand": "57083"}], "transport": {"idleConnTimeout": "forever"}}`,
+	} {
+		file := helperConfigFile(t, contents)
+		if _, err := loadConfig(file); err == nil {
+			t.Errorf("no error for %s", contents)
+		}
+		os.Remove(file)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Title:       "Аэростат",
+		Description: "Одно предложение.",
+		Link:        &feeds.Link{Href: "https://smotrim.ru/brand/57083"},
+	}}
+	applyOverrides(feed, brandConfig{Description: "Подробное описание."})
+	if feed.Title != "Аэростат" || feed.Description != "Подробное описание." || feed.Image != nil {
+		t.Errorf("got %+v", feed)
+	}
+
+	applyOverrides(feed, brandConfig{
+		Title: "«Аэростат»",
+		Image: "https://example.com/cover.jpg",
+		Link:  "https://example.com/aerostat",
+	})
+	want := &feeds.Image{Url: "https://example.com/cover.jpg", Title: "«Аэростат»", Link: "https://example.com/aerostat"}
+	if feed.Title != "«Аэростат»" || feed.Link.Href != "https://example.com/aerostat" || !reflect.DeepEqual(feed.Image, want) {
+		t.Errorf("got %+v, image %+v", feed, feed.Image)
+	}
+}
+
+func TestFeedLanguage(t *testing.T) {
+	defer func(l string) { language = l }(language)
+	language = "ru"
+	if got := feedLanguage(brandConfig{}); got != "ru" {
+		t.Errorf("want ru by default, got %q", got)
+	}
+	if got := feedLanguage(brandConfig{Language: "tt"}); got != "tt" {
+		t.Errorf("want brand language, got %q", got)
+	}
+
+	feed := &brandFeed{Feed: feeds.Feed{Title: "Аэростат", Link: &feeds.Link{Href: "https://smotrim.ru/brand/57083"}}}
+	x := string(createFeed(feed, withLanguage("ru")))
+	for _, want := range []string{`<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xml:lang="ru">`, `<language>ru</language>`} {
+		if !strings.Contains(x, want) {
+			t.Errorf("no %s in\n%s", want, x)
+		}
+	}
+}
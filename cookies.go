@@ -0,0 +1,165 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cookieFile is where the cookies set by the site are kept between
+// runs, no cookies are kept if empty
+var cookieFile string
+
+// storedCookie is a cookie as kept in the cookie file, along with the
+// URL it was set from
+type storedCookie struct {
+	URL     string    `json:"url"`
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Path    string    `json:"path,omitempty"`
+	Domain  string    `json:"domain,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+	Secure  bool      `json:"secure,omitempty"`
+}
+
+// fileJar is a cookie jar that saves the cookies to a file whenever
+// they change; the matching of cookies to requests is left to the
+// standard library jar
+type fileJar struct {
+	*cookiejar.Jar
+	filename string
+
+	mu      sync.Mutex
+	cookies map[string]storedCookie
+}
+
+// newFileJar makes a jar with the cookies from the file, if it exists
+func newFileJar(filename string) (*fileJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	j := &fileJar{Jar: jar, filename: filename, cookies: make(map[string]storedCookie)}
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored []storedCookie
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, c := range stored {
+		u, err := url.Parse(c.URL)
+		if err != nil || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			continue
+		}
+		j.Jar.SetCookies(u, []*http.Cookie{c.cookie()})
+		j.cookies[c.key(u)] = c
+	}
+	return j, nil
+}
+
+// SetCookies implements http.CookieJar
+func (j *fileJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+	if len(cookies) == 0 {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	origin := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}).String()
+	for _, c := range cookies {
+		s := storedCookie{
+			URL:     origin,
+			Name:    c.Name,
+			Value:   c.Value,
+			Path:    c.Path,
+			Domain:  c.Domain,
+			Expires: c.Expires,
+			Secure:  c.Secure,
+		}
+		switch {
+		case c.MaxAge > 0:
+			s.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case c.MaxAge < 0:
+			s.Expires = now.Add(-time.Second)
+		}
+		if !s.Expires.IsZero() && s.Expires.Before(now) {
+			delete(j.cookies, s.key(u))
+			continue
+		}
+		j.cookies[s.key(u)] = s
+	}
+	if err := j.save(); err != nil {
+		log.Printf("could not save cookies to %s: %v", j.filename, err)
+	}
+}
+
+// save writes the cookies to the file
+func (j *fileJar) save() error {
+	keys := make([]string, 0, len(j.cookies))
+	for k := range j.cookies {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	stored := make([]storedCookie, 0, len(keys))
+	for _, k := range keys {
+		stored = append(stored, j.cookies[k])
+	}
+	b, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(j.filename, b, 0600)
+}
+
+// key identifies the cookie the way the jar does, so that a cookie
+// set anew replaces the old one
+func (c storedCookie) key(u *url.URL) string {
+	domain := c.Domain
+	if domain == "" {
+		domain = u.Hostname()
+	}
+	return domain + ";" + c.Path + ";" + c.Name
+}
+
+// cookie makes the HTTP cookie to feed back to the jar
+func (c storedCookie) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name:    c.Name,
+		Value:   c.Value,
+		Path:    c.Path,
+		Domain:  c.Domain,
+		Expires: c.Expires,
+		Secure:  c.Secure,
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileJar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cookies.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/set" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "42", Path: "/", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "gone", Value: "x", Path: "/", MaxAge: -1})
+			return
+		}
+		c, err := r.Cookie("session")
+		if err != nil {
+			return
+		}
+		_, _ = w.Write([]byte(c.Value))
+	}))
+	defer server.Close()
+
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+
+	for i, want := range []string{"", "42"} {
+		httpClient = newClient(time.Second, true)
+		jar, err := newFileJar(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		httpClient.Jar = jar
+
+		page, _, err := fetchPage(context.Background(), server.URL+"/get")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(page) != want {
+			t.Errorf("run %d: want %q, got %q", i, want, page)
+		}
+		if _, _, err := fetchPage(context.Background(), server.URL+"/set"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jar, err := newFileJar(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jar.cookies) != 1 {
+		t.Errorf("want 1 cookie stored, got %v", jar.cookies)
+	}
+}
+
+func TestFileJarExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cookies.json")
+
+	stored := `[{"url":"http://example.com/","name":"old","value":"1","expires":"2000-01-01T00:00:00Z"},
+{"url":"http://example.com/","name":"new","value":"2"}]`
+	if err := ioutil.WriteFile(file, []byte(stored), 0600); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := newFileJar(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jar.cookies) != 1 {
+		t.Errorf("want 1 cookie loaded, got %v", jar.cookies)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFileJar(file); err == nil {
+		t.Error("no error for a broken file")
+	}
+}
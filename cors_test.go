@@ -0,0 +1,60 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	defer func(o string) { corsOrigins = o }(corsOrigins)
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+
+	testdata := []struct {
+		origins, origin, method, want string
+		code                          int
+	}{
+		{"", "https://example.com", "GET", "", http.StatusOK},
+		{"*", "https://example.com", "GET", "*", http.StatusOK},
+		{"https://example.com/, https://player.example.org", "https://player.example.org", "GET", "https://player.example.org", http.StatusOK},
+		{"https://example.com", "https://evil.example.net", "GET", "", http.StatusOK},
+		{"https://example.com", "https://example.com", "OPTIONS", "https://example.com", http.StatusNoContent},
+	}
+
+	for _, tc := range testdata {
+		corsOrigins = tc.origins
+		req := httptest.NewRequest(tc.method, feed, nil)
+		req.Header.Set("Origin", tc.origin)
+		if tc.method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		w := httptest.NewRecorder()
+		helperDaemon("57083").handler().ServeHTTP(w, req)
+
+		if w.Code != tc.code {
+			t.Errorf("%q from %s: want %d, got %d", tc.origins, tc.origin, tc.code, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.want {
+			t.Errorf("%q from %s: want %q, got %q", tc.origins, tc.origin, tc.want, got)
+		}
+		if tc.method == "OPTIONS" && w.Header().Get("Access-Control-Allow-Methods") == "" {
+			t.Errorf("%q from %s: no allowed methods in preflight response", tc.origins, tc.origin)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Sunday
+	from := time.Date(2021, time.March, 7, 14, 10, 30, 0, moscow)
+
+	var tests = []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, time.March, 7, 14, 11, 0, 0, moscow)},
+		{"15 */2 * * *", time.Date(2021, time.March, 7, 14, 15, 0, 0, moscow)},
+		{"5 */2 * * *", time.Date(2021, time.March, 7, 16, 5, 0, 0, moscow)},
+		{"0 20 * * 5", time.Date(2021, time.March, 12, 20, 0, 0, 0, moscow)},
+		{"30 14 * * 0", time.Date(2021, time.March, 7, 14, 30, 0, 0, moscow)},
+		{"0 14 * * 7", time.Date(2021, time.March, 14, 14, 0, 0, 0, moscow)},
+		{"0 9,21 1 * *", time.Date(2021, time.April, 1, 9, 0, 0, 0, moscow)},
+		{"0 0 1 1 *", time.Date(2022, time.January, 1, 0, 0, 0, 0, moscow)},
+		{"0 12 13 * 5", time.Date(2021, time.March, 12, 12, 0, 0, 0, moscow)},
+		{"10-20/5 14 * * *", time.Date(2021, time.March, 7, 14, 15, 0, 0, moscow)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		s, err := parseCron(test.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", test.expr, err)
+		}
+		if got := s.next(from); !got.Equal(test.want) {
+			t.Errorf("%q: want %v, got %v", test.expr, test.want, got)
+		}
+	}
+}
+
+func TestCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("%q: no error", expr)
+		}
+	}
+}
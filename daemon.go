@@ -0,0 +1,263 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// daemon keeps the feeds regenerated, each brand on its own schedule
+type daemon struct {
+	brands []brandConfig
+
+	mu      sync.Mutex
+	results map[string]result
+	status  map[string]brandStatus
+	reports map[string]*brandReport
+
+	// triggers request immediate regeneration of the brand
+	triggers map[string]chan struct{}
+
+	// slots hold the brands being generated, up to -jobs at once
+	slots chan struct{}
+	// gen generates the brand's feed
+	gen func(brandConfig) (result, error)
+
+	// proxied generates the feeds of the brands not configured
+	proxied *feedProxy
+}
+
+func newDaemon(brands []brandConfig) *daemon {
+	n := jobs
+	if n < 1 {
+		n = 1
+	}
+	d := &daemon{
+		brands:   brands,
+		results:  make(map[string]result),
+		status:   make(map[string]brandStatus),
+		reports:  make(map[string]*brandReport),
+		triggers: make(map[string]chan struct{}),
+		slots:    make(chan struct{}, n),
+		gen:      generate,
+	}
+	for _, bc := range brands {
+		d.triggers[bc.Brand] = make(chan struct{}, 1)
+	}
+	if proxyBrands != "" {
+		d.proxied = newFeedProxy(proxyBrands, proxyTTL, proxyMax)
+	}
+	return d
+}
+
+func runDaemon(brands []brandConfig) {
+	fallback, err := parseCron(defaultCron)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	d := newDaemon(brands)
+
+	ctx, stop := context.WithCancel(context.Background())
+	var abort context.CancelFunc
+	fetchCtx, abort = context.WithCancel(context.Background())
+	defer abort()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go shutdown(signals, stop, abort)
+
+	if otlpEndpoint != "" {
+		go exportSpans(ctx, 5*time.Second)
+		defer func() {
+			if err := flushSpans(); err != nil {
+				log.Printf("could not export spans: %v", err)
+			}
+		}()
+	}
+
+	if interval := watchdogInterval(); interval > 0 {
+		go runWatchdog(fetchCtx, interval)
+	}
+
+	var wg, first sync.WaitGroup
+	if listenAddr != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.serve(ctx, listenAddr)
+		}()
+	}
+
+	for _, bc := range brands {
+		sched := fallback
+		if bc.Cron != "" {
+			if sched, err = parseCron(bc.Cron); err != nil {
+				log.Fatal(err)
+			}
+		}
+		wg.Add(1)
+		first.Add(1)
+		go func(bc brandConfig, sched *schedule) {
+			defer wg.Done()
+			d.run(ctx, bc, sched, first.Done)
+		}(bc, sched)
+	}
+	go func() {
+		first.Wait()
+		if err := sdNotify("READY=1"); err != nil {
+			log.Printf("failed to notify systemd: %v", err)
+		}
+	}()
+	wg.Wait()
+	noticef("all done, exiting")
+}
+
+// shutdown stops scheduling new work on the first signal, and aborts
+// the requests in flight if they do not finish in time or on the second
+// signal
+func shutdown(signals <-chan os.Signal, stop, abort context.CancelFunc) {
+	sig := <-signals
+	noticef("%v received, waiting for the feeds being generated (up to %v)", sig, shutdownTimeout)
+	_ = sdNotify("STOPPING=1")
+	stop()
+
+	select {
+	case <-time.After(shutdownTimeout):
+	case <-signals:
+	}
+	noticef("aborting requests in flight")
+	abort()
+}
+
+// run generates the brand's feed right away and then every time
+// the schedule fires or a refresh is triggered until the context
+// is cancelled, waiting for a free slot each time; generated is
+// called once the first generation is over
+func (d *daemon) run(ctx context.Context, bc brandConfig, sched *schedule, generated func()) {
+	var once sync.Once
+	defer once.Do(generated)
+	for {
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		d.refresh(bc)
+		<-d.slots
+		once.Do(generated)
+
+		next := sched.next(time.Now())
+		d.scheduled(bc.Brand, next)
+		if next.IsZero() {
+			log.Printf("brand %s: schedule never fires, no more updates", bc.Brand)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-d.triggers[bc.Brand]:
+			timer.Stop()
+		}
+	}
+}
+
+// trigger requests immediate regeneration of the brand, it returns
+// false if the brand is unknown
+func (d *daemon) trigger(brand string) bool {
+	ch, ok := d.triggers[brand]
+	if !ok {
+		return false
+	}
+	select {
+	case ch <- struct{}{}:
+	default: // already pending
+	}
+	return true
+}
+
+// scheduled records when the brand is regenerated next, never if the
+// time is zero
+func (d *daemon) scheduled(brand string, next time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := d.status[brand]
+	s.NextRun = nil
+	if !next.IsZero() {
+		s.NextRun = &next
+	}
+	d.status[brand] = s
+}
+
+func (d *daemon) refresh(bc brandConfig) {
+	atomic.AddInt32(&busy, 1)
+	r, err := d.gen(bc)
+	atomic.AddInt32(&busy, -1)
+	now := time.Now()
+	if alerts != nil {
+		alerts.record(bc, err, latestEpisode(r.feed), now)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r.report != nil && reportFile != "" {
+		d.reports[bc.Brand] = r.report
+		var reports []*brandReport
+		for _, b := range d.brands {
+			if rep, ok := d.reports[b.Brand]; ok {
+				reports = append(reports, rep)
+			}
+		}
+		if err := writeReport(reportFile, reports); err != nil {
+			log.Printf("could not write report: %v", err)
+		}
+	}
+	s := d.status[bc.Brand]
+	if err != nil {
+		log.Printf("brand %s: %v", bc.Brand, err)
+		s.LastError, s.LastErrorTime = err.Error(), &now
+		d.status[bc.Brand] = s
+		return
+	}
+	s.LastSuccess = &now
+	if r.report != nil {
+		s.Episodes, s.NewEpisodes = r.report.Episodes, r.report.NewEpisodes
+	}
+	d.status[bc.Brand] = s
+	d.results[bc.Brand] = r
+
+	if index {
+		var generated []result
+		for _, b := range d.brands {
+			if r, ok := d.results[b.Brand]; ok {
+				generated = append(generated, r)
+			}
+		}
+		if err := writeOutput(outputPath+"index.html", createIndex(generated)); err != nil {
+			log.Printf("could not write the index: %v", err)
+		}
+	}
+}
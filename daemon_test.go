@@ -0,0 +1,60 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDaemonJobs(t *testing.T) {
+	defer func(n int) { jobs = n }(jobs)
+	jobs = 2
+	d := helperDaemon("1", "2", "3", "4", "5")
+	var running, most int32
+	d.gen = func(bc brandConfig) (result, error) {
+		n := atomic.AddInt32(&running, 1)
+		for m := atomic.LoadInt32(&most); n > m && !atomic.CompareAndSwapInt32(&most, m, n); m = atomic.LoadInt32(&most) {
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return result{brand: bc.Brand}, nil
+	}
+
+	never, err := parseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for _, bc := range d.brands {
+		wg.Add(1)
+		go func(bc brandConfig) {
+			defer wg.Done()
+			d.run(context.Background(), bc, never, func() {})
+		}(bc)
+	}
+	wg.Wait()
+
+	if most != 2 {
+		t.Errorf("want 2 brands generated at once, got %d", most)
+	}
+	if len(d.results) != 5 {
+		t.Errorf("want all the brands generated, got %d", len(d.results))
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gorilla/feeds"
+)
+
+// feedChange is a difference between two versions of the feed
+type feedChange struct {
+	kind   byte // '+' added, '-' removed, '~' changed
+	guid   string
+	title  string
+	fields []string
+}
+
+func (c feedChange) String() string {
+	s := fmt.Sprintf("%c %s %s", c.kind, c.guid, c.title)
+	if len(c.fields) != 0 {
+		s += " (" + strings.Join(c.fields, ", ") + ")"
+	}
+	return s
+}
+
+func diffCmd(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	path := fs.String("path", "./", "path to look for the RSS file in")
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	smotrim := fs.Bool("smotrim", false, "use smotrim.ru directly")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff [options] [file]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	file := feedFilename(*path, *brand)
+	if fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	old, err := readFeed(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	feed := processURL(brandURL(*brand, *smotrim))
+	restoreDescriptions(feed, old)
+	fresh, err := parseFeed(createFeed(feed))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if changes := diffFeeds(old, fresh); len(changes) != 0 {
+		printChanges(os.Stdout, changes)
+		os.Exit(1)
+	}
+}
+
+// diffFeeds lists the items added, removed and changed in the new
+// version of the feed, matching them by guid
+func diffFeeds(old, fresh *feeds.RssFeed) (changes []feedChange) {
+	was := make(map[string]*feeds.RssItem, len(old.Items))
+	for _, item := range old.Items {
+		was[item.Guid] = item
+	}
+	is := make(map[string]bool, len(fresh.Items))
+
+	for _, item := range fresh.Items {
+		is[item.Guid] = true
+		o, ok := was[item.Guid]
+		if !ok {
+			changes = append(changes, feedChange{kind: '+', guid: item.Guid, title: item.Title})
+			continue
+		}
+		if fields := changedFields(o, item); len(fields) != 0 {
+			changes = append(changes, feedChange{kind: '~', guid: item.Guid, title: item.Title, fields: fields})
+		}
+	}
+	for _, item := range old.Items {
+		if !is[item.Guid] {
+			changes = append(changes, feedChange{kind: '-', guid: item.Guid, title: item.Title})
+		}
+	}
+	return
+}
+
+func changedFields(old, fresh *feeds.RssItem) (fields []string) {
+	if old.Title != fresh.Title {
+		fields = append(fields, "title")
+	}
+	if old.Link != fresh.Link {
+		fields = append(fields, "link")
+	}
+	if old.Description != fresh.Description {
+		fields = append(fields, "description")
+	}
+	if old.PubDate != fresh.PubDate {
+		fields = append(fields, "pubDate")
+	}
+	var oldURL, freshURL string
+	if old.Enclosure != nil {
+		oldURL = old.Enclosure.Url
+	}
+	if fresh.Enclosure != nil {
+		freshURL = fresh.Enclosure.Url
+	}
+	if oldURL != freshURL {
+		fields = append(fields, "enclosure")
+	}
+	return
+}
+
+func printChanges(w io.Writer, changes []feedChange) {
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestDiffFeeds(t *testing.T) {
+	old, err := readFeed(filepath.Join("testdata", "TestServedFeed.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := readFeed(filepath.Join("testdata", "TestServedFeed.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := diffFeeds(old, fresh); len(changes) != 0 {
+		t.Fatalf("changes in the same feed: %v", changes)
+	}
+
+	fresh.Items[1].Title = "The Cure (исправлено)"
+	fresh.Items[1].Description = "Новое описание"
+	fresh.Items[2].Enclosure = nil
+	fresh.Items = append([]*feeds.RssItem{{Guid: "new", Title: "Новый выпуск"}}, fresh.Items[:len(fresh.Items)-1]...)
+
+	var buf bytes.Buffer
+	printChanges(&buf, diffFeeds(old, fresh))
+	assertGolden(t, buf.Bytes(), filepath.Join("testdata", t.Name()+".golden"))
+}
@@ -0,0 +1,175 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// resolverAddr is the -resolver option dnsResolver is made from
+	resolverAddr string
+
+	// dnsResolver resolves the site's names if set, the system
+	// resolver is used otherwise
+	dnsResolver *net.Resolver
+)
+
+// parseResolver makes the resolver querying the server: host[:port]
+// for plain DNS, tls://host[:port] for DNS over TLS, or an https:// URL
+// for DNS over HTTPS
+func parseResolver(s string) (*net.Resolver, error) {
+	var dial func(ctx context.Context) (net.Conn, error)
+	switch {
+	case strings.HasPrefix(s, "https://"):
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("bad DNS over HTTPS URL %q", s)
+		}
+		dial = func(ctx context.Context) (net.Conn, error) {
+			return &dohConn{ctx: ctx, url: s}, nil
+		}
+	case strings.HasPrefix(s, "tls://"):
+		addr := withPort(strings.TrimPrefix(s, "tls://"), "853")
+		host, _, _ := net.SplitHostPort(addr)
+		dial = func(ctx context.Context) (net.Conn, error) {
+			d := net.Dialer{Timeout: 10 * time.Second}
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				_ = conn.SetDeadline(deadline)
+			}
+			tc := tls.Client(conn, &tls.Config{ServerName: host, RootCAs: dotRoots})
+			if err := tc.Handshake(); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return tc, nil
+		}
+	case strings.Contains(s, "://"):
+		return nil, fmt.Errorf("unknown resolver %q", s)
+	default:
+		addr := withPort(s, "53")
+		dial = func(ctx context.Context) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", addr)
+		}
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+	}, nil
+}
+
+// dotRoots are the CAs to verify the DNS over TLS servers with, the
+// system ones if nil
+var dotRoots *x509.CertPool
+
+// withPort adds the port to the address unless it has one
+func withPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+	return net.JoinHostPort(strings.Trim(addr, "[]"), port)
+}
+
+// dohClient makes the DNS over HTTPS requests, the name of the server
+// is resolved by the system resolver
+var dohClient = &http.Client{Timeout: 10 * time.Second}
+
+// dohConn is the connection the Go resolver talks DNS over TCP to,
+// each query written is sent to the DNS over HTTPS server and the
+// answer is there to read
+type dohConn struct {
+	ctx context.Context
+	url string
+
+	mu      sync.Mutex
+	out, in bytes.Buffer
+}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.out.Write(b)
+	for c.out.Len() >= 2 {
+		msg := c.out.Bytes()
+		n := int(msg[0])<<8 | int(msg[1])
+		if len(msg) < n+2 {
+			break
+		}
+		answer, err := c.query(msg[2 : n+2])
+		if err != nil {
+			return 0, err
+		}
+		c.out.Next(n + 2)
+		c.in.Write([]byte{byte(len(answer) >> 8), byte(len(answer))})
+		c.in.Write(answer)
+	}
+	return len(b), nil
+}
+
+func (c *dohConn) query(msg []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.ctx, "POST", c.url, bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	res, err := dohClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS over HTTPS: %s", res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.in.Read(b)
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr(c.url) }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr(c.url) }
+func (c *dohConn) SetDeadline(t time.Time) error      { return nil }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// dohAddr is the address of the DNS over HTTPS server
+type dohAddr string
+
+func (a dohAddr) Network() string { return "https" }
+func (a dohAddr) String() string  { return string(a) }
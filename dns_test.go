@@ -0,0 +1,151 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// helperDNSAnswer answers the DNS query with 127.0.0.2 for the A
+// questions and with nothing for the rest
+func helperDNSAnswer(query []byte) []byte {
+	end := 12
+	for end < len(query) && query[end] != 0 {
+		end += int(query[end]) + 1
+	}
+	end += 5 // the root label, type and class
+	if end > len(query) {
+		return nil
+	}
+	answer := append([]byte{}, query[:end]...)
+	answer[2], answer[3] = 0x81, 0x80                       // response, recursion desired and available
+	answer[6], answer[7], answer[8], answer[9] = 0, 0, 0, 0 // no answers yet
+	answer[10], answer[11] = 0, 0
+	if binary.BigEndian.Uint16(query[end-4:]) == 1 {
+		answer[7] = 1
+		answer = append(answer, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 127, 0, 0, 2)
+	}
+	return answer
+}
+
+func TestParseResolver(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	go func() {
+		b := make([]byte, 512)
+		for {
+			n, addr, err := udp.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			_, _ = udp.WriteTo(helperDNSAnswer(b[:n]), addr)
+		}
+	}()
+
+	doh := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		query, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(helperDNSAnswer(query))
+	}))
+	defer doh.Close()
+	defer func(c *http.Client) { dohClient = c }(dohClient)
+	dohClient = doh.Client()
+
+	dot, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: doh.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dot.Close()
+	go func() {
+		for {
+			conn, err := dot.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					var l [2]byte
+					if _, err := io.ReadFull(conn, l[:]); err != nil {
+						return
+					}
+					query := make([]byte, binary.BigEndian.Uint16(l[:]))
+					if _, err := io.ReadFull(conn, query); err != nil {
+						return
+					}
+					answer := helperDNSAnswer(query)
+					binary.BigEndian.PutUint16(l[:], uint16(len(answer)))
+					_, _ = conn.Write(append(l[:], answer...))
+				}
+			}(conn)
+		}
+	}()
+	defer func(p *x509.CertPool) { dotRoots = p }(dotRoots)
+	dotRoots = x509.NewCertPool()
+	dotRoots.AddCert(doh.Certificate())
+
+	for _, addr := range []string{udp.LocalAddr().String(), "tls://" + dot.Addr().String(), doh.URL + "/dns-query"} {
+		r, err := parseResolver(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := r.LookupHost(context.Background(), "www.radiorus.ru")
+		if err != nil {
+			t.Errorf("%s: %v", addr, err)
+			continue
+		}
+		if want := []string{"127.0.0.2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: want %v, got %v", addr, want, got)
+		}
+	}
+
+	for _, addr := range []string{"quic://1.1.1.1", "https://"} {
+		if _, err := parseResolver(addr); err == nil {
+			t.Errorf("no error for %s", addr)
+		}
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	for addr, want := range map[string]string{
+		"1.1.1.1":              "1.1.1.1:53",
+		"1.1.1.1:5353":         "1.1.1.1:5353",
+		"dns.google":           "dns.google:53",
+		"2606:4700:4700::1111": "[2606:4700:4700::1111]:53",
+		"[::1]:5353":           "[::1]:5353",
+	} {
+		if got := withPort(addr, "53"); got != want {
+			t.Errorf("%s: want %s, got %s", addr, want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// episodeInfo is everything that can be extracted from an episode page
+type episodeInfo struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+	AudioID     string    `json:"audioId"`
+	Image       string    `json:"image"`
+}
+
+func episodeCmd(args []string) {
+	fs := flag.NewFlagSet("episode", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s episode URL\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	page, u := getPage(fs.Arg(0))
+	ep, err := parseEpisodePage(page, siteOf(u))
+	if err != nil {
+		log.Fatalf("could not parse %v: %v", u, err)
+	}
+	ep.URL = u
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ep); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseEpisodePage extracts episode data from both radiorus and smotrim
+// episode pages, the site being the one the page was served from,
+// falling back to the page metadata when needed
+func parseEpisodePage(page []byte, site string) (ep episodeInfo, err error) {
+	doc, err := newDocument(page)
+	if err != nil {
+		return
+	}
+	head := doc.Find(".brand-episode__head")
+
+	ep.Title = strings.TrimSpace(head.Find(".title").Text())
+	if ep.Title == "" {
+		t, _ := doc.Find(`meta[property="og:title"]`).Attr("content")
+		ep.Title = strings.TrimSpace(strings.Split(t, " / ")[0])
+	}
+
+	ep.Description, _ = processEpisodeDesc(doc, site)
+
+	ep.Date = parseSmotrimDate(doc)
+	if ep.Date.IsZero() {
+		ep.Date = parseDay(strings.TrimSpace(head.Find(".date").Text()))
+	}
+
+	ep.AudioID, _ = doc.Find(`[data-type="audio"]`).First().Attr("data-id")
+
+	ep.Image, _ = doc.Find(".brand-episode__slider img").First().Attr("src")
+	if ep.Image == "" {
+		ep.Image, _ = doc.Find(`meta[property="og:image"]`).Attr("content")
+	}
+
+	return
+}
+
+// parseDay parses dates like "18 Октября 2020"
+func parseDay(s string) time.Time {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) != 3 {
+		return time.Time{}
+	}
+	for i, mnt := range months {
+		if fields[1] == mnt {
+			fields[1] = strconv.Itoa(i + 1)
+		}
+	}
+	t, err := time.ParseInLocation("2 1 2006", strings.Join(fields, " "), moscow)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
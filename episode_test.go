@@ -0,0 +1,60 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEpisodePage(t *testing.T) {
+	page := cleanText(helperLoadBytes(t, "blues"))
+	ep, err := parseEpisodePage(page, "radiorus.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Выпуск № 805. British Blues"; ep.Title != want {
+		t.Errorf("want title %q, got %q", want, ep.Title)
+	}
+	if want := time.Date(2020, time.October, 18, 0, 0, 0, 0, moscow); !ep.Date.Equal(want) {
+		t.Errorf("want date %v, got %v", want, ep.Date)
+	}
+	if want := "2522624"; ep.AudioID != want {
+		t.Errorf("want audio ID %q, got %q", want, ep.AudioID)
+	}
+	if want := "https://cdn-st3.rtr-vesti.ru/vh/pictures/xw/304/006/6.jpg"; ep.Image != want {
+		t.Errorf("want image %q, got %q", want, ep.Image)
+	}
+	if ep.Description == "" {
+		t.Error("no description")
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	var tests = map[string]time.Time{
+		"18 Октября 2020": time.Date(2020, time.October, 18, 0, 0, 0, 0, moscow),
+		"1 мая 2021":      time.Date(2021, time.May, 1, 0, 0, 0, 0, moscow),
+		"вчера":           {},
+		"32 мая 2021":     {},
+	}
+
+	for s, want := range tests {
+		if got := parseDay(s); !got.Equal(want) {
+			t.Errorf("for %q want %v, got %v", s, want, got)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// storedBrand is what is kept of the brand: its feed and the archives
+type storedBrand struct {
+	Brand       string
+	Title       string
+	Link        string
+	Description string
+	Episodes    []storedEpisode
+}
+
+// storedEpisode is an episode as written to the feed or the archives
+type storedEpisode struct {
+	GUID        string
+	Title       string
+	Published   time.Time
+	Link        string
+	AudioURL    string
+	AudioLength int64
+	Duration    int // seconds, 0 if unknown
+	Description string
+}
+
+func exportCmd(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	path := fs.String("path", "./", "path the feeds are in")
+	brand := fs.String("brand", "", "brand numbers, comma-separated (defaults to all the feeds found)")
+	format := fs.String("format", "sqlite", "output format (sqlite or csv)")
+	out := fs.String("o", "", "file to write (defaults to radiorus.sqlite for sqlite, standard output for csv)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s export [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	var brands []string
+	if *brand != "" {
+		for _, b := range strings.Split(*brand, ",") {
+			brands = append(brands, strings.TrimSpace(b))
+		}
+	} else {
+		brands = feedBrands(*path)
+	}
+
+	var stored []storedBrand
+	for _, b := range brands {
+		sb, err := readStoredBrand(*path, b)
+		if err != nil {
+			log.Fatal(err)
+		}
+		stored = append(stored, sb)
+	}
+
+	var write func(io.Writer, []storedBrand) error
+	switch *format {
+	case "sqlite":
+		write = exportSQLite
+		if *out == "" {
+			*out = "radiorus.sqlite"
+		}
+	case "csv":
+		write = exportCSV
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	if *out == "" {
+		if err := write(os.Stdout, stored); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(f, stored); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// itunesItems is the part of the feed the RSS parser does not keep
+type itunesItems struct {
+	Channel struct {
+		Items []struct {
+			Guid     string `xml:"guid"`
+			Duration string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// itemDurations returns the durations of the items of the RSS document
+// by their guids, in seconds
+func itemDurations(b []byte) map[string]int {
+	var it itunesItems
+	_ = xml.Unmarshal(b, &it)
+	durations := make(map[string]int)
+	for _, ii := range it.Channel.Items {
+		if d, err := strconv.Atoi(strings.TrimSpace(ii.Duration)); err == nil {
+			durations[ii.Guid] = d
+		}
+	}
+	return durations
+}
+
+// readStoredBrand reads the episodes of the brand from its feed and the
+// archives, newest first
+func readStoredBrand(path, brand string) (sb storedBrand, err error) {
+	sb.Brand = brand
+	seen := make(map[string]bool)
+	for i, file := range append([]string{feedFilename(path, brand)}, brandArchives(path, brand)...) {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			if i == 0 {
+				return sb, err
+			}
+			continue
+		}
+		feed, err := parseFeed(b)
+		if err != nil {
+			if i == 0 {
+				return sb, fmt.Errorf("%s: %w", file, err)
+			}
+			continue
+		}
+		if i == 0 {
+			sb.Title, sb.Link, sb.Description = feed.Title, feed.Link, feed.Description
+		}
+
+		durations := itemDurations(b)
+		for _, ri := range feed.Items {
+			if seen[ri.Guid] {
+				continue
+			}
+			seen[ri.Guid] = true
+			item := itemFromRss(ri)
+			ep := storedEpisode{
+				GUID:        ri.Guid,
+				Title:       item.Title,
+				Published:   item.Created,
+				Link:        item.Link.Href,
+				Duration:    durations[ri.Guid],
+				Description: item.Description,
+			}
+			if item.Enclosure != nil {
+				ep.AudioURL = item.Enclosure.Url
+				ep.AudioLength, _ = strconv.ParseInt(item.Enclosure.Length, 10, 64)
+			}
+			sb.Episodes = append(sb.Episodes, ep)
+		}
+	}
+	sort.SliceStable(sb.Episodes, func(i, j int) bool {
+		return sb.Episodes[i].Published.After(sb.Episodes[j].Published)
+	})
+	return sb, nil
+}
+
+// sqliteSchema is the schema of the exported database
+var sqliteSchema = []sqliteTable{
+	{name: "brands", schema: `CREATE TABLE brands (
+	brand TEXT NOT NULL,
+	title TEXT,
+	link TEXT,
+	description TEXT
+)`},
+	{name: "episodes", schema: `CREATE TABLE episodes (
+	id INTEGER PRIMARY KEY,
+	brand TEXT NOT NULL,
+	guid TEXT NOT NULL,
+	title TEXT,
+	published TEXT,
+	link TEXT,
+	audio_url TEXT,
+	audio_length INTEGER,
+	duration INTEGER,
+	description TEXT
+)`},
+}
+
+// exportSQLite writes the episodes of the brands as an SQLite database
+func exportSQLite(w io.Writer, brands []storedBrand) error {
+	text := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+	integer := func(n int64) interface{} {
+		if n == 0 {
+			return nil
+		}
+		return n
+	}
+
+	tables := append([]sqliteTable{}, sqliteSchema...)
+	for _, sb := range brands {
+		tables[0].rows = append(tables[0].rows, []interface{}{sb.Brand, text(sb.Title), text(sb.Link), text(sb.Description)})
+		for _, ep := range sb.Episodes {
+			var published interface{}
+			if !ep.Published.IsZero() {
+				published = ep.Published.In(moscow).Format(time.RFC3339)
+			}
+			tables[1].rows = append(tables[1].rows, []interface{}{
+				nil, sb.Brand, ep.GUID, text(ep.Title), published, text(ep.Link),
+				text(ep.AudioURL), integer(ep.AudioLength), integer(int64(ep.Duration)), text(ep.Description),
+			})
+		}
+	}
+	return writeSQLite(w, tables)
+}
+
+// exportCSV writes the episodes of the brands as CSV, one episode a row
+func exportCSV(w io.Writer, brands []storedBrand) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"title", "date", "brand", "url", "audio_url", "duration"})
+	for _, sb := range brands {
+		for _, ep := range sb.Episodes {
+			var date, duration string
+			if !ep.Published.IsZero() {
+				date = ep.Published.In(moscow).Format("2006-01-02 15:04:05")
+			}
+			if ep.Duration != 0 {
+				duration = fmt.Sprintf("%d:%02d:%02d", ep.Duration/3600, ep.Duration/60%60, ep.Duration%60)
+			}
+			_ = cw.Write([]string{ep.Title, date, sb.Brand, ep.Link, ep.AudioURL, duration})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadStoredBrand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(filepath.Separator)
+
+	feed := helperLoadBytes(t, "TestServedFeed.golden")
+	guid := []byte("<guid>**localhost**/brand/57083/episode/2237849</guid>")
+	feed = bytes.Replace(feed, guid, append(guid, "<itunes:duration>3540</itunes:duration>"...), 1)
+	writeFile(feed, feedFilename(path, "57083"))
+	// the archive repeats the episodes of the feed, they are kept once
+	writeFile(feed, archiveFilename(path, "57083", 2019))
+
+	sb, err := readStoredBrand(path, "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.Title != `"Аэростат"` || len(sb.Episodes) != 10 {
+		t.Fatalf("want 10 episodes of \"Аэростат\", got %d of %s", len(sb.Episodes), sb.Title)
+	}
+	ep := sb.Episodes[0]
+	if ep.GUID != "**localhost**/brand/57083/episode/2237849" || ep.Duration != 3540 || ep.AudioURL == "" || ep.Published.IsZero() {
+		t.Errorf("bad newest episode: %+v", ep)
+	}
+	for i := 1; i < len(sb.Episodes); i++ {
+		if sb.Episodes[i].Published.After(sb.Episodes[i-1].Published) {
+			t.Fatalf("episodes not sorted newest first at %d", i)
+		}
+	}
+
+	if _, err := readStoredBrand(path, "1"); err == nil {
+		t.Error("no error for a missing feed")
+	}
+}
+
+func TestExportSQLite(t *testing.T) {
+	brands := []storedBrand{{
+		Brand: "57083",
+		Title: "Аэростат",
+		Episodes: []storedEpisode{
+			{GUID: "2", Title: "Второй", Published: time.Date(2020, 2, 2, 14, 10, 0, 0, moscow), AudioURL: "https://example.org/2.mp3", AudioLength: 12345, Duration: 3540},
+			{GUID: "1", Title: "Первый"},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := exportSQLite(&buf, brands); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not found")
+	}
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "radiorus.sqlite")
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT b.title, e.id, e.title, e.published, e.audio_length, e.duration, e.link IS NULL " +
+		"FROM episodes e JOIN brands b USING (brand) ORDER BY e.id;"
+	out, err := exec.Command(sqlite, file, query).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	want := "Аэростат|1|Второй|2020-02-02T14:10:00+03:00|12345|3540|1\nАэростат|2|Первый||||1\n"
+	if string(out) != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, out)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	brands := []storedBrand{
+		{Brand: "57083", Episodes: []storedEpisode{
+			{Title: "Второй, \"последний\"", Published: time.Date(2020, 2, 2, 14, 10, 0, 0, moscow), Link: "https://www.radiorus.ru/brand/57083/episode/2", AudioURL: "https://example.org/2.mp3", Duration: 3725},
+			{Title: "Первый"},
+		}},
+		{Brand: "59798", Episodes: []storedEpisode{
+			{Title: "Блюз", Published: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Duration: 59},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := exportCSV(&buf, brands); err != nil {
+		t.Fatal(err)
+	}
+	want := `title,date,brand,url,audio_url,duration
+"Второй, ""последний""",2020-02-02 14:10:00,57083,https://www.radiorus.ru/brand/57083/episode/2,https://example.org/2.mp3,1:02:05
+Первый,,57083,,,
+Блюз,2020-01-01 03:00:00,59798,,,0:00:59
+`
+	if got := buf.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
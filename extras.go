@@ -0,0 +1,77 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gorilla/feeds"
+)
+
+// feedItem is a feed item along with the episode data feeds.Item has no
+// room for, the latter is added to the RSS item when the feed is rendered
+type feedItem struct {
+	*feeds.Item
+	audioID    string
+	categories []string
+	image      string
+	mirrored   *mirroredFile
+	parts      []episodePart
+	season     int
+	duration   int // seconds
+}
+
+// brandFeed is a feed of the items with their episode data, its Items
+// are the ones rendered rather than those of the embedded feeds.Feed
+type brandFeed struct {
+	feeds.Feed
+	Items []*feedItem
+}
+
+// Add adds the item to the feed
+func (f *brandFeed) Add(item *feedItem) {
+	f.Items = append(f.Items, item)
+}
+
+// plain returns the feeds.Feed with the items stripped of their
+// episode data
+func (f *brandFeed) plain() *feeds.Feed {
+	feed := f.Feed
+	feed.Items = make([]*feeds.Item, len(f.Items))
+	for i, item := range f.Items {
+		feed.Items[i] = item.Item
+	}
+	return &feed
+}
+
+// episodeTags extracts the topic tags from the episode page
+func episodeTags(doc *goquery.Document, site string) (tags []string) {
+	sel := ".brand-episode__tags a"
+	if site == "smotrim.ru" {
+		sel = ".video__tags a, .tags a"
+	}
+	seen := make(map[string]bool)
+	doc.Find(sel).Each(func(i int, s *goquery.Selection) {
+		tag := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s.Text()), "#"))
+		if tag == "" || seen[tag] {
+			return
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	})
+	return
+}
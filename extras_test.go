@@ -0,0 +1,57 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestEpisodeTags(t *testing.T) {
+	doc, err := newDocument(helperLoadBytes(t, "blues"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"музыка", "Борис Гребенщиков", "аэростат"}
+	if got := episodeTags(doc, "radiorus.ru"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	doc, err = newDocument([]byte(`<div class="video__tags"><a>#джаз</a> <a>#джаз</a> <a> </a></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"джаз"}
+	if got := episodeTags(doc, "smotrim.ru"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	item := &feedItem{Item: &feeds.Item{Title: "t", Link: &feeds.Link{Href: "l"}}}
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}}, Items: []*feedItem{item}}
+
+	item.categories = []string{"музыка", "джаз"}
+	got := string(createFeed(feed))
+	for _, want := range []string{"<category>музыка</category>", "<category>джаз</category>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+}
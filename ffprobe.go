@@ -0,0 +1,138 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ffprobe is the ffprobe binary to probe the mirrored audio with,
+// the header probing is used if it is empty or not found
+var ffprobe = "ffprobe"
+
+// ffprobeOutput is the part of the ffprobe -show_format JSON we use
+type ffprobeOutput struct {
+	Format struct {
+		Duration string            `json:"duration"`
+		BitRate  string            `json:"bit_rate"`
+		Size     string            `json:"size"`
+		Tags     map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+// mirroredInfo returns what is known about the mirrored audio file,
+// probing it unless its exact duration is known already
+func mirroredInfo(ctx context.Context, id, name string) audioInfo {
+	cacheFile := audioCacheFile(outputPath)
+	cached, _ := audioInfos.get(cacheFile, id)
+	if cached.Exact {
+		return cached
+	}
+	info, err := probeFile(ctx, name)
+	if err != nil {
+		warnf(ctx, "could not probe %s: %v", name, err)
+		return cached
+	}
+	audioInfos.put(cacheFile, map[string]audioInfo{id: info})
+	return info
+}
+
+// probeFile finds the duration, the bitrate and the tags of the audio
+// file with ffprobe, or the duration and the bitrate from its MP3
+// header if ffprobe is not available or fails
+func probeFile(ctx context.Context, name string) (audioInfo, error) {
+	if ffprobe != "" {
+		if bin, err := exec.LookPath(ffprobe); err == nil {
+			info, err := runFFprobe(ctx, bin, name)
+			if err == nil {
+				return info, nil
+			}
+			warnf(ctx, "ffprobe %s: %v", name, err)
+		}
+	}
+	return probeLocal(name)
+}
+
+func runFFprobe(ctx context.Context, bin, name string) (info audioInfo, err error) {
+	cmd := exec.CommandContext(ctx, bin, "-v", "error", "-print_format", "json", "-show_format", name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return
+	}
+	var p ffprobeOutput
+	if err = json.Unmarshal(out, &p); err != nil {
+		return
+	}
+	seconds, err := strconv.ParseFloat(p.Format.Duration, 64)
+	if err != nil {
+		return
+	}
+	info = audioInfo{Duration: int(math.Round(seconds)), Exact: true, Probed: time.Now()}
+	info.Length, _ = strconv.ParseInt(p.Format.Size, 10, 64)
+	if rate, err := strconv.Atoi(p.Format.BitRate); err == nil {
+		info.Bitrate = (rate + 500) / 1000
+	}
+	if len(p.Format.Tags) != 0 {
+		info.Tags = make(map[string]string, len(p.Format.Tags))
+		for k, v := range p.Format.Tags {
+			if v = strings.TrimSpace(v); v != "" {
+				info.Tags[strings.ToLower(k)] = v
+			}
+		}
+	}
+	return info, nil
+}
+
+// probeLocal reads the MP3 header of the file
+func probeLocal(name string) (audioInfo, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return audioInfo{}, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return audioInfo{}, err
+	}
+	readAt := func(off int64, n int) ([]byte, error) {
+		b := make([]byte, n)
+		n, err := f.ReadAt(b, off)
+		if err == io.EOF {
+			err = nil
+		}
+		return b[:n], err
+	}
+	head, err := readAt(0, probeHead)
+	if err != nil {
+		return audioInfo{}, err
+	}
+	return headerInfo(head, fi.Size(), readAt)
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestProbeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(f string) { ffprobe = f }(ffprobe)
+
+	name := filepath.Join(dir, "1.mp3")
+	if err := ioutil.WriteFile(name, append(helperMP3(probeHead, 0), make([]byte, 1600000)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// no ffprobe, the header is read
+	ffprobe = filepath.Join(dir, "no-such-ffprobe")
+	info, err := probeFile(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 100 || info.Bitrate != 128 || info.Exact || info.Tags != nil {
+		t.Errorf("wrong header info %+v", info)
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell script ffprobe on windows")
+	}
+	ffprobe = filepath.Join(dir, "ffprobe")
+	script := `#!/bin/sh
+echo '{"format": {"duration": "100.600000", "bit_rate": "127936", "size": "1665992", "tags": {"TITLE": "Новые имена", "artist": " Борис Гребенщиков ", "comment": ""}}}'
+`
+	if err := ioutil.WriteFile(ffprobe, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	info, err = probeFile(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 101 || info.Bitrate != 128 || info.Length != 1665992 || !info.Exact {
+		t.Errorf("wrong ffprobe info %+v", info)
+	}
+	if want := map[string]string{"title": "Новые имена", "artist": "Борис Гребенщиков"}; !reflect.DeepEqual(info.Tags, want) {
+		t.Errorf("want tags %v, got %v", want, info.Tags)
+	}
+
+	// ffprobe failing, the header is read
+	if err := ioutil.WriteFile(ffprobe, []byte("#!/bin/sh\necho 'Invalid data' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = probeFile(context.Background(), name); err != nil || info.Duration != 100 {
+		t.Errorf("want header duration, got %+v, %v", info, err)
+	}
+}
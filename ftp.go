@@ -0,0 +1,230 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpURL is where to upload the feeds over FTP(S)
+var ftpURL string
+
+// ftpPublisher uploads the files to an FTP server; ftps:// URLs use
+// explicit TLS (AUTH TLS) for both the control and the data connections
+type ftpPublisher struct {
+	host     string
+	user     string
+	password string
+	dir      string
+	secure   bool
+	tls      *tls.Config
+}
+
+func newFTPPublisher(raw string) (*ftpPublisher, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	p := &ftpPublisher{user: "anonymous", password: "anonymous", dir: u.Path}
+	switch u.Scheme {
+	case "ftp":
+	case "ftps":
+		p.secure = true
+		p.tls = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, fmt.Errorf("FTP URL %q should be ftp:// or ftps://", raw)
+	}
+	p.host = u.Host
+	if u.Port() == "" {
+		p.host = net.JoinHostPort(u.Hostname(), "21")
+	}
+	if u.User != nil {
+		p.user = u.User.Username()
+		if pw, ok := u.User.Password(); ok {
+			p.password = pw
+		}
+	}
+	return p, nil
+}
+
+func (p *ftpPublisher) publish(ctx context.Context, name string, data []byte) error {
+	d := net.Dialer{Timeout: 30 * time.Second}
+	conn, err := d.DialContext(ctx, "tcp", p.host)
+	if err != nil {
+		return err
+	}
+	c := &ftpConn{conn: conn, text: textproto.NewConn(conn)}
+	defer c.close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := c.expect(220); err != nil {
+		return err
+	}
+	if p.secure {
+		if err := c.cmd(234, "AUTH TLS"); err != nil {
+			return err
+		}
+		tc := tls.Client(conn, p.tls)
+		if err := tc.Handshake(); err != nil {
+			return err
+		}
+		c.conn, c.text = tc, textproto.NewConn(tc)
+	}
+	if err := c.login(p.user, p.password); err != nil {
+		return err
+	}
+	if p.secure {
+		if err := c.cmd(200, "PBSZ 0"); err != nil {
+			return err
+		}
+		if err := c.cmd(200, "PROT P"); err != nil {
+			return err
+		}
+	}
+	if err := c.cmd(200, "TYPE I"); err != nil {
+		return err
+	}
+
+	addr, err := c.passive()
+	if err != nil {
+		return err
+	}
+	dc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if p.secure {
+		// servers commonly require the data connection to resume the
+		// control connection's TLS session
+		dc = tls.Client(dc, p.tls)
+	}
+
+	if err := c.text.PrintfLine("STOR %s", path.Join(p.dir, name)); err != nil {
+		dc.Close()
+		return err
+	}
+	if code, msg, err := c.text.ReadResponse(1); err != nil {
+		dc.Close()
+		return fmt.Errorf("STOR: %d %s", code, msg)
+	}
+	if _, err := io.Copy(dc, bytes.NewReader(data)); err != nil {
+		dc.Close()
+		return err
+	}
+	if err := dc.Close(); err != nil {
+		return err
+	}
+	_, err = c.expect(226)
+	return err
+}
+
+type ftpConn struct {
+	conn net.Conn
+	text *textproto.Conn
+}
+
+func (c *ftpConn) expect(code int) (string, error) {
+	_, msg, err := c.text.ReadResponse(code)
+	return msg, err
+}
+
+func (c *ftpConn) cmd(code int, format string, args ...interface{}) error {
+	if err := c.text.PrintfLine(format, args...); err != nil {
+		return err
+	}
+	_, err := c.expect(code)
+	return err
+}
+
+func (c *ftpConn) login(user, password string) error {
+	if err := c.text.PrintfLine("USER %s", user); err != nil {
+		return err
+	}
+	code, msg, err := c.text.ReadResponse(0)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case 230:
+		return nil
+	case 331:
+		return c.cmd(230, "PASS %s", password)
+	default:
+		return fmt.Errorf("USER: %d %s", code, msg)
+	}
+}
+
+// passive asks for a passive data connection, trying EPSV first
+func (c *ftpConn) passive() (string, error) {
+	host, _, err := net.SplitHostPort(c.conn.RemoteAddr().String())
+	if err != nil {
+		return "", err
+	}
+	if err := c.text.PrintfLine("EPSV"); err != nil {
+		return "", err
+	}
+	if code, msg, err := c.text.ReadResponse(229); err == nil {
+		// 229 Entering Extended Passive Mode (|||port|)
+		start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+		if start < 0 || end < start+4 {
+			return "", fmt.Errorf("EPSV: %d %s", code, msg)
+		}
+		return net.JoinHostPort(host, msg[start+4:end]), nil
+	}
+
+	if err := c.text.PrintfLine("PASV"); err != nil {
+		return "", err
+	}
+	code, msg, err := c.text.ReadResponse(227)
+	if err != nil {
+		return "", err
+	}
+	// 227 Entering Passive Mode (h1,h2,h3,h4,p1,p2)
+	start, end := strings.Index(msg, "("), strings.Index(msg, ")")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("PASV: %d %s", code, msg)
+	}
+	f := strings.Split(msg[start+1:end], ",")
+	if len(f) != 6 {
+		return "", fmt.Errorf("PASV: %d %s", code, msg)
+	}
+	p1, err1 := strconv.Atoi(f[4])
+	p2, err2 := strconv.Atoi(f[5])
+	if err1 != nil || err2 != nil {
+		return "", fmt.Errorf("PASV: %d %s", code, msg)
+	}
+	// the address the server reports is often a private one, so only
+	// the port is used
+	return net.JoinHostPort(host, strconv.Itoa(p1<<8|p2)), nil
+}
+
+func (c *ftpConn) close() {
+	_ = c.text.PrintfLine("QUIT")
+	c.text.Close()
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeFTP serves a single FTP session, storing the uploaded file
+type fakeFTP struct {
+	ln       net.Listener
+	user     string
+	password string
+	epsv     bool
+	stored   chan [2]string
+}
+
+func newFakeFTP(t *testing.T, epsv bool) *fakeFTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeFTP{ln: ln, epsv: epsv, stored: make(chan [2]string, 1)}
+	go f.serve()
+	return f
+}
+
+func (f *fakeFTP) serve() {
+	conn, err := f.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 ready\r\n")
+	var data net.Listener
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		arg := ""
+		if i := strings.Index(cmd, " "); i > 0 {
+			cmd, arg = cmd[:i], cmd[i+1:]
+		}
+		switch cmd {
+		case "USER":
+			f.user = arg
+			fmt.Fprint(conn, "331 password please\r\n")
+		case "PASS":
+			f.password = arg
+			fmt.Fprint(conn, "230 logged in\r\n")
+		case "TYPE":
+			fmt.Fprint(conn, "200 ok\r\n")
+		case "EPSV", "PASV":
+			if cmd == "EPSV" && !f.epsv {
+				fmt.Fprint(conn, "500 unknown command\r\n")
+				continue
+			}
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			port := data.Addr().(*net.TCPAddr).Port
+			if cmd == "EPSV" {
+				fmt.Fprintf(conn, "229 Entering Extended Passive Mode (|||%d|)\r\n", port)
+			} else {
+				fmt.Fprintf(conn, "227 Entering Passive Mode (10,0,0,1,%d,%d)\r\n", port>>8, port&0xff)
+			}
+		case "STOR":
+			fmt.Fprint(conn, "150 go ahead\r\n")
+			dc, err := data.Accept()
+			if err != nil {
+				return
+			}
+			b, _ := ioutil.ReadAll(dc)
+			dc.Close()
+			data.Close()
+			f.stored <- [2]string{arg, string(b)}
+			fmt.Fprint(conn, "226 done\r\n")
+		case "QUIT":
+			fmt.Fprint(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "502 not implemented\r\n")
+		}
+	}
+}
+
+func TestFTPPublisher(t *testing.T) {
+	for _, epsv := range []bool{true, false} {
+		t.Run(fmt.Sprintf("epsv=%v", epsv), func(t *testing.T) {
+			srv := newFakeFTP(t, epsv)
+			defer srv.ln.Close()
+
+			p, err := newFTPPublisher("ftp://feeds:s3cret@" + srv.ln.Addr().String() + "/www/podcasts")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.publish(context.Background(), "radiorus-1.rss", []byte("<rss/>")); err != nil {
+				t.Fatal(err)
+			}
+			got := <-srv.stored
+			if got != [2]string{"/www/podcasts/radiorus-1.rss", "<rss/>"} {
+				t.Errorf("stored %q", got)
+			}
+			if srv.user != "feeds" || srv.password != "s3cret" {
+				t.Errorf("logged in as %q:%q", srv.user, srv.password)
+			}
+		})
+	}
+}
+
+func TestNewFTPPublisher(t *testing.T) {
+	p, err := newFTPPublisher("ftps://example.com/feeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.host != "example.com:21" || !p.secure || p.user != "anonymous" || p.dir != "/feeds" {
+		t.Errorf("got %+v", p)
+	}
+	if _, err := newFTPPublisher("sftp://example.com/"); err == nil {
+		t.Error("want error for sftp://")
+	}
+}
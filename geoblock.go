@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// errGeoBlocked means the CDN served the stub it gives outside of the
+// region instead of the audio
+var errGeoBlocked = errors.New("not available in the region: got the geo-blocking stub instead of the audio")
+
+const (
+	// geoStubMaxSize and geoStubMaxDuration are the size and duration
+	// (seconds) the audio is taken for the stub below, the episodes being
+	// way longer than the stub's few seconds
+	geoStubMaxSize     = 256 << 10
+	geoStubMaxDuration = 30
+)
+
+var (
+	// geoStubRe matches what the stub and the pages served instead of
+	// the audio say
+	geoStubRe = regexp.MustCompile(`(?i)not available in your (region|country)|geo-?block|недоступ\S* в вашем (регионе|стране)`)
+	// geoStubURLRe matches the links the stub is served from
+	geoStubURLRe = regexp.MustCompile(`(?i)/(geo|stub|blocked)[/._-]`)
+)
+
+// geoStubContent tells if what was served for the audio is the stub
+// judging by its link, type or the beginning of it: a page rather than
+// audio, or a text saying it is not available
+func geoStubContent(finalURL, contentType string, head []byte) bool {
+	if geoStubURLRe.MatchString(finalURL) || strings.HasPrefix(contentType, "text/html") {
+		return true
+	}
+	if geoStubRe.Match(head) {
+		return true
+	}
+	head = bytes.ToLower(bytes.TrimSpace(head))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+// geoStubFile tells if the downloaded file is the stub judging by the
+// beginning of it
+func geoStubFile(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	head, err := ioutil.ReadAll(io.LimitReader(f, 512))
+	if err != nil {
+		return false, err
+	}
+	return geoStubContent("", "", head), nil
+}
+
+// geoStubInfo tells if the probed audio is the stub judging by its
+// size and duration
+func geoStubInfo(info audioInfo) bool {
+	return info.Length > 0 && info.Length < geoStubMaxSize && info.Duration > 0 && info.Duration < geoStubMaxDuration
+}
+
+// noteGeoBlocked records the audio in the run report if the error is
+// the geo-blocking stub
+func noteGeoBlocked(ctx context.Context, u string, err error) {
+	if errors.Is(err, errGeoBlocked) {
+		reportFrom(ctx).addGeoBlocked(u)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestGeoStubContent(t *testing.T) {
+	tests := []struct {
+		url, contentType string
+		head             []byte
+		want             bool
+	}{
+		{"https://cdn.example.com/1.mp3", "audio/mpeg", helperMP3(0, 0), false},
+		{"https://cdn.example.com/geo/stub.mp3", "audio/mpeg", helperMP3(0, 0), true},
+		{"https://cdn.example.com/1.mp3", "text/html; charset=utf-8", []byte("whatever"), true},
+		{"", "", []byte("  <!DOCTYPE html><html>"), true},
+		{"", "", []byte("Content is not available in your region"), true},
+		{"", "", []byte("Аудио недоступно в вашем регионе"), true},
+		{"", "", []byte("audio 1"), false},
+	}
+	for _, tc := range tests {
+		if got := geoStubContent(tc.url, tc.contentType, tc.head); got != tc.want {
+			t.Errorf("%s %s %q: want %v, got %v", tc.url, tc.contentType, tc.head, tc.want, got)
+		}
+	}
+}
+
+func TestGeoStubInfo(t *testing.T) {
+	tests := []struct {
+		info audioInfo
+		want bool
+	}{
+		{audioInfo{Length: 160000, Duration: 10}, true},
+		{audioInfo{Length: 66994, Duration: 2612}, false},
+		{audioInfo{Length: 50000000, Duration: 10}, false},
+		{audioInfo{Length: 160000}, false},
+	}
+	for _, tc := range tests {
+		if got := geoStubInfo(tc.info); got != tc.want {
+			t.Errorf("%+v: want %v, got %v", tc.info, tc.want, got)
+		}
+	}
+}
+
+func helperGeoCDN() *httptest.Server {
+	stub := append(helperMP3(0, 0), make([]byte, 160000)...)
+	audio := append(helperMP3(10, 0), make([]byte, 1600000)...)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download":
+			switch r.URL.Query().Get("id") {
+			case "1":
+				http.Redirect(w, r, "/cdn/1.mp3", http.StatusFound)
+			case "2":
+				http.Redirect(w, r, "/geo/stub.mp3", http.StatusFound)
+			case "3":
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, "<html><body>Not available in your region</body></html>")
+			case "4":
+				http.ServeContent(w, r, "stub.mp3", time.Time{}, bytes.NewReader(stub))
+			}
+		case "/cdn/1.mp3":
+			http.ServeContent(w, r, "1.mp3", time.Time{}, bytes.NewReader(audio))
+		case "/geo/stub.mp3":
+			http.ServeContent(w, r, "stub.mp3", time.Time{}, bytes.NewReader(stub))
+		}
+	}))
+}
+
+func TestResolveGeoBlocked(t *testing.T) {
+	server := helperGeoCDN()
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var items []*feedItem
+	for _, id := range []string{"1", "2", "3"} {
+		items = append(items, &feedItem{Item: &feeds.Item{Enclosure: &feeds.Enclosure{Url: server.URL + "/download?id=" + id, Length: "1024"}}})
+	}
+	rep := newBrandReport("57083")
+	resolveEnclosures(withReport(context.Background(), rep), items, resolveCacheFile(dir, "57083"))
+
+	if got := items[0].Enclosure.Url; got != server.URL+"/cdn/1.mp3" {
+		t.Errorf("want the audio resolved, got %s", got)
+	}
+	for _, item := range items[1:] {
+		if item.Enclosure.Length != "1024" || item.Enclosure.Url[len(server.URL):len(server.URL)+9] != "/download" {
+			t.Errorf("stub put in the feed: %s (%s)", item.Enclosure.Url, item.Enclosure.Length)
+		}
+	}
+	if len(rep.GeoBlocked) != 2 || len(rep.Warnings) != 2 {
+		t.Errorf("want 2 geo-blocked audio files reported, got %v and warnings %v", rep.GeoBlocked, rep.Warnings)
+	}
+}
+
+func TestProbeGeoBlocked(t *testing.T) {
+	server := helperGeoCDN()
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(c *audioCache) { audioInfos = c }(audioInfos)
+	audioInfos = &audioCache{}
+
+	items := []*feedItem{
+		{Item: &feeds.Item{Enclosure: &feeds.Enclosure{Url: server.URL + "/download?id=1", Length: "1024"}}},
+		{Item: &feeds.Item{Enclosure: &feeds.Enclosure{Url: server.URL + "/download?id=4", Length: "1024"}}},
+	}
+	rep := newBrandReport("57083")
+	probeEnclosures(withReport(context.Background(), rep), items, audioCacheFile(dir))
+
+	if items[0].Enclosure.Length == "1024" {
+		t.Error("audio not probed")
+	}
+	if items[1].Enclosure.Length != "1024" || items[1].duration != 0 {
+		t.Errorf("stub put in the feed: %s, %d s", items[1].Enclosure.Length, items[1].duration)
+	}
+	if len(rep.GeoBlocked) != 1 || rep.GeoBlocked[0] != items[1].Enclosure.Url {
+		t.Errorf("want the stub reported, got %v", rep.GeoBlocked)
+	}
+	if _, ok := audioInfos.get(audioCacheFile(dir), "4"); ok {
+		t.Error("stub info cached")
+	}
+}
+
+func TestDownloadGeoBlocked(t *testing.T) {
+	server := helperGeoCDN()
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "3.mp3")
+	if _, _, err := download(context.Background(), server.URL+"/download?id=3", name); !errors.Is(err, errGeoBlocked) {
+		t.Errorf("want errGeoBlocked, got %v", err)
+	}
+	for _, file := range []string{name, partName(name)} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s left: %v", file, err)
+		}
+	}
+}
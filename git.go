@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	gitDir  string
+	gitPush bool
+)
+
+// maxCommitTitles is how many new episode titles a commit message lists
+const maxCommitTitles = 10
+
+// gitPublisher commits the files to a git working tree (e.g. a checked
+// out Pages branch) and optionally pushes them upstream
+type gitPublisher struct {
+	dir  string
+	push bool
+
+	// brands are generated concurrently, but the working tree and the
+	// index are shared
+	mu sync.Mutex
+}
+
+func newGitPublisher(dir string, push bool) (*gitPublisher, error) {
+	p := &gitPublisher{dir: dir, push: push}
+	if _, err := p.git(context.Background(), "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *gitPublisher) publish(ctx context.Context, name string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := writeFileAtomic(filepath.Join(p.dir, name), data, 0644); err != nil {
+		return err
+	}
+	if _, err := p.git(ctx, "add", "--", name); err != nil {
+		return err
+	}
+	if _, err := p.git(ctx, "diff", "--cached", "--quiet", "--", name); err == nil {
+		// nothing changed
+		return nil
+	}
+	if _, err := p.git(ctx, "commit", "-q", "-m", commitMessage(name, reportFrom(ctx)), "--", name); err != nil {
+		return err
+	}
+	if p.push {
+		if _, err := p.git(ctx, "push", "-q"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gitPublisher) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", p.dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// commitMessage describes the update of the file, listing the new
+// episodes of the brand
+func commitMessage(name string, r *brandReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Update %s", name)
+	if r == nil {
+		return b.String()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.newTitles) == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n\nNew episodes:\n")
+	for i, t := range r.newTitles {
+		if i == maxCommitTitles {
+			fmt.Fprintf(&b, "... and %d more\n", len(r.newTitles)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", t)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommitMessage(t *testing.T) {
+	r := newBrandReport("1")
+	if got := commitMessage("radiorus-1.rss", r); got != "Update radiorus-1.rss" {
+		t.Errorf("got %q", got)
+	}
+
+	r.newTitles = []string{"Первый", "Второй"}
+	want := "Update radiorus-1.rss\n\nNew episodes:\n- Первый\n- Второй"
+	if got := commitMessage("radiorus-1.rss", r); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	r.newTitles = nil
+	for i := 0; i < maxCommitTitles+3; i++ {
+		r.newTitles = append(r.newTitles, fmt.Sprint(i))
+	}
+	if got := commitMessage("radiorus-1.rss", r); !strings.HasSuffix(got, "- 9\n... and 3 more") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestGitPublisher(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir, err := ioutil.TempDir("", "radiorus-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+	run("init", "-q", "--bare", "remote.git")
+	run("clone", "-q", "remote.git", "work")
+	dir += "/work"
+	run("config", "user.name", "Test")
+	run("config", "user.email", "test@example.com")
+
+	if _, err := newGitPublisher(os.TempDir(), false); err == nil {
+		t.Error("want error for a non-repository")
+	}
+	p, err := newGitPublisher(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newBrandReport("1")
+	r.newTitles = []string{"Новый выпуск"}
+	ctx := withReport(context.Background(), r)
+	for i := 0; i < 2; i++ {
+		// publishing the same content twice makes a single commit
+		if err := p.publish(ctx, "radiorus-1.rss", []byte("<rss/>")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := run("rev-list", "--count", "HEAD"); strings.TrimSpace(got) != "1" {
+		t.Errorf("got %s commits", got)
+	}
+	if got := run("log", "-1", "--format=%B"); !strings.Contains(got, "- Новый выпуск") {
+		t.Errorf("got commit message %q", got)
+	}
+	if got := run("--git-dir=../remote.git", "rev-list", "--count", "HEAD"); strings.TrimSpace(got) != "1" {
+		t.Errorf("got %s commits pushed", got)
+	}
+}
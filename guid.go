@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// guidMode is how the guids of the new episodes are made: "url" is the
+// link of the episode page, "uuid" a name-based UUID of it
+var guidMode = "url"
+
+// guidPrefix is prepended to the guids of the new episodes to tell
+// them from the ones of other brands or instances downstream; in the
+// uuid mode it is a part of the name the UUID is derived from
+var guidPrefix string
+
+// urlNamespaceUUID is the RFC 4122 namespace for names that are URLs
+var urlNamespaceUUID = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// newGUID returns the guid the item is to get in the current mode, the
+// item's Id being the one derived from its link
+func newGUID(item *feedItem) string {
+	if guidMode == "uuid" {
+		return uuidV5(urlNamespaceUUID, guidPrefix+item.Id)
+	}
+	return guidPrefix + item.Id
+}
+
+// guidsFile returns the file mapping the brand's episodes to the guids
+// they were published with
+func guidsFile(path, brand string) string {
+	return filepath.Join(path, "."+brand+".guids.json")
+}
+
+// pinsGUIDs is true if the guids are to be looked up in the mapping
+// file: the guids are not the default ones now or were not some time
+// before
+func pinsGUIDs(file string) bool {
+	if guidMode != "url" || guidPrefix != "" {
+		return true
+	}
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// readGUIDs reads the mapping of the episodes to their guids
+func readGUIDs(ctx context.Context, file string) map[string]string {
+	guids := make(map[string]string)
+	if b, err := ioutil.ReadFile(file); err == nil {
+		if err := json.Unmarshal(b, &guids); err != nil {
+			warnf(ctx, "ignoring %s: %v", file, err)
+		}
+	}
+	return guids
+}
+
+// writeGUIDs saves the mapping of the episodes to their guids
+func writeGUIDs(ctx context.Context, file string, guids map[string]string) {
+	b, err := json.MarshalIndent(guids, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(file, b, 0644)
+	}
+	if err != nil {
+		warnf(ctx, "could not save %s: %v", file, err)
+	}
+}
+
+// assignGUIDs gives the items the guids they were published with, so
+// that changing -guid-mode only affects the episodes published after
+// the change and the subscribers do not get the old ones again as new;
+// the episodes not found in the mapping file are looked up in the feed
+// written before, and the ones never published get the guid of the
+// current mode; the mapping file is updated with the items and pruned
+// of the episodes neither in them nor in the feed or its archives
+func assignGUIDs(ctx context.Context, items []*feedItem, file, feedFile string) {
+	guids := readGUIDs(ctx, file)
+
+	published := make(map[string]string)
+	if old, err := readFeed(feedFile); err == nil {
+		for _, ri := range old.Items {
+			// before the mapping file the guids were derived from the
+			// links
+			published[ri.Guid], published[episodeID(ri.Link)] = ri.Guid, ri.Guid
+		}
+	}
+
+	changed := false
+	keep := keptEpisodes(feedFile)
+	for _, item := range items {
+		if item.Id == "" {
+			continue
+		}
+		keep[item.Id] = true
+		guid, ok := guids[item.Id]
+		if !ok {
+			if guid, ok = published[item.Id]; !ok {
+				guid = newGUID(item)
+			}
+			guids[item.Id], changed = guid, true
+		}
+		item.Id = guid
+	}
+	for id, guid := range guids {
+		if !keep[id] && !keep[guid] {
+			delete(guids, id)
+			changed = true
+		}
+	}
+	if changed {
+		writeGUIDs(ctx, file, guids)
+	}
+}
+
+// keptEpisodes returns the episodes and guids found in the feed and the
+// files written alongside it, the per-year archives and the seasons
+func keptEpisodes(feedFile string) map[string]bool {
+	keep := make(map[string]bool)
+	files, _ := filepath.Glob(strings.TrimSuffix(feedFile, ".rss") + "-*.rss")
+	for _, f := range append(files, feedFile) {
+		rss, err := readFeed(f)
+		if err != nil {
+			continue
+		}
+		for _, ri := range rss.Items {
+			keep[ri.Guid], keep[episodeID(ri.Link)] = true, true
+		}
+	}
+	return keep
+}
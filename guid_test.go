@@ -0,0 +1,156 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestNewGUID(t *testing.T) {
+	defer func(m string) { guidMode = m }(guidMode)
+	item := &feedItem{Item: &feeds.Item{Id: "http://www.radiorus.ru/brand/57083/episode/2237849"}}
+
+	guidMode = "url"
+	if got := newGUID(item); got != item.Id {
+		t.Errorf("want %s, got %s", item.Id, got)
+	}
+	guidMode = "uuid"
+	if got, want := newGUID(item), "23e565b4-5cc2-5ae6-bfd4-541f6a8cfa84"; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	defer func(p string) { guidPrefix = p }(guidPrefix)
+	guidPrefix = "example.org:"
+	if got, want := newGUID(item), uuidV5(urlNamespaceUUID, "example.org:"+item.Id); got != want || got == "23e565b4-5cc2-5ae6-bfd4-541f6a8cfa84" {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	guidMode = "url"
+	if got, want := newGUID(item), "example.org:"+item.Id; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestGUIDPrefixPins(t *testing.T) {
+	defer func(m, p string) { guidMode, guidPrefix = m, p }(guidMode, guidPrefix)
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := guidsFile(dir, "57083")
+	feedFile := filepath.Join(dir, "feed.rss")
+
+	old := &brandFeed{Feed: feeds.Feed{Title: "Аэростат", Link: &feeds.Link{}}}
+	old.Add(&feedItem{Item: &feeds.Item{Id: "http://www.radiorus.ru/brand/57083/episode/1", Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episode/1"}}})
+	writeFile(createFeed(old), feedFile)
+
+	guidMode, guidPrefix = "url", "example.org:"
+	if !pinsGUIDs(file) {
+		t.Fatal("guids not pinned with a prefix")
+	}
+	items := []*feedItem{
+		{Item: &feeds.Item{Id: "http://www.radiorus.ru/brand/57083/episode/1"}},
+		{Item: &feeds.Item{Id: "http://www.radiorus.ru/brand/57083/episode/2"}},
+	}
+	assignGUIDs(context.Background(), items, file, feedFile)
+	if items[0].Id != "http://www.radiorus.ru/brand/57083/episode/1" {
+		t.Errorf("published guid changed to %s", items[0].Id)
+	}
+	if items[1].Id != "example.org:http://www.radiorus.ru/brand/57083/episode/2" {
+		t.Errorf("new guid not prefixed: %s", items[1].Id)
+	}
+}
+
+func TestAssignGUIDs(t *testing.T) {
+	defer func(m string) { guidMode = m }(guidMode)
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := guidsFile(dir, "57083")
+	feedFile := filepath.Join(dir, "feed.rss")
+
+	episode := func(n string) *feedItem {
+		link := "https://www.radiorus.ru/brand/57083/episode/" + n
+		return &feedItem{Item: &feeds.Item{Id: episodeID(link), Link: &feeds.Link{Href: link}, Title: n}}
+	}
+	// the feed written before the mapping file
+	old := &brandFeed{Feed: feeds.Feed{Title: "Аэростат", Link: &feeds.Link{}}}
+	old.Add(episode("1"))
+	third := episode("3")
+	third.Id = "third-party-3"
+	old.Add(third)
+	writeFile(createFeed(old), feedFile)
+
+	guidMode = "url"
+	if pinsGUIDs(file) {
+		t.Fatal("guids pinned in the default mode with no mapping file")
+	}
+
+	guidMode = "uuid"
+	if !pinsGUIDs(file) {
+		t.Fatal("guids not pinned in the uuid mode")
+	}
+	items := []*feedItem{episode("1"), episode("2"), episode("3")}
+	assignGUIDs(context.Background(), items, file, feedFile)
+	want := []string{
+		"http://www.radiorus.ru/brand/57083/episode/1",
+		newGUID(episode("2")),
+		"third-party-3",
+	}
+	for i, item := range items {
+		if item.Id != want[i] {
+			t.Errorf("for episode %d want %s, got %s", i+1, want[i], item.Id)
+		}
+	}
+
+	// switching back keeps the guids published in the uuid mode
+	guidMode = "url"
+	if !pinsGUIDs(file) {
+		t.Fatal("guids not pinned with the mapping file")
+	}
+	items = []*feedItem{episode("2"), episode("4")}
+	assignGUIDs(context.Background(), items, file, feedFile)
+	if items[0].Id != want[1] || items[1].Id != "http://www.radiorus.ru/brand/57083/episode/4" {
+		t.Errorf("want %s and the url of episode 4, got %s and %s", want[1], items[0].Id, items[1].Id)
+	}
+
+	guids := readGUIDs(context.Background(), file)
+	if len(guids) != 4 || guids["http://www.radiorus.ru/brand/57083/episode/3"] != "third-party-3" {
+		t.Errorf("bad mapping: %v", guids)
+	}
+
+	// the episodes gone from the feed are kept while in its archives
+	archive := &brandFeed{Feed: feeds.Feed{Title: "Аэростат", Link: &feeds.Link{}}}
+	archive.Add(third)
+	writeFile(createFeed(archive), filepath.Join(dir, "feed-2019.rss"))
+	if err := os.Remove(feedFile); err != nil {
+		t.Fatal(err)
+	}
+	assignGUIDs(context.Background(), []*feedItem{episode("4")}, file, feedFile)
+	guids = readGUIDs(context.Background(), file)
+	if len(guids) != 2 || guids["http://www.radiorus.ru/brand/57083/episode/3"] != "third-party-3" ||
+		guids["http://www.radiorus.ru/brand/57083/episode/4"] == "" {
+		t.Errorf("bad pruned mapping: %v", guids)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gorilla/feeds"
+)
+
+func importCmd(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	path := fs.String("path", "./", "path to write the archives to")
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	fs.StringVar(&baseURL, "base-url", "", "URL the feeds are published under")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import [options] file.rss\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if !strings.HasSuffix(*path, "/") {
+		*path += "/"
+	}
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	b, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	imported, known, undated, err := importFeed(b, *path, *brand)
+	if err != nil {
+		log.Fatalf("%s: %v", fs.Arg(0), err)
+	}
+	noticef("%d episodes imported, %d already archived, %d skipped for having no date", imported, known, undated)
+}
+
+// importFeed adds the items of the RSS document that are not archived
+// yet to the brand's archives, keeping their guids so that the feed
+// stays continuous for the subscribers; the items without a date are
+// skipped, as there is no telling which archive they belong to
+func importFeed(b []byte, path, brand string) (imported, known, undated int, err error) {
+	rss, err := parseFeed(b)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	durations := itemDurations(b)
+
+	archived := make(map[string]bool)
+	for _, file := range append(brandArchives(path, brand), feedFilename(path, brand)) {
+		if a, err := readFeed(file); err == nil {
+			for _, ri := range a.Items {
+				archived[ri.Guid], archived[ri.Link] = true, true
+			}
+		}
+	}
+
+	feed := &brandFeed{Feed: feeds.Feed{
+		Title:       rss.Title,
+		Link:        &feeds.Link{Href: rss.Link},
+		Description: rss.Description,
+	}}
+	if current, err := readFeed(feedFilename(path, brand)); err == nil {
+		feed.Title, feed.Link.Href, feed.Description = current.Title, current.Link, current.Description
+	}
+	if rss.Image != nil {
+		feed.Image = &feeds.Image{Url: rss.Image.Url, Title: rss.Image.Title, Link: rss.Image.Link}
+	}
+
+	// the episodes still on the site are to keep the imported guids
+	// when the feed is made from the site
+	ctx := context.Background()
+	file := guidsFile(path, brand)
+	pins := pinsGUIDs(file)
+	guids := readGUIDs(ctx, file)
+	for _, ri := range rss.Items {
+		if ri.Link == "" {
+			continue
+		}
+		key := episodeID(ri.Link)
+		if _, ok := guids[key]; !ok && (pins || ri.Guid != key) {
+			guids[key], pins = ri.Guid, true
+		}
+	}
+	if pins {
+		writeGUIDs(ctx, file, guids)
+	}
+
+	for _, ri := range rss.Items {
+		if archived[ri.Guid] || (ri.Link != "" && archived[ri.Link]) {
+			known++
+			continue
+		}
+		item := itemFromRss(ri)
+		if item.Created.IsZero() {
+			undated++
+			continue
+		}
+		if d := durations[ri.Guid]; d != 0 {
+			item.duration = d
+		}
+		archived[ri.Guid] = true
+		feed.Items = append(feed.Items, item)
+	}
+
+	if len(feed.Items) != 0 {
+		if _, err := writeArchives(feed, path, brand); err != nil {
+			return 0, known, undated, err
+		}
+	}
+	return len(feed.Items), known, undated, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportFeed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(filepath.Separator)
+
+	guid := []byte("<guid>**localhost**/brand/57083/episode/2237849</guid>")
+	feed := helperLoadBytes(t, "TestServedFeed.golden")
+	feed = bytes.Replace(feed, guid, append(guid, "<itunes:duration>3540</itunes:duration>"...), 1)
+	undated := []byte("<item><title>Без даты</title><guid>undated</guid></item></channel>")
+	feed = bytes.Replace(feed, []byte("</channel>"), undated, 1)
+
+	imported, known, skipped, err := importFeed(feed, path, "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 10 || known != 0 || skipped != 1 {
+		t.Fatalf("want 10 imported, 0 known, 1 skipped; got %d, %d, %d", imported, known, skipped)
+	}
+
+	archives := brandArchives(path, "57083")
+	if len(archives) == 0 {
+		t.Fatal("no archives written")
+	}
+	var items, duration int
+	for _, file := range archives {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := parseFeed(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items += len(a.Items)
+		if d, ok := itemDurations(b)["**localhost**/brand/57083/episode/2237849"]; ok {
+			duration = d
+		}
+	}
+	if items != 10 {
+		t.Errorf("want 10 episodes archived, got %d", items)
+	}
+	if duration != 3540 {
+		t.Errorf("want duration 3540 archived, got %d", duration)
+	}
+
+	// importing again changes nothing
+	imported, known, _, err = importFeed(feed, path, "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 0 || known != 10 {
+		t.Errorf("want 0 imported and 10 known on the second import, got %d and %d", imported, known)
+	}
+
+	if _, _, _, err := importFeed([]byte("not a feed"), path, "57083"); err == nil {
+		t.Error("no error for a bad feed")
+	}
+}
+
+func TestMergeItemsKeepsDurations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "archive.rss")
+
+	guid := []byte("<guid>**localhost**/brand/57083/episode/2237849</guid>")
+	feed := helperLoadBytes(t, "TestServedFeed.golden")
+	writeFile(bytes.Replace(feed, guid, append(guid, "<itunes:duration>3540</itunes:duration>"...), 1), file)
+
+	merged := mergeItems(nil, file)
+	for _, item := range merged {
+		if item.Id == "**localhost**/brand/57083/episode/2237849" {
+			if d := item.duration; d != 3540 {
+				t.Errorf("want duration 3540, got %d", d)
+			}
+			return
+		}
+	}
+	t.Error("episode not merged")
+}
+
+func TestImportPinsGUIDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(filepath.Separator)
+
+	feed := []byte(`<rss version="2.0"><channel><title>Аэростат</title>
+<item><title>Первый</title><link>https://www.radiorus.ru/brand/57083/episode/1</link><guid>other-1</guid><pubDate>Sun, 26 Jan 2020 14:10:00 +0300</pubDate></item>
+<item><title>Второй</title><link>https://www.radiorus.ru/brand/57083/episode/2</link><guid>http://www.radiorus.ru/brand/57083/episode/2</guid><pubDate>Sun, 02 Feb 2020 14:10:00 +0300</pubDate></item>
+</channel></rss>`)
+	if _, _, _, err := importFeed(feed, path, "57083"); err != nil {
+		t.Fatal(err)
+	}
+
+	guids := readGUIDs(context.Background(), guidsFile(path, "57083"))
+	if guids["http://www.radiorus.ru/brand/57083/episode/1"] != "other-1" {
+		t.Errorf("imported guid not pinned: %v", guids)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"path/filepath"
+
+	"github.com/gorilla/feeds"
+)
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Передачи «Радио России»</title>
+</head>
+<body>
+<h1>Передачи «Радио России»</h1>
+{{range .}}<div class="feed">
+{{with .Image}}<img src="{{.Url}}" alt="{{.Title}}" width="200">
+{{end}}<h2><a href="{{.Link}}">{{.Title}}</a></h2>
+<p>{{.Description}}</p>
+<p><a href="{{.File}}">RSS</a></p>
+</div>
+{{end}}</body>
+</html>
+`))
+
+type indexEntry struct {
+	Title       string
+	Description string
+	Link        string
+	Image       *feeds.Image
+	File        string
+}
+
+// createIndex renders an HTML page listing the generated feeds
+func createIndex(results []result) []byte {
+	var entries []indexEntry
+	for _, r := range results {
+		e := indexEntry{
+			Title:       r.feed.Title,
+			Description: r.feed.Description,
+			Link:        r.feed.Link.Href,
+			Image:       r.feed.Image,
+			File:        filepath.Base(r.file),
+		}
+		entries = append(entries, e)
+	}
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, entries); err != nil {
+		log.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestCreateIndex(t *testing.T) {
+	var results []result
+	for _, test := range []struct{ brand, link, page string }{
+		{"57083", "https://smotrim.ru/brand/57083", "smotrim.57083"},
+		{"57083", "http://www.radiorus.ru/brand/57083/episodes", "episodes.noimg"},
+	} {
+		feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: test.link}}}
+		if err := populateFeed(feed, cleanText(helperLoadBytes(t, test.page))); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result{brand: test.brand, file: feedFilename("./", test.brand), feed: feed})
+	}
+
+	golden := filepath.Join("testdata", t.Name()+".golden")
+	assertGolden(t, createIndex(results), golden)
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// appleCategory is the Apple Podcasts category of the feeds for the
+// brands that do not configure their own, as "Category" or
+// "Category/Subcategory"; no iTunes channel elements if empty
+var appleCategory string
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type itunesCategory struct {
+	Text        string          `xml:"text,attr"`
+	Subcategory *itunesCategory `xml:"itunes:category"`
+}
+
+// parseAppleCategory parses the "Category" or "Category/Subcategory"
+// form, checking the category is one Apple Podcasts accepts
+func parseAppleCategory(s string) (*itunesCategory, error) {
+	parts := strings.SplitN(s, "/", 2)
+	c := &itunesCategory{Text: strings.TrimSpace(parts[0])}
+	if !appleCategories[c.Text] {
+		return nil, fmt.Errorf("%q is not an Apple Podcasts category", c.Text)
+	}
+	if len(parts) == 2 {
+		sub := strings.TrimSpace(parts[1])
+		if sub == "" {
+			return nil, fmt.Errorf("empty subcategory in %q", s)
+		}
+		c.Subcategory = &itunesCategory{Text: sub}
+	}
+	return c, nil
+}
+
+// feedAppleCategory returns the Apple Podcasts category of the brand's
+// feed
+func feedAppleCategory(bc brandConfig) string {
+	if bc.Category != "" {
+		return bc.Category
+	}
+	return appleCategory
+}
+
+// withItunes adds the channel elements Apple Podcasts requires: the
+// category, the explicit flag, and the programme image as itunes:image;
+// the category is expected to be valid
+func withItunes(category string, explicit bool) extension {
+	c, _ := parseAppleCategory(category)
+	return func(doc *rssDoc) {
+		doc.ItunesNamespace = itunesNS
+		ch := doc.Channel
+		ch.ItunesCategory = c
+		ch.ItunesExplicit = "false"
+		if explicit {
+			ch.ItunesExplicit = "true"
+		}
+		if ch.Image != nil && ch.Image.Url != "" {
+			ch.ItunesImage = &itunesImage{Href: ch.Image.Url}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestParseAppleCategory(t *testing.T) {
+	for s, want := range map[string]string{
+		"Music":                         "Music",
+		"Society & Culture/Documentary": "Society & Culture/Documentary",
+		" History / Modern ":            "History/Modern",
+		"Музыка":                        "",
+		"Music/":                        "",
+		"music":                         "",
+	} {
+		c, err := parseAppleCategory(s)
+		got := ""
+		if err == nil {
+			got = c.Text
+			if c.Subcategory != nil {
+				got += "/" + c.Subcategory.Text
+			}
+		}
+		if got != want {
+			t.Errorf("%q: want %q, got %q (%v)", s, want, got, err)
+		}
+	}
+}
+
+func TestFeedAppleCategory(t *testing.T) {
+	defer func(c string) { appleCategory = c }(appleCategory)
+	appleCategory = "Music"
+	if got := feedAppleCategory(brandConfig{}); got != "Music" {
+		t.Errorf("want Music by default, got %q", got)
+	}
+	if got := feedAppleCategory(brandConfig{Category: "History"}); got != "History" {
+		t.Errorf("want brand category, got %q", got)
+	}
+}
+
+func TestWithItunes(t *testing.T) {
+	feed := &brandFeed{
+		Feed: feeds.Feed{
+			Title:       "Аэростат",
+			Link:        &feeds.Link{Href: "https://smotrim.ru/brand/57083"},
+			Description: "Передача",
+			Image:       &feeds.Image{Url: "https://example.com/cover.jpg", Title: "Аэростат", Link: "https://smotrim.ru/brand/57083"},
+		},
+		Items: []*feedItem{{Item: &feeds.Item{
+			Id:        "https://smotrim.ru/audio/2456411",
+			Title:     "Выпуск",
+			Link:      &feeds.Link{Href: "https://smotrim.ru/audio/2456411"},
+			Enclosure: enclosure("2456411"),
+			Created:   time.Date(2020, time.May, 1, 20, 0, 0, 0, moscow),
+		}}},
+	}
+	x := createFeed(feed, withLanguage("ru"), withItunes("Music/Music History", true))
+	for _, want := range []string{
+		`xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`,
+		`<itunes:image href="https://example.com/cover.jpg"></itunes:image>`,
+		`<itunes:category text="Music">`,
+		`<itunes:category text="Music History"></itunes:category>`,
+		`<itunes:explicit>true</itunes:explicit>`,
+	} {
+		if !strings.Contains(string(x), want) {
+			t.Errorf("no %s in\n%s", want, x)
+		}
+	}
+
+	// everything Apple requires is in what the generator produces
+	if problems := validateApple(x, false); len(problems) != 0 {
+		t.Errorf("want the feed to pass, got %v", problems)
+	}
+	if problems := validateApple(createFeed(feed, withLanguage("ru")), false); len(problems) != 3 {
+		t.Errorf("want no category, explicit and image without withItunes, got %v", problems)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// layout is a version of the programme page markup the site has
+// shipped, with the way to extract the episodes from it
+type layout struct {
+	name string
+	// site is what the layout is used on, "" for any site but smotrim.ru
+	site string
+	// episodes extracts the episodes from the page
+	episodes func(feed *brandFeed, doc *goquery.Document, page []byte) ([]*feedItem, error)
+}
+
+// layouts are tried in order, the first one to find episodes wins; a new
+// redesign of the site is supported by adding one more layout here
+var layouts = []layout{
+	{
+		name: "smotrim",
+		site: "smotrim.ru",
+		episodes: func(feed *brandFeed, doc *goquery.Document, _ []byte) ([]*feedItem, error) {
+			return smotrimEpisodes(feed.Link.Href, doc)
+		},
+	},
+	{
+		name: "radiorus",
+		episodes: func(feed *brandFeed, doc *goquery.Document, _ []byte) ([]*feedItem, error) {
+			return radiorusEpisodes(doc, episodeURLPrefix(feed.Link.Href))
+		},
+	},
+	{
+		name: "radiorus-legacy",
+		episodes: func(feed *brandFeed, _ *goquery.Document, page []byte) ([]*feedItem, error) {
+			return radiorusEpisodesLegacy(episodeURLPrefix(feed.Link.Href), page)
+		},
+	},
+}
+
+// populateEpisodes adds the episodes found on the page to the feed,
+// trying the layouts of the site in order; it returns the name of the
+// layout used
+func populateEpisodes(feed *brandFeed, doc *goquery.Document, page []byte) (string, error) {
+	site := parseSite(feed)
+	var (
+		fallback string
+		empty    bool
+		lastErr  error
+	)
+	for _, l := range layouts {
+		if l.site != site && (l.site != "" || site == "smotrim.ru") {
+			continue
+		}
+		items, err := l.episodes(feed, doc, page)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(items) == 0 {
+			// the programme may have no episodes yet, but the next
+			// layouts may know better
+			if !empty {
+				fallback, empty = l.name, true
+			}
+			continue
+		}
+		for _, item := range items {
+			feed.Add(item)
+		}
+		return l.name, nil
+	}
+	if empty {
+		return fallback, nil
+	}
+	return "", lastErr
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestLayouts(t *testing.T) {
+	legacy := []byte(`<h2><a href="/brand/57083">Аэростат</a></h2>
+<div class="brand__list--wrap--item">
+<a href="/brand/57083/episode/1" class="title">Первый</a>
+<a href="/brand/57083/episode/1" class="brand-time">26.01.2020 в 14:10</a>
+<div class="audio-count" data-type="audio" data-id="2"></div>`)
+
+	tests := map[string]struct {
+		link   string
+		page   []byte
+		layout string
+		items  int
+	}{
+		"radiorus": {"http://www.radiorus.ru/brand/57083/episodes", cleanText(helperLoadBytes(t, "episodes")), "radiorus", 10},
+		"smotrim":  {"https://smotrim.ru/brand/57083", cleanText(helperLoadBytes(t, "smotrim.57083")), "smotrim", 0},
+		"legacy":   {"http://www.radiorus.ru/brand/57083/episodes", legacy, "radiorus-legacy", 1},
+		"empty":    {"http://www.radiorus.ru/brand/57083/episodes", []byte(`<h2>Аэростат</h2>`), "radiorus", 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: tc.link}}}
+			layout, err := parseProgrammePage(feed, tc.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if layout != tc.layout {
+				t.Errorf("want layout %q, got %q", tc.layout, layout)
+			}
+			if tc.items != 0 && len(feed.Items) != tc.items {
+				t.Errorf("want %d episodes, got %d", tc.items, len(feed.Items))
+			}
+		})
+	}
+}
+
+func TestLayoutsBadPage(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"}}}
+	if _, err := parseProgrammePage(feed, cleanText(helperLoadBytes(t, "episodes.badep.0"))); err != errBadEpisode {
+		t.Errorf("want errBadEpisode, got %v", err)
+	}
+}
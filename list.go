@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// listedEpisode is an episode as the parser sees it on the listing page
+type listedEpisode struct {
+	Title   string    `json:"title"`
+	Date    time.Time `json:"date"`
+	AudioID string    `json:"audioId"`
+	URL     string    `json:"url"`
+}
+
+func listCmd(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	smotrim := fs.Bool("smotrim", false, "use smotrim.ru directly")
+	format := fs.String("format", "table", "output format (table or json)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s list [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	feed := getFeed(brandURL(*brand, *smotrim))
+	if err := printEpisodes(os.Stdout, listEpisodes(feed), *format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func listEpisodes(feed *brandFeed) []listedEpisode {
+	eps := make([]listedEpisode, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		eps = append(eps, listedEpisode{
+			Title:   item.Title,
+			Date:    item.Created,
+			AudioID: audioID(item),
+			URL:     item.Link.Href,
+		})
+	}
+	return eps
+}
+
+func printEpisodes(w io.Writer, eps []listedEpisode, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(eps)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "DATE\tAUDIO\tTITLE\tURL")
+		for _, ep := range eps {
+			date := "-"
+			if !ep.Date.IsZero() {
+				date = ep.Date.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", date, ep.AudioID, ep.Title, ep.URL)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// audioID returns the audio ID the item was scraped with, or, for the
+// items read back from the feeds, extracts it from the enclosure URL;
+// the URL may no longer contain it once the enclosure is resolved
+func audioID(item *feedItem) string {
+	if item.Enclosure == nil {
+		return ""
+	}
+	if id := item.audioID; id != "" {
+		return id
+	}
+	u, err := url.Parse(item.Enclosure.Url)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("id")
+}
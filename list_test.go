@@ -0,0 +1,50 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestListEpisodes(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
+	}}
+	page := cleanText(helperLoadBytes(t, "episodes"))
+	if err := populateFeed(feed, page); err != nil {
+		t.Fatal(err)
+	}
+	eps := listEpisodes(feed)
+
+	for _, format := range []string{"table", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printEpisodes(&buf, eps, format); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "TestListEpisodes."+format+".golden")
+			assertGolden(t, buf.Bytes(), golden)
+		})
+	}
+
+	if err := printEpisodes(&bytes.Buffer{}, eps, "xml"); err == nil {
+		t.Error("no error for unknown format")
+	}
+}
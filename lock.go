@@ -0,0 +1,45 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// lockWait makes the run wait for the one holding the lock instead
+// of exiting
+var lockWait bool
+
+// lockName is the lock file held in the output path while running
+const lockName = ".radiorus-rss.lock"
+
+var errLocked = errors.New("locked by another process")
+
+// lockOutput takes the lock of the output path, so that the runs
+// overlapping each other don't write the same files; it returns
+// errLocked if the lock is held by another process and wait is false
+func lockOutput(path string, wait bool) (*os.File, error) {
+	f, err := os.OpenFile(path+lockName, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, wait); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
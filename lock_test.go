@@ -0,0 +1,62 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLockOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no file locking on Windows")
+	}
+	dir, err := ioutil.TempDir("", "radiorus-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir += "/"
+
+	first, err := lockOutput(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockOutput(dir, false); err != errLocked {
+		t.Fatalf("want errLocked, got %v", err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		second, err := lockOutput(dir, true)
+		if err == nil {
+			second.Close()
+		}
+		locked <- err
+	}()
+	select {
+	case err := <-locked:
+		t.Fatalf("lock taken while held: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	first.Close()
+	if err := <-locked; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// quiet suppresses the output that is not about errors
+	quiet bool
+
+	// logFile is where the log goes instead of stderr if set, it is
+	// rotated once bigger than logMaxSize or older than logMaxAge
+	logFile    string
+	logMaxSize int64 = 10 << 20
+	logMaxAge        = 7 * 24 * time.Hour
+	logKeep          = 5
+)
+
+// noticef logs the message unless in quiet mode
+func noticef(format string, v ...interface{}) {
+	if !quiet {
+		log.Printf(format, v...)
+	}
+}
+
+// warnf logs the warning unless in quiet mode and records it in the
+// report of the brand being generated, if any
+func warnf(ctx context.Context, format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	if !quiet {
+		log.Print(msg)
+	}
+	if r := reportFrom(ctx); r != nil {
+		r.mu.Lock()
+		r.Warnings = append(r.Warnings, msg)
+		r.mu.Unlock()
+	}
+}
+
+// rotatingFile is a log file that is rotated by size and age, keeping
+// up to keep old files as name.1 (the latest) to name.N
+type rotatingFile struct {
+	name    string
+	maxSize int64
+	maxAge  time.Duration
+	keep    int
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	created time.Time
+}
+
+func openRotatingFile(name string, maxSize int64, maxAge time.Duration, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{name: name, maxSize: maxSize, maxAge: maxAge, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file for appending; the age of an existing file
+// is counted from its last modification as its creation time is not
+// known
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size, r.created = f, fi.Size(), time.Now()
+	if fi.Size() != 0 {
+		r.created = fi.ModTime()
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tooBig := r.maxSize > 0 && r.size != 0 && r.size+int64(len(p)) > r.maxSize
+	tooOld := r.maxAge > 0 && r.size != 0 && time.Since(r.created) > r.maxAge
+	if tooBig || tooOld {
+		if err := r.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "could not rotate %s: %v\n", r.name, err)
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files, dropping the oldest, and starts a new
+// log file
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.keep > 0 {
+		for i := r.keep - 1; i > 0; i-- {
+			_ = os.Rename(r.name+"."+strconv.Itoa(i), r.name+"."+strconv.Itoa(i+1))
+		}
+		if err := os.Rename(r.name, r.name+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.name); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
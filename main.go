@@ -17,163 +17,1004 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/gorilla/feeds"
 )
 
-type subst struct {
-	from string
-	to   string
-}
-
 var (
-	substitutes = []subst{ // these need to be changed to show up properly in the feed
-		{from: `&quot;`, to: `"`},
-		{from: `&ndash;`, to: `–`},
-	}
+	// substitutes are the entities that need to be changed to show up
+	// properly in the feed
+	substitutes = strings.NewReplacer(`&quot;`, `"`, `&ndash;`, `–`)
 
 	programNameRe  = regexp.MustCompile(`<h2>(.+?)?</h2>`)
 	programAboutRe = regexp.MustCompile(`(?s)<div class="brand__content_text__anons">(.+?)?</div>`)
 	programImageRe = regexp.MustCompile(`(?s)<div class="brand\-promo__header">(.+?)?<img src="(.+?)?"(.+?)?alt='(.+?)?'>`)
 	episodeTitleRe = regexp.MustCompile(`title brand\-menu\-link">(.+?)?</a>`)
 	episodeUrlRe   = regexp.MustCompile(`<a href="/brand/(.+?)?" class="title`)
+	episodeDayRe   = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+) в (\d+):(\d+)`)
+	episodeDateRe  = regexp.MustCompile(`brand\-time brand\-menu\-link">(.+?)?\.(.+?)?\.(.+?)? в (.+?)?:(.+?)?</a>`)
+	enclosureRe    = regexp.MustCompile(`data\-type="audio"\s+data\-id="(.+?)?">`)
+	episodeRe      = regexp.MustCompile(`(?s)<div class="brand__list\-\-wrap\-\-item">(.+?)?data-id="(.+?)"></div>`)
+	tagRe          = regexp.MustCompile(`<(.+?)?>`)
+	linkTagRe      = regexp.MustCompile(`</?a.*?>`)
 
-	outputPath, programNumber string
-	smotrim                   bool
+	outputPath, programNumber, strict, xslt, baseURL  string
+	configFile, defaultCron, listenAddr, adminToken   string
+	corsOrigins, tlsCert, tlsKey, basicAuth           string
+	pushGateway, otlpEndpoint, noAudio, quality       string
+	smotrim, index, gzipped, daemonMode, resolveAudio bool
+	retries, latest, jobs                             int
+	rateLimit                                         float64
+
+	retryDelay      = time.Second
+	resolveTTL      = 24 * time.Hour
+	shutdownTimeout = 30 * time.Second
+	xmlIndent       = "  "
+
+	// fetchCtx is cancelled to abort the requests in flight on shutdown
+	fetchCtx = context.Background()
 
 	errBadEpisode = fmt.Errorf("bad episode")
 	errCantParse  = fmt.Errorf("could not parse page")
+	errServer     = fmt.Errorf("server error")
 
 	moscow = time.FixedZone("Moscow Time", int((3 * time.Hour).Seconds()))
+
+	months = [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"}
 )
 
+// commands are run instead of generating the feed when named as the
+// first argument, they parse the rest of the arguments themselves
+var commands = map[string]func(args []string){
+	"validate":  validateCmd,
+	"compat":    compatCmd,
+	"list":      listCmd,
+	"episode":   episodeCmd,
+	"search":    searchCmd,
+	"catalogue": catalogueCmd,
+	"diff":      diffCmd,
+	"stats":     statsCmd,
+	"record":    recordCmd,
+	"selftest":  selftestCmd,
+	"backfill":  backfillCmd,
+	"export":    exportCmd,
+	"import":    importCmd,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
 	flag.StringVar(&outputPath, "path", "./", "path to put resulting RSS file in")
-	flag.StringVar(&programNumber, "brand", "57083", "brand number (defaults to Aerostat)")
+	flag.StringVar(&programNumber, "brand", "57083", "brand number (defaults to Aerostat), comma-separated for several brands")
 	flag.BoolVar(&smotrim, "smotrim", false, "use smotrim.ru directly")
+	flag.IntVar(&jobs, "jobs", 4, "number of brands to generate concurrently")
+	flag.Float64Var(&rateLimit, "rate", 0, "maximum requests per second to the site across all brands, 0 for unlimited")
+	flag.DurationVar(&fetchTimeout, "timeout", fetchTimeout, "timeout for fetching a single page")
+	flag.BoolVar(&http2, "http2", http2, "use HTTP/2 when the site supports it")
+	flag.BoolVar(&ip4, "ip4", false, "connect to the site over IPv4 only")
+	flag.BoolVar(&ip6, "ip6", false, "connect to the site over IPv6 only")
+	flag.StringVar(&bindAddr, "bind", "", "local IP address or network interface to connect to the site from")
+	flag.StringVar(&resolverAddr, "resolver", "", "DNS server to resolve the site's names with: host[:port], tls://host[:port] for DNS over TLS, or https:// URL for DNS over HTTPS")
+	flag.StringVar(&noAudio, "no-audio", "keep", "what to do with the episodes that have no audio: keep them without enclosure or drop")
+	flag.StringVar(&reruns, "reruns", reruns, "what to do with the repeat broadcasts of the episodes: keep, drop, or mark them \"(повтор)\"")
+	flag.StringVar(&guidMode, "guid-mode", guidMode, "how the guids of the new episodes are made: url of the episode page or uuid; the episodes published before keep theirs")
+	flag.StringVar(&guidPrefix, "guid-prefix", "", "prefix (e.g. a domain) for the guids of the new episodes, to make them globally unique")
+	flag.BoolVar(&mergeParts, "merge-parts", false, "merge the episodes published in parts (\"часть 1\", \"часть 2\") into a single item")
+	flag.Var(headerFlag(extraHeaders), "header", "extra HTTP header to send to the site, as \"Name: value\"; can be repeated")
+	flag.BoolVar(&polite, "polite", false, "obey robots.txt of the site, including its crawl delay")
+	flag.StringVar(&cookieFile, "cookies", "", "file to keep the cookies set by the site in between runs")
+	flag.Var(&titlePrefix, "strip-prefix", "strip the programme title, or the prefix given as -strip-prefix=PREFIX, from the episode titles")
+	flag.StringVar(&titleTmpl, "title-template", "", "Go template to build the episode titles from, e.g. \"{{.Date}} {{.Title}}\"")
+	flag.BoolVar(&typography, "typography", false, "make the quotes, dashes and ellipses in the titles and descriptions consistent")
+	flag.BoolVar(&trackUpdates, "track-updates", false, "remember the episode titles and descriptions to mark the ones edited on the site as updated")
+	flag.BoolVar(&pageLink, "page-link", false, "add the link to the episode page to the episode descriptions")
+	flag.StringVar(&scheduleURL, "schedule", "", "URL of the station schedule page for a day, with {yyyy}, {mm} and {dd} for the date, to take the air times of the episodes listed with dates only from")
+	flag.StringVar(&quality, "quality", "", "audio quality to pick when the episode offers several (high or low)")
+	flag.BoolVar(&probeAudio, "probe-audio", false, "fetch the beginning of the audio files to put their real size and duration in the feed, remembering them by audio ID")
+	flag.BoolVar(&resolveAudio, "resolve-audio", false, "follow the audio download redirects and put the final URLs in the feed")
+	flag.DurationVar(&resolveTTL, "resolve-ttl", resolveTTL, "how long to reuse the resolved audio URLs before resolving them again")
+	flag.IntVar(&retries, "retries", 3, "number of times to retry failed episode page fetches")
+	flag.StringVar(&strict, "strict", "", "fail if the feed does not comply with requirements (apple), or lacks anything that should have been found on the site (extract); comma-separated")
+	flag.BoolVar(&index, "index", false, "also create index.html listing all the generated feeds")
+	flag.StringVar(&xslt, "xslt", "", "URL of the XSLT stylesheet to reference in the feed (\"default\" to write and use the bundled one)")
+	flag.StringVar(&seasons, "seasons", "", "split the episodes into seasons by the year of broadcast (year) or the season named in the title (title), writing a feed per season")
+	flag.IntVar(&latest, "latest", 0, "keep only the latest N items in the feed, archiving the rest in per-year feeds")
+	flag.StringVar(&defaultFunding.URL, "funding-url", "", "URL to support the feed at, put in the feed as podcast:funding")
+	flag.StringVar(&defaultFunding.Message, "funding-message", "", "text of the -funding-url link")
+	flag.StringVar(&language, "language", language, "language of the feeds, empty for none")
+	flag.StringVar(&appleCategory, "apple-category", "", "Apple Podcasts category of the feeds, as Category or Category/Subcategory, to put in the feed with the other iTunes channel elements")
+	flag.StringVar(&outputFormat, "format", "rss", "output format: rss, or meta-json for everything scraped as JSON")
+	flag.StringVar(&baseURL, "base-url", "", "URL the feeds are published under")
+	flag.BoolVar(&subscribePage, "subscribe", false, "write a page with the links to subscribe to each feed and its QR code, needs -base-url")
+	flag.BoolVar(&gzipped, "gzip", false, "also write a gzip-compressed copy of the feed")
+	flag.BoolVar(&quiet, "quiet", false, "only output errors")
+	flag.StringVar(&logFile, "log-file", "", "file to write the log to instead of the standard error")
+	flag.Int64Var(&logMaxSize, "log-max-size", logMaxSize, "size in bytes to rotate the -log-file at, 0 to never rotate by size")
+	flag.DurationVar(&logMaxAge, "log-max-age", logMaxAge, "age to rotate the -log-file at, 0 to never rotate by age")
+	flag.IntVar(&logKeep, "log-keep", logKeep, "number of rotated log files to keep")
+	flag.StringVar(&reportFile, "report", "", "file to write the JSON report of the run to")
+	flag.StringVar(&publishURL, "publish-url", "", "URL to also upload the feeds to, {name} standing for the file name")
+	flag.StringVar(&publishMethod, "publish-method", publishMethod, "HTTP method to upload the feeds with (PUT or POST)")
+	flag.Var(headerFlag(publishHeaders), "publish-header", "extra HTTP header for the uploads, e.g. \"Authorization: Bearer ...\"; can be repeated")
+	flag.StringVar(&ftpURL, "ftp-url", "", "ftp:// or ftps:// URL of the directory to also upload the feeds to")
+	flag.StringVar(&gitDir, "git-dir", "", "git working tree to also commit the feeds to")
+	flag.BoolVar(&gitPush, "git-push", false, "push the -git-dir commits to the upstream")
+	flag.StringVar(&siteDir, "site", "", "directory to also render a static website of each brand to")
+	flag.StringVar(&siteTemplatesDir, "site-templates", "", "directory with brand.html and episode.html templates overriding the default website ones")
+	flag.BoolVar(&lockWait, "wait-lock", false, "wait for another run writing to the same -path to finish instead of exiting")
+	flag.Var(modeFlag{&fileMode}, "file-mode", "permissions of the files written, in octal")
+	flag.Var(&ownerFlag{owner: &fileOwner}, "owner", "user[:group] to give the files written to")
+	flag.StringVar(&mirrorDir, "mirror", "", "directory, or s3://bucket/prefix, to download the episodes' audio to")
+	flag.StringVar(&mirrorURL, "mirror-url", "", "URL the -mirror directory is published under, to list the copies in the feeds")
+	flag.StringVar(&s3Endpoint, "s3-endpoint", "", "API endpoint of the s3:// -mirror storage, AWS in -s3-region if empty")
+	flag.StringVar(&s3Region, "s3-region", s3Region, "region of the s3:// -mirror bucket")
+	flag.IntVar(&mirrorKeep, "mirror-keep", 0, "number of latest episodes of each brand to keep in the -mirror, all if 0")
+	flag.Var(bytesFlag{&mirrorMaxSize}, "mirror-max-size", "maximum size of each brand's -mirror, with optional k, m or g suffix, e.g. 10g")
+	flag.StringVar(&mirrorSince, "mirror-since", "", "only keep the episodes aired since the date (YYYY-MM-DD) in the -mirror")
+	flag.IntVar(&mirrorJobs, "mirror-jobs", mirrorJobs, "number of audio files to download at once for -mirror, across all brands")
+	flag.Var(bytesFlag{&limitRate}, "limit-rate", "maximum bandwidth of the -mirror downloads across all brands, in bytes per second with optional k, m or g suffix, e.g. 500k")
+	flag.StringVar(&ffprobe, "ffprobe", ffprobe, "ffprobe binary to probe the -mirror audio with, empty to only read the MP3 headers")
+	flag.StringVar(&warcFile, "warc", "", "WARC file to append every fetched page to (compressed if named .gz)")
+	flag.StringVar(&configFile, "config", "", "JSON file with the brands configuration")
+	flag.BoolVar(&daemonMode, "daemon", false, "keep running and regenerate the feeds on schedule")
+	flag.StringVar(&defaultCron, "cron", "0 * * * *", "schedule (cron expression) for the brands that do not define their own in daemon mode")
+	flag.StringVar(&listenAddr, "listen", "", "address to serve the feeds and the health check on in daemon mode, e.g. :8080")
+	flag.StringVar(&tlsCert, "tls-cert", "", "certificate file to serve HTTPS with")
+	flag.StringVar(&tlsKey, "tls-key", "", "private key file for the -tls-cert certificate")
+	flag.StringVar(&basicAuth, "basic-auth", os.Getenv("RADIORUS_BASIC_AUTH"), "user:password required to access the served files")
+	flag.StringVar(&corsOrigins, "cors", "", "comma-separated origins allowed to fetch the served feeds from browsers, \"*\" for any")
+	flag.StringVar(&proxyBrands, "proxy", "", "comma-separated brands (or * for any) to generate on request at /proxy/BRAND.rss in server mode")
+	flag.DurationVar(&proxyTTL, "proxy-ttl", proxyTTL, "how long to cache the -proxy feeds for")
+	flag.IntVar(&proxyMax, "proxy-max", proxyMax, "maximum number of -proxy feeds to cache")
+	flag.StringVar(&adminToken, "admin-token", os.Getenv("RADIORUS_ADMIN_TOKEN"), "bearer token for the admin endpoints, they are disabled if empty")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", shutdownTimeout, "how long to wait for the feeds being generated on shutdown in daemon mode")
+	flag.StringVar(&alertURL, "alert-url", "", "URL to POST a JSON alert to when a brand fails -alert-after times in a row, and when it recovers")
+	flag.IntVar(&alertAfter, "alert-after", alertAfter, "number of failed refreshes of a brand in a row to send the -alert-url alert after")
+	flag.DurationVar(&staleAfter, "stale-after", 0, "send the -alert-url alert when a brand has no new episodes for this long, e.g. 720h")
+	flag.StringVar(&pushGateway, "pushgateway", "", "Prometheus Pushgateway URL to push the run metrics to")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "OpenTelemetry collector OTLP/HTTP endpoint to export traces to, e.g. http://localhost:4318")
+	flag.StringVar(&xmlIndent, "xml-indent", xmlIndent, "indentation for the XML output, empty for compact single-line output")
 	flag.Parse()
 
-	url := "https://www.radiorus.ru/brand/" + programNumber + "/episodes"
-	if smotrim {
-		url = "https://smotrim.ru/brand/" + programNumber
+	if logFile != "" {
+		f, err := openRotatingFile(logFile, logMaxSize, logMaxAge, logKeep)
+		if err != nil {
+			log.Fatalf("could not open log file: %v", err)
+		}
+		defer f.Close()
+		log.SetOutput(f)
+	}
+
+	if publishURL != "" {
+		p, err := newHTTPPublisher(publishURL, publishMethod, publishHeaders)
+		if err != nil {
+			log.Fatal(err)
+		}
+		publishers = append(publishers, p)
+	}
+
+	if ftpURL != "" {
+		p, err := newFTPPublisher(ftpURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		publishers = append(publishers, p)
+	}
+
+	if gitDir != "" {
+		p, err := newGitPublisher(gitDir, gitPush)
+		if err != nil {
+			log.Fatal(err)
+		}
+		publishers = append(publishers, p)
+	}
+
+	if siteDir != "" {
+		t, err := parseSiteTemplates(siteTemplatesDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		siteTemplates = t
+	}
+
+	if outputFormat != "rss" && outputFormat != "meta-json" {
+		log.Fatalf("unknown -format %q", outputFormat)
+	}
+
+	if noAudio != "keep" && noAudio != "drop" {
+		log.Fatalf("unknown -no-audio mode %q", noAudio)
+	}
+
+	if seasons != "" && seasons != "year" && seasons != "title" {
+		log.Fatalf("unknown -seasons mode %q", seasons)
+	}
+
+	if reruns != "keep" && reruns != "drop" && reruns != "mark" {
+		log.Fatalf("unknown -reruns mode %q", reruns)
+	}
+
+	if guidMode != "url" && guidMode != "uuid" {
+		log.Fatalf("unknown -guid-mode %q", guidMode)
+	}
+
+	if titleTmpl != "" {
+		t, err := parseTitleTemplate(titleTmpl)
+		if err != nil {
+			log.Fatalf("bad -title-template: %v", err)
+		}
+		titleTemplate = t
+	}
+
+	if quality != "" && quality != "high" && quality != "low" {
+		log.Fatalf("unknown -quality %q", quality)
+	}
+
+	modes, err := parseStrict(strict)
+	if err != nil {
+		log.Fatal(err)
+	}
+	strictModes = modes
+
+	if appleCategory != "" {
+		if _, err := parseAppleCategory(appleCategory); err != nil {
+			log.Fatalf("bad -apple-category: %v", err)
+		}
+	}
+
+	if basicAuth != "" && !validBasicAuth(basicAuth) {
+		log.Fatal("-basic-auth must be user:password")
+	}
+
+	brands := brandsFromFlags()
+	if configFile != "" {
+		cfg, err := loadConfig(configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		brands = cfg.Brands
+		if cfg.Transport != nil {
+			transport = *cfg.Transport
+		}
+	}
+
+	if ip4 && ip6 {
+		log.Fatal("-ip4 and -ip6 can not be used together")
+	}
+
+	if bindAddr != "" {
+		ip, err := parseBind(bindAddr)
+		if err != nil {
+			log.Fatalf("-bind: %v", err)
+		}
+		bindIP = ip
+	}
+
+	if resolverAddr != "" {
+		r, err := parseResolver(resolverAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dnsResolver = r
+	}
+
+	fetchLimiter = newLimiter(rateLimit)
+	downloadLimiter = newBandwidthLimiter(limitRate)
+	httpClient = newClient(fetchTimeout, http2)
+	if cookieFile != "" {
+		jar, err := newFileJar(cookieFile)
+		if err != nil {
+			log.Fatalf("could not load cookies: %v", err)
+		}
+		httpClient.Jar = jar
+	}
+
+	if mirrorJobs < 1 {
+		log.Fatal("-mirror-jobs must be positive")
+	}
+	downloadSlots = make(chan struct{}, mirrorJobs)
+	if strings.HasPrefix(mirrorDir, "s3://") {
+		if _, err := newS3Store(mirrorDir, ""); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if mirrorKeep < 0 {
+		log.Fatal("-mirror-keep must not be negative")
+	}
+	if mirrorSince != "" {
+		t, err := time.ParseInLocation("2006-01-02", mirrorSince, moscow)
+		if err != nil {
+			log.Fatalf("bad -mirror-since date %q", mirrorSince)
+		}
+		mirrorAfter = t
+	}
+
+	if proxyMax < 1 {
+		log.Fatal("-proxy-max must be positive")
+	}
+
+	if (tlsCert == "") != (tlsKey == "") {
+		log.Fatal("-tls-cert and -tls-key only work together")
+	}
+
+	if subscribePage && baseURL == "" {
+		log.Fatal("-subscribe needs -base-url")
+	}
+	if baseURL != "" && !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	if mirrorURL != "" && !strings.HasSuffix(mirrorURL, "/") {
+		mirrorURL += "/"
+	}
+
+	if err := mkdirOutput(outputPath); err != nil {
+		log.Fatalf("could not create the output path: %v", err)
+	}
+
+	if warcFile != "" {
+		w, err := openWARC(warcFile)
+		if err != nil {
+			log.Fatalf("could not open the WARC file: %v", err)
+		}
+		defer w.Close()
+		pageArchive = w
+	}
+
+	if xslt == "default" {
+		writeFile([]byte(defaultXSLT), outputPath+defaultXSLTName)
+		xslt = defaultXSLTName
+	}
+
+	lock, err := lockOutput(outputPath, lockWait)
+	switch err {
+	case nil:
+		defer lock.Close()
+	case errLocked:
+		noticef("another run is writing to %q, exiting", outputPath)
+		return
+	default:
+		log.Fatalf("could not lock the output path: %v", err)
+	}
+
+	if alertURL != "" {
+		if alertAfter < 1 {
+			log.Fatal("-alert-after must be positive")
+		}
+		alerts = newAlerter(alertURL, alertAfter, alertsFile(outputPath))
+	}
+
+	if daemonMode {
+		runDaemon(brands)
+		return
+	}
+
+	start := time.Now()
+	metrics := runMetrics{episodes: make(map[string]int)}
+	push := func() {
+		if pushGateway == "" {
+			return
+		}
+		metrics.duration, metrics.finished = time.Since(start), time.Now()
+		if err := pushMetrics(pushGateway, metrics); err != nil {
+			log.Println(err)
+		}
+	}
+	defer func() {
+		if err := flushSpans(); err != nil {
+			log.Printf("could not export spans: %v", err)
+		}
+	}()
+
+	results, errs := generateAll(brands, jobs, generate)
+	if reportFile != "" {
+		var reports []*brandReport
+		for _, r := range results {
+			if r.report != nil {
+				reports = append(reports, r.report)
+			}
+		}
+		if err := writeReport(reportFile, reports); err != nil {
+			log.Printf("could not write report: %v", err)
+		}
+	}
+	var generated []result
+	for i, bc := range brands {
+		if alerts != nil {
+			alerts.record(bc, errs[i], latestEpisode(results[i].feed), time.Now())
+		}
+		if errs[i] != nil {
+			log.Printf("brand %s: %v", bc.Brand, errs[i])
+			metrics.failed = append(metrics.failed, bc.Brand)
+			continue
+		}
+		metrics.episodes[bc.Brand] = len(results[i].feed.Items)
+		generated = append(generated, results[i])
+	}
+	push()
+	if len(metrics.failed) != 0 {
+		_ = flushSpans()
+		log.Fatalf("could not generate %d of %d feeds", len(metrics.failed), len(brands))
+	}
+
+	if index {
+		writeFile(createIndex(generated), outputPath+"index.html")
+	}
+}
+
+// brandsFromFlags makes the brands configuration from the command line
+func brandsFromFlags() (brands []brandConfig) {
+	for _, brand := range strings.Split(programNumber, ",") {
+		brands = append(brands, brandConfig{
+			Brand:   strings.TrimSpace(brand),
+			Smotrim: smotrim,
+		})
+	}
+	return
+}
+
+// result is what was generated for a brand
+type result struct {
+	brand  string
+	file   string
+	feed   *brandFeed
+	meta   *feedMeta
+	report *brandReport
+}
+
+// generate creates the feed for the brand and writes it to the output file
+func generate(bc brandConfig) (r result, err error) {
+	brand := bc.Brand
+	rep := newBrandReport(brand)
+	ctx, sp := startSpan(withReport(fetchCtx, rep), "generate", spanInternal, "brand", brand)
+	defer func() {
+		sp.finish(err)
+		rep.finish(err)
+		r.report = rep
+	}()
+
+	feed, err := processBrand(ctx, brandURL(brand, bc.Smotrim))
+	if err != nil {
+		return
+	}
+	applyOverrides(feed, bc)
+	outputFile := feedFilename(outputPath, brand)
+	if outputFormat == "meta-json" {
+		outputFile = metaFilename(outputPath, brand)
 	}
 
-	feed := processURL(url)
+	if scheduleURL != "" {
+		addAirTimes(ctx, feed, brand)
+	}
+
+	if noAudio == "drop" {
+		feed.Items = withAudio(feed.Items)
+	}
+
+	if mergeParts {
+		feed.Items = mergeEpisodeParts(feed.Items)
+	}
+
+	var repeats map[*feedItem]bool
+	if reruns != "keep" {
+		repeats = findReruns(feed.Items)
+		if reruns == "drop" {
+			feed.Items = withoutReruns(feed.Items, repeats)
+		}
+	}
+
+	rawTitles := itemTitles(feed.Items)
+	if titlePrefix.on {
+		stripPrefixes(feed, titlePrefix.prefix)
+	}
+	if titleTemplate != nil {
+		applyTitleTemplate(feed, titleTemplate, rawTitles)
+	}
+	if reruns == "mark" {
+		markReruns(feed.Items, repeats)
+	}
+
+	if probeAudio {
+		probeEnclosures(ctx, feed.Items, audioCacheFile(outputPath))
+	}
+
+	if resolveAudio {
+		resolveEnclosures(ctx, feed.Items, resolveCacheFile(outputPath, brand))
+	}
+
+	if mirrorDir != "" {
+		if store, err := brandMirror(brand); err != nil {
+			warnf(ctx, "could not mirror the audio: %v", err)
+		} else {
+			rep.Mirrored = mirrorAudio(ctx, feed.Items, store)
+		}
+	}
+
+	if file := guidsFile(outputPath, brand); pinsGUIDs(file) {
+		assignGUIDs(ctx, feed.Items, file, feedFilename(outputPath, brand))
+	}
+
+	rep.Output, rep.Episodes, rep.NewEpisodes = outputFile, len(feed.Items), len(feed.Items)
+	rep.newTitles = itemTitles(feed.Items)
+	if old, err := readFeed(outputFile); err == nil {
+		restoreDescriptions(feed, old)
+		fresh := newItems(feed, old)
+		rep.NewEpisodes, rep.newTitles = len(fresh), itemTitles(fresh)
+	}
+
+	if strictModes["extract"] {
+		if gaps := extractionGaps(feed); len(gaps) != 0 {
+			for _, g := range gaps {
+				log.Println(g)
+			}
+			return r, fmt.Errorf("%d extraction gaps, not writing %s", len(gaps), outputFile)
+		}
+	}
+
+	if trackUpdates {
+		markUpdated(feed.Items, itemsStateFile(outputPath, brand), time.Now())
+	}
+
+	if pageLink {
+		addPageLinks(feed.Items)
+	}
 
 	feed.Created = time.Now()
-	output := createFeed(feed)
-	outputFile := outputPath + "radiorus-" + programNumber + ".rss"
 
-	writeFile(output, outputFile)
+	if outputFormat == "meta-json" {
+		output, err := feedMetaJSON(brand, feed)
+		if err != nil {
+			return r, err
+		}
+		if err := fetchCtx.Err(); err != nil {
+			return r, fmt.Errorf("not writing %s: %w", outputFile, err)
+		}
+		if err := writeOutput(outputFile, output); err != nil {
+			return r, err
+		}
+		if err := publishAll(ctx, filepath.Base(outputFile), output); err != nil {
+			return r, err
+		}
+		return result{brand: brand, file: outputFile, feed: feed, meta: newFeedMeta(brand, feed)}, nil
+	}
+
+	exts, err := feedExtensions(feed, bc)
+	if err != nil {
+		return r, err
+	}
+	output := createFeed(feed, exts...)
+	if xslt != "" {
+		output = addStylesheet(output, xslt)
+	}
+
+	if err := fetchCtx.Err(); err != nil {
+		return r, fmt.Errorf("not writing %s: %w", outputFile, err)
+	}
+
+	if strictModes["apple"] {
+		problems := append(validateApple(output, false), artworkProblems(feed, outputFile)...)
+		if len(problems) != 0 {
+			for _, p := range problems {
+				log.Println(p)
+			}
+			return r, fmt.Errorf("feed would be rejected by Apple Podcasts, not writing %s", outputFile)
+		}
+	}
+
+	if err := writeOutput(outputFile, output); err != nil {
+		return r, err
+	}
+	if err := publishAll(ctx, filepath.Base(outputFile), output); err != nil {
+		return r, err
+	}
+	if gzipped {
+		gz := gzipBytes(output)
+		if err := writeOutput(outputFile+".gz", gz); err != nil {
+			return r, err
+		}
+		if err := publishAll(ctx, filepath.Base(outputFile)+".gz", gz); err != nil {
+			return r, err
+		}
+	}
+	if siteTemplates != nil {
+		if err := writeSite(siteTemplates, feed, siteDir, brand, outputFile); err != nil {
+			return r, fmt.Errorf("could not render the site: %w", err)
+		}
+	}
+	if subscribePage {
+		page, err := createSubscribePage(feed, feedURL(feedFilename(outputPath, brand)))
+		if err != nil {
+			return r, fmt.Errorf("could not create the subscribe page: %w", err)
+		}
+		name := subscribeFilename(outputPath, brand)
+		if err := writeOutput(name, page); err != nil {
+			return r, err
+		}
+		if err := publishAll(ctx, filepath.Base(name), page); err != nil {
+			return r, err
+		}
+	}
+	return result{brand: brand, file: outputFile, feed: feed, meta: newFeedMeta(brand, feed)}, nil
+}
+
+// brandURL returns the URL of the brand's episode listing
+func brandURL(brand string, smotrim bool) string {
+	if smotrim {
+		return "https://smotrim.ru/brand/" + brand
+	}
+	return "https://www.radiorus.ru/brand/" + brand + "/episodes"
+}
+
+func processURL(url string) *brandFeed {
+	feed, err := processBrand(fetchCtx, url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return feed
 }
 
-func processURL(url string) *feeds.Feed {
-	feed := getFeed(url)
+// processBrand gets the feed from the brand page and describes it
+// along with its episodes
+func processBrand(ctx context.Context, url string) (feed *brandFeed, err error) {
+	ctx, sp := startSpan(ctx, "processURL", spanInternal, "url", url)
+	defer func() { sp.finish(err) }()
+
+	feed, err = fetchFeed(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	sp.setAttr("episodes", strconv.Itoa(len(feed.Items)))
 
 	var wg sync.WaitGroup
-	if feed.Description == "" {
+	if feed.Description == "" || (feed.Author == nil && parseSite(feed) != "smotrim.ru") {
 		wg.Add(1)
-		go describeFeed(feed, &wg)
+		go describeFeed(ctx, feed, &wg)
 	}
-	describeEpisodes(feed)
+	describeEpisodes(ctx, feed)
 	wg.Wait()
+	normalizeFeed(feed)
+	if typography {
+		typographFeed(feed)
+	}
 
-	return feed
+	return feed, nil
 }
 
-func createFeed(feed *feeds.Feed) []byte {
-	rss, err := feed.ToRss()
+// createFeed renders the RSS document, indented with xmlIndent
+// or compact if it is empty
+func createFeed(feed *brandFeed, exts ...extension) []byte {
+	x := newRssDoc(feed)
+	for _, ext := range exts {
+		ext(x)
+	}
+	var (
+		rss []byte
+		err error
+	)
+	if xmlIndent == "" {
+		rss, err = xml.Marshal(x)
+	} else {
+		rss, err = xml.MarshalIndent(x, "", xmlIndent)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
-	return []byte(rss)
+	return append([]byte(xml.Header[:len(xml.Header)-1]), rss...)
 }
 
+// writeFile writes the file or exits, it is only for the one-off runs:
+// whatever runs in daemon mode uses writeOutput and handles the error
 func writeFile(output []byte, filename string) {
-	if err := ioutil.WriteFile(filename, output, 0644); err != nil {
+	if err := writeOutput(filename, output); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func getFeed(url string) (feed *feeds.Feed) {
-	page, url := getPage(url)
-	feed = &feeds.Feed{
-		Link: &feeds.Link{Href: url},
+// writeFileAtomic writes to a temporary file and renames it, so that
+// the file is never left half-written
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
 	}
+	defer os.Remove(f.Name())
 
-	if err := populateFeed(feed, page); err != nil {
-		err = fmt.Errorf("could not process %v: %w", url, err)
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// gzipBytes compresses the data for static hosting
+func gzipBytes(b []byte) []byte {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
 		log.Fatal(err)
 	}
+	if _, err := zw.Write(b); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// commonExtensions returns the extensions shared by all the feeds of
+// the brand, the season and archive ones included
+func commonExtensions(bc brandConfig) (common []extension) {
+	if lang := feedLanguage(bc); lang != "" {
+		common = append(common, withLanguage(lang))
+	}
+	if category := feedAppleCategory(bc); category != "" {
+		common = append(common, withItunes(category, bc.Explicit))
+	}
+	return
+}
 
+// documentExtensions returns the extensions of the brand's main feed
+// that only shape the document and write nothing
+func documentExtensions(bc brandConfig) []extension {
+	exts := append([]extension{withPodcastGUID(bc.Brand)}, commonExtensions(bc)...)
+	if f := bc.Funding; f != nil {
+		exts = append(exts, withFunding(*f))
+	} else if defaultFunding.URL != "" {
+		exts = append(exts, withFunding(defaultFunding))
+	}
+	return exts
+}
+
+// feedExtensions writes the season and archive feeds of the brand if
+// those are enabled, leaving only the latest items in the feed, and
+// returns the extensions of the main feed
+func feedExtensions(feed *brandFeed, bc brandConfig) ([]extension, error) {
+	common := commonExtensions(bc)
+	exts := documentExtensions(bc)
+	if seasons != "" {
+		if err := writeSeasons(feed, outputPath, bc.Brand, seasons, common...); err != nil {
+			return nil, err
+		}
+	}
+	if latest > 0 {
+		archives, err := writeArchives(feed, outputPath, bc.Brand, common...)
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, archives...)
+		if len(feed.Items) > latest {
+			feed.Items = feed.Items[:latest]
+		}
+	}
+	return exts, nil
+}
+
+// feedFilename returns the name of the RSS file for the brand
+func feedFilename(path, brand string) string {
+	return path + "radiorus-" + brand + ".rss"
+}
+
+// readFeed reads a previously generated RSS file
+func readFeed(filename string) (*feeds.RssFeed, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseFeed(b)
+}
+
+// parseFeed parses an RSS document
+func parseFeed(b []byte) (*feeds.RssFeed, error) {
+	var rss feeds.RssFeedXml
+	if err := xml.Unmarshal(b, &rss); err != nil {
+		return nil, err
+	}
+	if rss.Channel == nil {
+		return nil, fmt.Errorf("no channel found")
+	}
+	return rss.Channel, nil
+}
+
+// restoreDescriptions fills in the descriptions that could not be fetched
+// with the ones from the previous version of the feed
+func restoreDescriptions(feed *brandFeed, old *feeds.RssFeed) {
+	if feed.Description == "" {
+		feed.Description = old.Description
+	}
+
+	descs := make(map[string]string, len(old.Items))
+	for _, item := range old.Items {
+		descs[item.Guid] = item.Description
+	}
+	for _, item := range feed.Items {
+		if item.Description == "" {
+			item.Description = descs[item.Id]
+		}
+	}
+}
+
+func getFeed(url string) *brandFeed {
+	feed, err := fetchFeed(fetchCtx, url)
+	if err != nil {
+		log.Fatal(err)
+	}
 	return feed
 }
 
-func populateFeed(feed *feeds.Feed, page []byte) (err error) {
-	feed.Title, err = parseText(page, ".brand-main-item__title")
+func fetchFeed(ctx context.Context, url string) (*brandFeed, error) {
+	page, url, err := fetchPage(ctx, url)
+	if err != nil && err != errServer {
+		return nil, err
+	}
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: url},
+	}}
+
+	layout, err := parseProgrammePage(feed, page)
+	if err != nil {
+		return nil, fmt.Errorf("could not process %v: %w", url, err)
+	}
+	reportFrom(ctx).setLayout(layout)
+
+	return feed, nil
+}
+
+func populateFeed(feed *brandFeed, page []byte) error {
+	_, err := parseProgrammePage(feed, page)
+	return err
+}
+
+// parseProgrammePage populates the feed from the programme page,
+// returning the name of the page layout recognised
+func parseProgrammePage(feed *brandFeed, page []byte) (layout string, err error) {
+	doc, err := newDocument(page)
+	if err != nil {
+		return "", fmt.Errorf("bad programme page: %w", err)
+	}
+
+	feed.Title = docText(doc, ".brand-main-item__title")
 	if feed.Title == "" {
 		feed.Title, err = parseProgrammeTitle(page)
 	}
 
 	if err != nil {
-		return fmt.Errorf("bad programme page: title not found")
+		return "", fmt.Errorf("bad programme page: title not found")
 	}
 
-	feed.Description, _ = parseText(page, ".program-about__text")
-
-	addFeedImage(page, feed)
+	feed.Description = docText(doc, ".program-about__text")
+	feed.Author = presenters(doc)
 
-	switch site := parseSite(feed); site {
-	case "smotrim.ru":
-		err = populateSmotrimEpisodes(feed, page)
-	default:
-		episodes := findEpisodes(page)
-		urlPrefix := episodeURLPrefix(feed.Link.Href)
+	addFeedImage(doc, page, feed)
 
-		for _, episode := range episodes {
-			if len(episodeUrlRe.FindAllSubmatch(episode, -1)) > 1 {
-				return errBadEpisode
-			}
-			url, err := parseSingle(episode, episodeUrlRe)
-			if err != nil {
-				return errBadEpisode
-			}
-			episodeUrl := urlPrefix + string(url)
-			title, _ := parseSingle(episode, episodeTitleRe)
-			episodeTitle := string(title)
-			enclosure := findEnclosure(episode)
-			date := findDate(episode)
+	return populateEpisodes(feed, doc, page)
+}
 
-			feed.Add(&feeds.Item{
-				Id:        episodeID(episodeUrl),
-				Link:      &feeds.Link{Href: episodeUrl},
-				Title:     episodeTitle,
-				Enclosure: enclosure,
-				Created:   date,
-			})
+// radiorusEpisodes extracts the episodes from the listing entries
+func radiorusEpisodes(doc *goquery.Document, urlPrefix string) (items []*feedItem, err error) {
+	doc.Find(".brand__list--wrap--item").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var item *feedItem
+		item, err = radiorusEpisode(s, urlPrefix)
+		if err != nil {
+			return false
+		}
+		if item != nil {
+			items = append(items, item)
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
-	return
+	// episode links outside of the entries mean the markup is not
+	// what it seems
+	if doc.Find("a.title.brand-menu-link").Length() != len(items) {
+		return nil, errBadEpisode
+	}
+	return items, nil
 }
 
-func populateSmotrimEpisodes(feed *feeds.Feed, page []byte) (err error) {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
-	if err != nil {
-		return
+// radiorusEpisode extracts the episode from its radiorus.ru listing
+// entry, it returns nil item for the entries that are not episodes
+// (e.g. ads)
+func radiorusEpisode(s *goquery.Selection, urlPrefix string) (*feedItem, error) {
+	title := s.Find("a.title.brand-menu-link")
+	audio := s.Find(`[data-type="audio"]`)
+	switch {
+	case title.Length() == 0 && audio.Length() == 0:
+		return nil, nil
+	case title.Length() != 1:
+		return nil, errBadEpisode
+	}
+	href, _ := title.Attr("href")
+	if !strings.HasPrefix(href, "/brand/") {
+		return nil, errBadEpisode
+	}
+	episodeUrl := urlPrefix + strings.TrimPrefix(href, "/brand/")
+
+	id, _ := audio.Attr("data-id")
+	enc := enclosure(id)
+
+	item := &feedItem{Item: &feeds.Item{
+		Id:        episodeID(episodeUrl),
+		Link:      &feeds.Link{Href: episodeUrl},
+		Title:     strings.TrimSpace(title.Text()),
+		Enclosure: enc,
+		Created:   parseDate(episodeDayRe.FindSubmatch([]byte(s.Find("a.brand-time").Text()))),
+	}}
+	if enc != nil {
+		item.audioID = id
+	}
+	if img, ok := s.Find(".photo-wrap img").First().Attr("src"); ok {
+		item.image = strings.TrimSpace(img)
+	}
+	return item, nil
+}
+
+// radiorusEpisodesLegacy scans the page with regular expressions,
+// the way it was done before the markup was parsed
+func radiorusEpisodesLegacy(urlPrefix string, page []byte) (items []*feedItem, err error) {
+	episodes := findEpisodes(page)
+
+	for _, episode := range episodes {
+		if len(episodeUrlRe.FindAllSubmatch(episode, -1)) > 1 {
+			return nil, errBadEpisode
+		}
+		url, err := parseSingle(episode, episodeUrlRe)
+		if err != nil {
+			return nil, errBadEpisode
+		}
+		episodeUrl := urlPrefix + string(url)
+		title, _ := parseSingle(episode, episodeTitleRe)
+		episodeTitle := string(title)
+		id := findAudioID(episode)
+		date := findDate(episode)
+
+		item := &feedItem{Item: &feeds.Item{
+			Id:        episodeID(episodeUrl),
+			Link:      &feeds.Link{Href: episodeUrl},
+			Title:     episodeTitle,
+			Enclosure: enclosure(id),
+			Created:   date,
+		}}
+		if id != "" {
+			item.audioID = id
+		}
+		items = append(items, item)
 	}
-	base, err := url.Parse(feed.Link.Href)
+	return items, nil
+}
+
+// smotrimEpisodes extracts the episodes from a smotrim.ru programme page
+func smotrimEpisodes(pageURL string, doc *goquery.Document) (items []*feedItem, err error) {
+	base, err := url.Parse(pageURL)
 	if err != nil {
 		return
 	}
@@ -185,22 +1026,31 @@ func populateSmotrimEpisodes(feed *feeds.Feed, page []byte) (err error) {
 			return
 		}
 		title := strings.TrimSpace(strings.TrimPrefix(s.Find(".episode-card__title").Text(), s.Find(".episode-card__title__brand").Text()))
-		feed.Add(&feeds.Item{
+		item := &feedItem{Item: &feeds.Item{
 			Id:        id,
 			Link:      &feeds.Link{Href: link.String()},
 			Title:     title,
 			Enclosure: enclosure(id),
-		})
+		}}
+		if id != "" {
+			item.audioID = id
+		}
+		items = append(items, item)
 	})
 	return
 }
 
-func parseSite(feed *feeds.Feed) string {
-	u, err := url.Parse(feed.Link.Href)
+func parseSite(feed *brandFeed) string {
+	return siteOf(feed.Link.Href)
+}
+
+// siteOf returns the host of the URL without the www. prefix
+func siteOf(link string) string {
+	u, err := url.Parse(link)
 	if err != nil {
 		return ""
 	}
-	return u.Hostname()
+	return strings.TrimPrefix(u.Hostname(), "www.")
 }
 
 func parseProgrammeTitle(page []byte) (title string, err error) {
@@ -212,20 +1062,20 @@ func parseProgrammeTitle(page []byte) (title string, err error) {
 	return
 }
 
-func parseText(page []byte, sel string) (title string, err error) {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
-	if err != nil {
-		return
-	}
-	title = strings.TrimSpace(doc.Find(sel).Text())
-	return
+// newDocument parses the page, every page is to be parsed once
+// and the document shared by all the extractors
+func newDocument(page []byte) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(bytes.NewReader(page))
 }
 
-func addFeedImage(page []byte, feed *feeds.Feed) {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
-	if err != nil {
-		return
-	}
+// docText returns the trimmed text of the elements matching the selector
+func docText(doc *goquery.Document, sel string) string {
+	return strings.TrimSpace(doc.Find(sel).Text())
+}
+
+// addFeedImage looks for the programme image in the document, falling
+// back to scanning the raw page
+func addFeedImage(doc *goquery.Document, page []byte, feed *brandFeed) {
 	img := doc.Find(".brand-main-item__picture").Find("img")
 	if src, ok := img.Attr("src"); ok {
 		t, _ := img.Attr("title")
@@ -266,7 +1116,6 @@ func parseSingle(src []byte, re *regexp.Regexp) (out []byte, err error) {
 }
 
 func findDate(ep []byte) time.Time {
-	episodeDateRe := regexp.MustCompile(`brand\-time brand\-menu\-link">(.+?)?\.(.+?)?\.(.+?)? в (.+?)?:(.+?)?</a>`)
 	dateBytes := episodeDateRe.FindSubmatch(ep)
 	return parseDate(dateBytes)
 }
@@ -287,18 +1136,23 @@ func parseDate(bytes [][]byte) time.Time {
 	return time.Date(date[2], time.Month(date[1]), date[0], date[3], date[4], 0, 0, moscow)
 }
 
-func findEnclosure(ep []byte) *feeds.Enclosure {
-	re := regexp.MustCompile(`data\-type="audio"\s+data\-id="(.+?)?">`)
-
-	res, err := parseSingle(ep, re)
+// findAudioID returns the ID of the episode's audio, empty if there's
+// no audio
+func findAudioID(ep []byte) string {
+	res, err := parseSingle(ep, enclosureRe)
 	if err != nil {
-		return &feeds.Enclosure{}
+		return ""
 	}
 
-	return enclosure(string(res))
+	return string(res)
 }
 
+// enclosure returns the enclosure for the audio ID, nil if there's
+// no audio
 func enclosure(no string) *feeds.Enclosure {
+	if no == "" {
+		return nil
+	}
 
 	url := "https://audio.vgtrk.com/download?id=" + string(no)
 
@@ -310,18 +1164,28 @@ func enclosure(no string) *feeds.Enclosure {
 }
 
 func findEpisodes(page []byte) [][]byte {
-	episodeRe := regexp.MustCompile(`(?s)<div class="brand__list\-\-wrap\-\-item">(.+?)?data-id="(.+?)"></div>`)
-	episodes := episodeRe.FindAll(page, -1)
-	return episodes
+	return episodeRe.FindAll(page, -1)
 }
 
-func describeFeed(feed *feeds.Feed, wg *sync.WaitGroup) {
+func describeFeed(ctx context.Context, feed *brandFeed, wg *sync.WaitGroup) {
 	defer wg.Done()
 	url := strings.TrimSuffix(feed.Link.Href, "episodes") + "about"
-	page, _ := getPage(url)
+	page, _, err := fetchPage(ctx, url)
+	if err != nil && err != errServer {
+		warnf(ctx, "could not fetch programme page %v: %v", url, err)
+		return
+	}
+	if feed.Author == nil {
+		if doc, err := newDocument(page); err == nil {
+			feed.Author = presenters(doc)
+		}
+	}
+	if feed.Description != "" {
+		return
+	}
 	desc, err := processFeedDesc(page)
 	if err != nil {
-		log.Printf("could not find programme description on page %v: %v", url, err)
+		warnf(ctx, "could not find programme description on page %v: %v", url, err)
 	}
 	feed.Description = desc
 }
@@ -331,39 +1195,102 @@ func processFeedDesc(page []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	re := regexp.MustCompile(`<(.+?)?>`)
-	return string(re.ReplaceAll(res, []byte(``))), err
+	return string(tagRe.ReplaceAll(res, []byte(``))), err
 }
 
-func describeEpisodes(feed *feeds.Feed) {
-	var wg sync.WaitGroup
-	for _, item := range feed.Items {
+func describeEpisodes(ctx context.Context, feed *brandFeed) {
+	items, errs := describeItems(ctx, feed.Items)
+	for i := 0; i < retries && len(items) != 0; i++ {
+		if err := sleep(ctx, retryDelay<<uint(i)); err != nil {
+			break
+		}
+		items, errs = describeItems(ctx, items)
+	}
+	for i, item := range items {
+		warnf(ctx, "could not fetch episode page %v: %v", item.Link.Href, errs[i])
+	}
+	atomic.AddInt32(&episodeErrors, int32(len(items)))
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// describeItems describes items concurrently, returns the items
+// that could not be fetched along with the corresponding errors
+func describeItems(ctx context.Context, items []*feedItem) (failed []*feedItem, errs []error) {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, item := range items {
 		wg.Add(1)
-		go describeEpisode(item, &wg)
+		go func(item *feedItem) {
+			defer wg.Done()
+			if err := describeEpisode(ctx, item); err != nil {
+				mu.Lock()
+				failed = append(failed, item)
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(item)
 	}
 	wg.Wait()
+	return
 }
 
-func describeEpisode(item *feeds.Item, wg *sync.WaitGroup) {
-	defer wg.Done()
-	page, _ := getPage(item.Link.Href)
-	desc, err := processEpisodeDesc(page)
+func describeEpisode(ctx context.Context, item *feedItem) (err error) {
+	ctx, sp := startSpan(ctx, "describeEpisode", spanInternal, "url", item.Link.Href)
+	defer func() { sp.finish(err) }()
+
+	page, u, err := fetchPage(ctx, item.Link.Href)
+	if errors.Is(err, errDisallowed) {
+		warnf(ctx, "%v", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	doc, err := newDocument(page)
+	if err != nil {
+		warnf(ctx, "could not parse episode page %v: %v", item.Link.Href, err)
+		return nil
+	}
+	// radiorus.ru episodes may redirect to smotrim.ru, so the page is
+	// parsed according to where it ended up rather than to the feed
+	site := siteOf(u)
+	desc, err := processEpisodeDesc(doc, site)
 	if err != nil {
-		log.Printf("could not find episode description on page %v: %v", item.Link.Href, err)
+		warnf(ctx, "could not find episode description on page %v: %v", u, err)
 	}
 	item.Description = desc
-	if item.Created.IsZero() {
-		item.Created = parseSmotrimDate(page)
+	if item.Created.IsZero() && site == "smotrim.ru" {
+		item.Created = parseSmotrimDate(doc)
 	}
+	item.categories = episodeTags(doc, site)
+	if quality != "" {
+		if id, ok := audioVariants(doc)[quality]; ok {
+			item.Enclosure = enclosure(id)
+			item.audioID = id
+		}
+	}
+	return nil
 }
 
-func parseSmotrimDate(page []byte) (t time.Time) {
-	s, err := parseText(page, ".video__date")
-	if err != nil {
+func parseSmotrimDate(doc *goquery.Document) (t time.Time) {
+	s := docText(doc, ".video__date")
+	if s == "" {
 		return
 	}
-	mnths := [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"}
-	for i, mnt := range mnths {
+	for i, mnt := range months {
 		s = strings.ReplaceAll(s, mnt, strconv.Itoa(i+1))
 	}
 	s = fmt.Sprintf("%s z+03", s)
@@ -371,21 +1298,23 @@ func parseSmotrimDate(page []byte) (t time.Time) {
 	return
 }
 
-func processEpisodeDesc(page []byte) (string, error) {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
-	if err != nil {
-		return "", err
-	}
+// processEpisodeDesc extracts the episode description from the page
+// using the markup of the site the page is from
+func processEpisodeDesc(doc *goquery.Document, site string) (string, error) {
 	var r []string
-	r = addText(r, doc.Find(".brand-episode__head").Find(".anons").Text())
-	r = addText(r, doc.Find(".brand-episode__body").Find(".body").Text())
-	r = addText(r, strings.TrimSpace(doc.Find(".video__body").Text()))
+	switch site {
+	case "smotrim.ru":
+		r = addText(r, strings.TrimSpace(doc.Find(".video__body").Text()))
+	default:
+		r = addText(r, doc.Find(".brand-episode__head").Find(".anons").Text())
+		r = addText(r, doc.Find(".brand-episode__body").Find(".body").Text())
+	}
 
 	res := strings.Join(r, "\n\n")
 	if res == "" {
 		return "", errCantParse
 	}
-	return res, err
+	return res, nil
 }
 
 func addText(arr []string, str string) []string {
@@ -396,34 +1325,70 @@ func addText(arr []string, str string) []string {
 }
 
 func getPage(pageUrl string) ([]byte, string) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", pageUrl, nil)
-	if err != nil {
+	page, u, err := fetchPage(fetchCtx, pageUrl)
+	if err != nil && err != errServer {
 		log.Fatal(err)
 	}
-	req.Header.Add("User-Agent", `Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.27 Safari/537.36`)
-	res, err := client.Do(req)
+	return page, u
+}
+
+// fetchPage retrieves the page, server-side (5xx) responses are returned
+// along with errServer since they are usually worth retrying
+func fetchPage(ctx context.Context, pageUrl string) (page []byte, u string, err error) {
+	ctx, sp := startSpan(ctx, "getPage", spanClient, "http.url", pageUrl)
+	defer func() { sp.finish(err) }()
+
+	if err := fetchLimiter.wait(ctx); err != nil {
+		return nil, pageUrl, err
+	}
+	if err := politeWait(ctx, pageUrl); err != nil {
+		return nil, pageUrl, err
+	}
+
+	req, err := newRequest(ctx, "GET", pageUrl)
 	if err != nil {
-		log.Fatal(err)
+		return nil, pageUrl, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, pageUrl, err
 	}
 	defer res.Body.Close()
-	page, err := ioutil.ReadAll(res.Body)
+	sp.setAttr("http.status_code", strconv.Itoa(res.StatusCode))
+	page, err = ioutil.ReadAll(res.Body)
 	if err != nil {
-		log.Fatal(err)
+		return nil, pageUrl, err
+	}
+	markProgress()
+	reportFrom(ctx).addPage()
+	if pageArchive != nil {
+		if err := pageArchive.archive(res, page); err != nil {
+			warnf(ctx, "could not archive %s: %v", res.Request.URL, err)
+		}
 	}
 
 	page = cleanText(page)
 
-	return page, res.Request.URL.String()
+	if res.StatusCode >= 500 {
+		err = errServer
+	}
+	return page, res.Request.URL.String(), err
 }
 
 // cleanText replaces HTML-encoded symbols with proper UTF
 func cleanText(b []byte) []byte {
-	for _, sub := range substitutes {
-		re := regexp.MustCompile(sub.from)
-		b = re.ReplaceAll(b, []byte(sub.to))
+	return []byte(substitutes.Replace(string(b)))
+}
+
+// withAudio returns the items that have audio
+func withAudio(items []*feedItem) []*feedItem {
+	var kept []*feedItem
+	for _, item := range items {
+		if item.Enclosure != nil && item.Enclosure.Url != "" {
+			kept = append(kept, item)
+		}
 	}
-	return b
+	return kept
 }
 
 // episodeURLPrefix derives common episode URL prefix from programme page URL
@@ -442,6 +1407,5 @@ func episodeID(url string) string {
 
 // stripLink strips string of <a> tags
 func stripLink(s string) string {
-	re := regexp.MustCompile(`</?a.*?>`)
-	return re.ReplaceAllString(s, "")
+	return linkTagRe.ReplaceAllString(s, "")
 }
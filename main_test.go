@@ -17,19 +17,23 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
@@ -76,9 +80,9 @@ func assertGolden(t *testing.T, actual []byte, golden string) {
 func TestFeed(t *testing.T) {
 	var page []byte
 
-	feed := &feeds.Feed{
+	feed := &brandFeed{Feed: feeds.Feed{
 		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
-	}
+	}}
 
 	err := populateFeed(feed, page)
 	assertStringContains(t, fmt.Sprint(err), "bad programme")
@@ -99,10 +103,93 @@ func TestFeed(t *testing.T) {
 	assertGolden(t, actual, golden)
 }
 
+func TestCompactFeed(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "https://smotrim.ru/brand/57083"},
+	}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "smotrim.57083"))); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(i string) { xmlIndent = i }(xmlIndent)
+	xmlIndent = ""
+	compact := createFeed(feed)
+	if bytes.Contains(compact, []byte("\n  <")) {
+		t.Error("compact output is indented")
+	}
+
+	xmlIndent = "\t"
+	indented := createFeed(feed)
+	if !bytes.Contains(indented, []byte("\n\t<channel>")) {
+		t.Error("output not indented with tabs")
+	}
+
+	c, _ := parseFeed(compact)
+	i, _ := parseFeed(indented)
+	if c == nil || i == nil || len(c.Items) != len(i.Items) || c.Title != i.Title {
+		t.Error("compact and indented feeds differ")
+	}
+}
+
+func TestGzipBytes(t *testing.T) {
+	page := helperLoadBytes(t, "TestServedFeed.golden")
+	compressed := gzipBytes(page)
+	if len(compressed) >= len(page) {
+		t.Errorf("%d bytes compressed to %d", len(page), len(compressed))
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, page) {
+		t.Fatal("decompressed data differs")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "feed.rss")
+	for _, data := range []string{"old contents", "new"} {
+		if err := writeFileAtomic(file, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(helperReadFile(t, file)); got != data {
+			t.Errorf("want %q, got %q", data, got)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("want only the feed in the directory, got %d files", len(files))
+	}
+}
+
+func TestFetchCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := fetchPage(ctx, server.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
+
 func TestBadEpisode(t *testing.T) {
-	feed := &feeds.Feed{
+	feed := &brandFeed{Feed: feeds.Feed{
 		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
-	}
+	}}
 
 	for i := 0; i <= 1; i++ {
 		page := helperLoadBytes(t, "episodes.badep."+strconv.Itoa(i))
@@ -115,9 +202,9 @@ func TestBadEpisode(t *testing.T) {
 }
 
 func TestNoImage(t *testing.T) {
-	feed := &feeds.Feed{
+	feed := &brandFeed{Feed: feeds.Feed{
 		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
-	}
+	}}
 
 	page := helperLoadBytes(t, "episodes.noimg")
 	page = cleanText(page)
@@ -147,12 +234,170 @@ func TestFindEpisodes(t *testing.T) {
 	}
 }
 
+func TestRadiorusEpisode(t *testing.T) {
+	const prefix = "https://www.radiorus.ru/brand/"
+	testdata := []struct {
+		name, html string
+		want       *feedItem
+		err        error
+	}{
+		{
+			name: "episode",
+			html: `<a href="/brand/57083/episode/2223937" class="brand-time brand-menu-link">01.12.2019 в 14:10</a>
+<a href="/brand/57083/episode/2223937" class="title brand-menu-link">То да сё № 5</a>
+<div class="audio-count" data-type="audio" data-id="2456411"></div>`,
+			want: &feedItem{Item: &feeds.Item{
+				Id:        "http://www.radiorus.ru/brand/57083/episode/2223937",
+				Link:      &feeds.Link{Href: "https://www.radiorus.ru/brand/57083/episode/2223937"},
+				Title:     "То да сё № 5",
+				Enclosure: enclosure("2456411"),
+				Created:   time.Date(2019, time.December, 1, 14, 10, 0, 0, moscow),
+			}, audioID: "2456411"},
+		},
+		{
+			name: "no audio, no date",
+			html: `<a class="title brand-menu-link" href="/brand/57083/episode/1">  Title  </a>`,
+			want: &feedItem{Item: &feeds.Item{
+				Id:      "http://www.radiorus.ru/brand/57083/episode/1",
+				Link:    &feeds.Link{Href: "https://www.radiorus.ru/brand/57083/episode/1"},
+				Title:   "Title",
+				Created: time.Date(1970, time.January, 1, 0, 0, 0, 0, moscow),
+			}},
+		},
+		{
+			name: "ad",
+			html: `<div class="adv">Реклама</div>`,
+		},
+		{
+			name: "audio without title",
+			html: `<div class="audio-count" data-type="audio" data-id="2456411"></div>`,
+			err:  errBadEpisode,
+		},
+		{
+			name: "two titles",
+			html: `<a href="/brand/57083/episode/1" class="title brand-menu-link">One</a><a href="/brand/57083/episode/2" class="title brand-menu-link">Two</a>`,
+			err:  errBadEpisode,
+		},
+		{
+			name: "foreign link",
+			html: `<a href="https://example.com/1" class="title brand-menu-link">One</a>`,
+			err:  errBadEpisode,
+		},
+	}
+
+	for _, tc := range testdata {
+		doc, err := newDocument([]byte(`<div class="brand__list--wrap--item">` + tc.html + `</div>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := radiorusEpisode(doc.Find(".brand__list--wrap--item"), prefix)
+		if err != tc.err {
+			t.Errorf("%s: want error %v, got %v", tc.name, tc.err, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: want %+v, got %+v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFeedExtensions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p, l string, n int, f funding) { outputPath, language, latest, defaultFunding = p, l, n, f }(outputPath, language, latest, defaultFunding)
+	outputPath, language, latest, defaultFunding = dir+"/", "ru", 3, funding{URL: "https://example.com/donate"}
+
+	feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"}}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "episodes"))); err != nil {
+		t.Fatal(err)
+	}
+
+	exts, err := feedExtensions(feed, brandConfig{Brand: "57083", Language: "tt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := string(createFeed(feed, exts...))
+	for _, want := range []string{
+		`<language>tt</language>`,
+		`<podcast:guid>` + podcastGUID("57083") + `</podcast:guid>`,
+		`<podcast:funding url="https://example.com/donate"></podcast:funding>`,
+		`<atom:link rel="prev-archive" href="radiorus-57083-2020.rss"`,
+	} {
+		assertStringContains(t, x, want)
+	}
+	if len(feed.Items) != 3 {
+		t.Errorf("want the latest 3 items left, got %d", len(feed.Items))
+	}
+	helperAssertItems(t, archiveFilename(outputPath, "57083", 2019), 6)
+
+	// a failed write is the brand's error, not the end of the program
+	outputPath = filepath.Join(dir, "missing") + "/"
+	if _, err := feedExtensions(feed, brandConfig{Brand: "57083"}); err == nil {
+		t.Error("want the error writing the archives")
+	}
+}
+
+func TestNoAudio(t *testing.T) {
+	items := []*feedItem{
+		{Item: &feeds.Item{Id: "1", Enclosure: enclosure("2456411")}},
+		{Item: &feeds.Item{Id: "2", Enclosure: enclosure("")}},
+		{Item: &feeds.Item{Id: "3", Enclosure: &feeds.Enclosure{Url: "", Length: "1024", Type: "audio/mpeg"}}},
+		{Item: &feeds.Item{Id: "4", Enclosure: enclosure(findAudioID([]byte(`<div class="audio-count"></div>`)))}},
+	}
+	if got := withAudio(items); len(got) != 1 || got[0].Id != "1" {
+		t.Errorf("want only item 1 with audio, got %d items", len(got))
+	}
+
+	for _, item := range items {
+		item.Link = &feeds.Link{Href: "https://smotrim.ru/audio/" + item.Id}
+	}
+	feed := &brandFeed{Feed: feeds.Feed{Title: "test", Link: &feeds.Link{Href: "https://smotrim.ru/brand/57083"}}, Items: items}
+	out := createFeed(feed)
+	if n := bytes.Count(out, []byte("<enclosure")); n != 1 {
+		t.Errorf("want 1 enclosure, got %d", n)
+	}
+	if bytes.Contains(out, []byte(`url=""`)) {
+		t.Error("enclosure with empty URL")
+	}
+}
+
+func TestRadiorusLegacyParity(t *testing.T) {
+	for _, test := range []string{"episodes", "episodes.59798"} {
+		page := cleanText(helperLoadBytes(t, test))
+		doc, err := newDocument(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		link := "http://www.radiorus.ru/brand/57083/episodes"
+		items, err := radiorusEpisodes(doc, episodeURLPrefix(link))
+		if err != nil {
+			t.Fatal(err)
+		}
+		legacy, err := radiorusEpisodesLegacy(episodeURLPrefix(link), page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the legacy parsing never extracted the episode images
+		if len(items) != len(legacy) {
+			t.Fatalf("%s: DOM and legacy parsing differ", test)
+		}
+		for i, item := range items {
+			if !reflect.DeepEqual(item.Item, legacy[i].Item) || item.audioID != legacy[i].audioID {
+				t.Errorf("%s: DOM and legacy parsing differ", test)
+			}
+		}
+	}
+}
+
 func TestUpdatingFeed(t *testing.T) {
 	var page []byte
 
-	feed := &feeds.Feed{
+	feed := &brandFeed{Feed: feeds.Feed{
 		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/59798/episodes"},
-	}
+	}}
 
 	page = helperLoadBytes(t, "episodes.59798")
 	page = cleanText(page)
@@ -169,9 +414,9 @@ func TestUpdatingFeed(t *testing.T) {
 func TestPopulateFeed(t *testing.T) {
 	var page []byte
 
-	feed := &feeds.Feed{
+	feed := &brandFeed{Feed: feeds.Feed{
 		Link: &feeds.Link{Href: "https://smotrim.ru/brand/57083"},
-	}
+	}}
 
 	page = helperLoadBytes(t, "smotrim.57083")
 	page = cleanText(page)
@@ -189,28 +434,100 @@ func TestMissingEpisode(t *testing.T) {
 	server := helperMockServer(t)
 	defer helperCleanupServer(t)
 
-	item := feeds.Item{
+	item := &feedItem{Item: &feeds.Item{
 		Id:   "aabb",
 		Link: &feeds.Link{Href: fmt.Sprintf("%s/brand/none", server.URL)},
-	}
+	}}
 
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
 	defer func() { log.SetOutput(os.Stderr) }()
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	describeEpisode(&item, &wg)
+	if err := describeEpisode(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
 
 	assertStringContains(t, buf.String(), fmt.Sprintf("could not find episode description on page %v: %v", item.Link.Href, errCantParse))
 }
 
+func TestRetryEpisode(t *testing.T) {
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(helperLoadBytes(t, "blues"))
+	}))
+	defer server.Close()
+
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	feed := &brandFeed{}
+	feed.Add(&feedItem{Item: &feeds.Item{Link: &feeds.Link{Href: server.URL}}})
+
+	retries = 1
+	describeEpisodes(context.Background(), feed)
+	if feed.Items[0].Description != "" {
+		t.Fatal("description found before the page was available")
+	}
+
+	retries = 3
+	describeEpisodes(context.Background(), feed)
+	if feed.Items[0].Description == "" {
+		t.Fatal("description not found after retries")
+	}
+
+	// the backoff does not outlive the context
+	count, retryDelay = 0, time.Hour
+	feed.Items[0].Description = ""
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	describeEpisodes(ctx, feed)
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("retries went on for %v after the context was done", d)
+	}
+}
+
 func assertStringContains(t *testing.T, got, want string) {
 	if !strings.Contains(got, want) {
 		t.Fatalf("%v does not contain %v", got, want)
 	}
 }
 
+func TestRestoreDescriptions(t *testing.T) {
+	old, err := readFeed(filepath.Join("testdata", "TestServedFeed.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old.Items) != 10 {
+		t.Fatalf("want 10 items, got %d", len(old.Items))
+	}
+
+	feed := &brandFeed{}
+	feed.Add(&feedItem{Item: &feeds.Item{Id: old.Items[0].Guid}})
+	feed.Add(&feedItem{Item: &feeds.Item{Id: old.Items[1].Guid, Description: "fresh"}})
+	feed.Add(&feedItem{Item: &feeds.Item{Id: "new"}})
+
+	restoreDescriptions(feed, old)
+
+	if feed.Description != old.Description {
+		t.Error("feed description not restored")
+	}
+	if feed.Items[0].Description != old.Items[0].Description {
+		t.Error("item description not restored")
+	}
+	if feed.Items[1].Description != "fresh" {
+		t.Error("fetched description overwritten")
+	}
+	if feed.Items[2].Description != "" {
+		t.Error("description made up for a new item")
+	}
+}
+
 func TestMissingFeedDesc(t *testing.T) {
 	server := helperMockServer(t)
 	defer helperCleanupFile(t, "episodes")
@@ -255,6 +572,64 @@ func TestServedFeed(t *testing.T) {
 	assertGolden(t, actual, golden)
 }
 
+func BenchmarkLegacyEpisodes(b *testing.B) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "episodes"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	page = cleanText(page)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := radiorusEpisodesLegacy(episodeURLPrefix("http://www.radiorus.ru/brand/57083/episodes"), page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCleanText(b *testing.B) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "episodes"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cleanText(page)
+	}
+}
+
+func BenchmarkFeedDesc(b *testing.B) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "about"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := processFeedDesc(page); err != nil {
+			b.Fatal(err)
+		}
+		stripLink(`<a href="/brand/57083">"Аэростат"</a>`)
+	}
+}
+
+func BenchmarkPopulateFeed(b *testing.B) {
+	page, err := ioutil.ReadFile(filepath.Join("testdata", "smotrim.57083"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	page = cleanText(page)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: "https://smotrim.ru/brand/57083"}}}
+		if err := populateFeed(feed, page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkServedFeed(b *testing.B) {
 	server := helperMockServer(b)
 	defer helperCleanupServer(b)
@@ -442,10 +817,63 @@ func TestParseErrors(t *testing.T) {
 }
 
 func TestProcessEpisodeDesc(t *testing.T) {
-	page := helperLoadBytes(t, "blues")
-	got, err := processEpisodeDesc(page)
+	doc, err := newDocument(helperLoadBytes(t, "blues"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := processEpisodeDesc(doc, "radiorus.ru")
 	if err != nil {
 		t.Fatal(err)
 	}
 	assertGolden(t, []byte(got), filepath.Join("testdata", "blues.golden"))
 }
+
+func TestProcessEpisodeDescSite(t *testing.T) {
+	doc, err := newDocument([]byte(`<div class="video__date">5 марта 2021, 14:10</div>
+<div class="video__body"> Smotrim description </div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := processEpisodeDesc(doc, "smotrim.ru")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Smotrim description"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if _, err := processEpisodeDesc(doc, "radiorus.ru"); err != errCantParse {
+		t.Errorf("radiorus markup found on a smotrim page: %v", err)
+	}
+}
+
+func TestDescribeRedirectedEpisode(t *testing.T) {
+	var smotrimURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/radiorus" {
+			http.Redirect(w, r, smotrimURL, http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<div class="video__date">5 марта 2021, 14:10</div>
+<div class="video__body">Smotrim description</div>`))
+	}))
+	defer server.Close()
+	smotrimURL = strings.Replace(server.URL, "127.0.0.1", "smotrim.ru", 1) + "/smotrim"
+
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = newClient(time.Second, true)
+	addr := strings.TrimPrefix(server.URL, "http://")
+	httpClient.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	item := &feedItem{Item: &feeds.Item{Link: &feeds.Link{Href: server.URL + "/radiorus"}}}
+	if err := describeEpisode(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Smotrim description"; item.Description != want {
+		t.Errorf("want description %q, got %q", want, item.Description)
+	}
+	if item.Created.IsZero() {
+		t.Error("smotrim date not parsed")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// outputFormat is what generate writes: the RSS feed or, for
+// "meta-json", all the scraped data as JSON
+var outputFormat = "rss"
+
+// feedMeta is the programme with its episodes as scraped
+type feedMeta struct {
+	Brand       string        `json:"brand"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	Image       string        `json:"image,omitempty"`
+	Author      string        `json:"author,omitempty"`
+	Generated   time.Time     `json:"generated"`
+	Episodes    []episodeMeta `json:"episodes"`
+}
+
+// episodeMeta is an episode as scraped
+type episodeMeta struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+	AudioID     string    `json:"audioId,omitempty"`
+	AudioURL    string    `json:"audioUrl,omitempty"`
+	AudioLength string    `json:"audioLength,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	Categories  []string  `json:"categories,omitempty"`
+}
+
+// metaFilename returns the name of the -format meta-json output file
+func metaFilename(path, brand string) string {
+	return path + "radiorus-" + brand + ".json"
+}
+
+// feedMetaJSON renders the feed with all the scraped data as JSON
+func feedMetaJSON(brand string, feed *brandFeed) ([]byte, error) {
+	return json.MarshalIndent(newFeedMeta(brand, feed), "", "  ")
+}
+
+// newFeedMeta collects all the scraped data of the feed
+func newFeedMeta(brand string, feed *brandFeed) *feedMeta {
+	m := &feedMeta{
+		Brand:       brand,
+		Title:       feed.Title,
+		Description: feed.Description,
+		Generated:   feed.Created,
+		Episodes:    make([]episodeMeta, 0, len(feed.Items)),
+	}
+	if feed.Link != nil {
+		m.URL = feed.Link.Href
+	}
+	if feed.Image != nil {
+		m.Image = feed.Image.Url
+	}
+	if feed.Author != nil {
+		m.Author = feed.Author.Name
+	}
+	for _, item := range feed.Items {
+		ep := episodeMeta{
+			ID:          item.Id,
+			Title:       item.Title,
+			Description: item.Description,
+			Date:        item.Created,
+			AudioID:     audioID(item),
+			Image:       item.image,
+			Categories:  item.categories,
+		}
+		if item.Link != nil {
+			ep.URL = item.Link.Href
+		}
+		if item.Enclosure != nil {
+			ep.AudioURL, ep.AudioLength = item.Enclosure.Url, item.Enclosure.Length
+		}
+		m.Episodes = append(m.Episodes, ep)
+	}
+	return m
+}
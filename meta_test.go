@@ -0,0 +1,41 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestFeedMetaJSON(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
+	}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "episodes"))); err != nil {
+		t.Fatal(err)
+	}
+	feed.Created = time.Date(2020, time.January, 27, 0, 0, 0, 0, time.UTC)
+	feed.Items[0].categories = []string{"музыка"}
+
+	got, err := feedMetaJSON("57083", feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, got, filepath.Join("testdata", t.Name()+".golden"))
+}
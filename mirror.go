@@ -0,0 +1,486 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// mirrorDir is where to download the audio to, the audio is not
+	// mirrored if empty
+	mirrorDir string
+	// mirrorURL is the URL mirrorDir is published under
+	mirrorURL string
+
+	// mirrorJobs is how many audio files are downloaded at once
+	// across all the brands, downloadSlots hold the running ones
+	mirrorJobs    = 2
+	downloadSlots = make(chan struct{}, mirrorJobs)
+)
+
+// sumsName is the file in the brand's mirror directory that holds the
+// checksums of the audio files, in the format sha256sum -c checks
+const sumsName = "SHA256SUMS"
+
+// mirroredFile is an audio file in the mirror
+type mirroredFile struct {
+	File   string `json:"file"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+
+	Duration int               `json:"duration,omitempty"`
+	Bitrate  int               `json:"bitrate,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+
+	// url is where the file is published, if known
+	url string
+}
+
+// integrity returns the Subresource Integrity value of the file
+func (f mirroredFile) integrity() string {
+	b, err := hex.DecodeString(f.SHA256)
+	if err != nil {
+		return ""
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(b)
+}
+
+// mirrorStore is where a brand's mirrored audio is kept
+type mirrorStore interface {
+	// dir is the local directory the audio is downloaded to
+	dir() string
+	// stat returns the size of the stored file and its checksum if
+	// the store knows it, the error satisfies os.IsNotExist if there
+	// is no such file
+	stat(ctx context.Context, name string) (int64, string, error)
+	// put stores the file downloaded to dir
+	put(ctx context.Context, name, sum string) error
+	remove(ctx context.Context, name string) error
+	// list returns the names of the stored files
+	list(ctx context.Context) ([]string, error)
+	read(ctx context.Context, name string) ([]byte, error)
+	write(ctx context.Context, name string, data []byte) error
+	// url is where the stored file is published, empty if unknown
+	url(name string) string
+}
+
+// brandMirror returns the store for the brand's mirrored audio under
+// -mirror
+func brandMirror(brand string) (mirrorStore, error) {
+	if strings.HasPrefix(mirrorDir, "s3://") {
+		return newS3Store(mirrorDir, brand)
+	}
+	return dirStore(filepath.Join(mirrorDir, brand)), nil
+}
+
+// dirStore keeps the mirrored audio in the local directory, which is
+// published under -mirror-url if set
+type dirStore string
+
+func (d dirStore) dir() string {
+	return string(d)
+}
+
+func (d dirStore) stat(_ context.Context, name string) (int64, string, error) {
+	fi, err := os.Stat(filepath.Join(string(d), name))
+	if err != nil {
+		return 0, "", err
+	}
+	return fi.Size(), "", nil
+}
+
+func (d dirStore) put(context.Context, string, string) error {
+	return nil
+}
+
+func (d dirStore) remove(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(string(d), name))
+}
+
+func (d dirStore) list(context.Context) ([]string, error) {
+	names, err := filepath.Glob(filepath.Join(string(d), "*"))
+	for i := range names {
+		names[i] = filepath.Base(names[i])
+	}
+	return names, err
+}
+
+func (d dirStore) read(_ context.Context, name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), name))
+}
+
+func (d dirStore) write(_ context.Context, name string, data []byte) error {
+	return writeOutput(filepath.Join(string(d), name), data)
+}
+
+func (d dirStore) url(name string) string {
+	if mirrorURL == "" {
+		return ""
+	}
+	return mirrorURL + filepath.Base(string(d)) + "/" + name
+}
+
+// mirrorAudio downloads the audio of the items that is not mirrored yet
+// into the store and returns the mirrored files with their checksums
+func mirrorAudio(ctx context.Context, items []*feedItem, store mirrorStore) []mirroredFile {
+	if err := mkdirOutput(store.dir()); err != nil {
+		warnf(ctx, "could not create %s: %v", store.dir(), err)
+		return nil
+	}
+	sums, err := readSums(ctx, store)
+	if err != nil && !os.IsNotExist(err) {
+		warnf(ctx, "could not read checksums: %v", err)
+	}
+
+	// the reruns share the audio
+	var ids []string
+	byID := make(map[string][]*feedItem)
+	for _, item := range items {
+		id := audioID(item)
+		if id == "" {
+			continue
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], item)
+	}
+	if retaining() {
+		ids = retain(ctx, ids, byID, store)
+		pruneMirror(ctx, store, ids, sums)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		sumsMu  sync.Mutex
+		results = make([]*mirroredFile, len(ids))
+	)
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sumsMu.Lock()
+			known := sums[id+".mp3"]
+			sumsMu.Unlock()
+			f, ok := mirrorItem(ctx, byID[id][0], id, store, known)
+			if !ok {
+				return
+			}
+			sumsMu.Lock()
+			sums[f.File] = f.SHA256
+			sumsMu.Unlock()
+			for _, item := range byID[id] {
+				item.mirrored = &f
+				if f.Duration > 0 {
+					item.duration = f.Duration
+				}
+			}
+			results[i] = &f
+		}(i, id)
+	}
+	wg.Wait()
+
+	var files []mirroredFile
+	for _, f := range results {
+		if f != nil {
+			files = append(files, *f)
+		}
+	}
+	if err := writeSums(ctx, store, sums); err != nil {
+		warnf(ctx, "could not write checksums: %v", err)
+	}
+	return files
+}
+
+// mirrorItem makes sure the audio of the item is in the store,
+// downloading it in one of the -mirror-jobs slots if it is not; sum
+// is its checksum if known
+func mirrorItem(ctx context.Context, item *feedItem, id string, store mirrorStore, sum string) (mirroredFile, bool) {
+	f := mirroredFile{File: id + ".mp3", SHA256: sum}
+	name := filepath.Join(store.dir(), f.File)
+	size, stored, err := store.stat(ctx, f.File)
+	switch {
+	case err == nil:
+		f.Size = size
+		if f.SHA256 == "" {
+			f.SHA256 = stored
+		}
+		if f.SHA256 == "" {
+			if f.SHA256, err = hashFile(name); err != nil {
+				warnf(ctx, "could not checksum %s: %v", name, err)
+				return f, false
+			}
+		}
+	case os.IsNotExist(err):
+		select {
+		case downloadSlots <- struct{}{}:
+		case <-ctx.Done():
+			return f, false
+		}
+		f.Size, f.SHA256, err = download(ctx, item.Enclosure.Url, name)
+		<-downloadSlots
+		if err != nil {
+			warnf(ctx, "could not mirror %s: %v", item.Enclosure.Url, err)
+			noteGeoBlocked(ctx, item.Enclosure.Url, err)
+			return f, false
+		}
+	default:
+		warnf(ctx, "could not mirror %s: %v", item.Enclosure.Url, err)
+		return f, false
+	}
+
+	// the stored file is only probed while it is local
+	info, _ := audioInfos.get(audioCacheFile(outputPath), id)
+	if _, err := os.Stat(name); err == nil {
+		info = mirroredInfo(ctx, id, name)
+	}
+	f.Duration, f.Bitrate, f.Tags = info.Duration, info.Bitrate, info.Tags
+	if err := store.put(ctx, f.File, f.SHA256); err != nil {
+		warnf(ctx, "could not store %s: %v", f.File, err)
+		return f, false
+	}
+	f.url = store.url(f.File)
+	return f, true
+}
+
+// maxResumes is how many times an interrupted download is resumed in
+// the same run, as long as each attempt gets something
+const maxResumes = 5
+
+// partName is the file the download of the file goes to until it is
+// complete, so that an interrupted one can be resumed
+func partName(name string) string {
+	return filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".part")
+}
+
+// download saves the URL to the file, returning its size and checksum;
+// the download resumes from what a previous attempt left, if the
+// server supports it
+func download(ctx context.Context, u, name string) (int64, string, error) {
+	part := partName(name)
+	var err error
+	for i := 0; i <= maxResumes; i++ {
+		var got int64
+		if got, err = downloadPart(ctx, u, part); err == nil || got == 0 || ctx.Err() != nil {
+			break
+		}
+		warnf(ctx, "resuming %s after %d bytes: %v", u, got, err)
+		time.Sleep(retryDelay)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	size, sum, err := hashPart(part)
+	if err != nil {
+		return 0, "", err
+	}
+	if stub, err := geoStubFile(part); err != nil || stub {
+		os.Remove(part)
+		if err == nil {
+			err = errGeoBlocked
+		}
+		return 0, "", err
+	}
+	if err := os.Chmod(part, fileMode); err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(part, name); err != nil {
+		return 0, "", err
+	}
+	return size, sum, chown(name)
+}
+
+// downloadPart appends the rest of the URL to the partial file, starting
+// over if the server does not resume, and checks the final size; it
+// returns how much it got
+func downloadPart(ctx context.Context, u, part string) (int64, error) {
+	var have int64
+	if fi, err := os.Stat(part); err == nil {
+		have = fi.Size()
+	}
+
+	req, err := newRequest(ctx, "GET", u)
+	if err != nil {
+		return 0, err
+	}
+	if have > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", have))
+	}
+	res, err := downloadClient().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	flags, total := os.O_WRONLY|os.O_CREATE|os.O_APPEND, int64(-1)
+	switch {
+	case res.StatusCode == http.StatusPartialContent && have > 0:
+		var from, to int64
+		if _, err := fmt.Sscanf(res.Header.Get("Content-Range"), "bytes %d-%d/%d", &from, &to, &total); err != nil || from != have {
+			return 0, fmt.Errorf("bad Content-Range %q", res.Header.Get("Content-Range"))
+		}
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable && have > 0:
+		if res.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", have) {
+			// complete already
+			return 0, nil
+		}
+		// the file has changed, start over
+		if err := os.Remove(part); err != nil {
+			return 0, err
+		}
+		return downloadPart(ctx, u, part)
+	case res.StatusCode == http.StatusOK:
+		flags, have = flags|os.O_TRUNC, 0
+		if res.ContentLength >= 0 {
+			total = res.ContentLength
+		}
+	default:
+		return 0, fmt.Errorf("server returned %s", res.Status)
+	}
+
+	f, err := os.OpenFile(part, flags, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, throttle(ctx, res.Body, downloadLimiter))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, err
+	}
+	if total >= 0 && have+n != total {
+		return n, fmt.Errorf("got %d bytes of %d", have+n, total)
+	}
+	return n, nil
+}
+
+// hashPart returns the size and the checksum of the downloaded file
+func hashPart(name string) (int64, string, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return 0, "", err
+	}
+	sum, err := hashFile(name)
+	return fi.Size(), sum, err
+}
+
+// downloadClient is the HTTP client for the audio, with no overall
+// timeout as the files are large
+func downloadClient() *http.Client {
+	c := *httpClient
+	c.Timeout = 0
+	return &c
+}
+
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readSums reads the checksums file from the store, returning an empty
+// map if there is none
+func readSums(ctx context.Context, store mirrorStore) (map[string]string, error) {
+	sums := make(map[string]string)
+	b, err := store.read(ctx, sumsName)
+	if err != nil {
+		return sums, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 {
+			sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+		}
+	}
+	return sums, sc.Err()
+}
+
+func writeSums(ctx context.Context, store mirrorStore, sums map[string]string) error {
+	files := make([]string, 0, len(sums))
+	for f := range sums {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	var b strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&b, "%s  %s\n", sums[f], f)
+	}
+	return store.write(ctx, sumsName, []byte(b.String()))
+}
+
+// podcastAlternateEnclosure is a podcast:alternateEnclosure element
+type podcastAlternateEnclosure struct {
+	Type      string `xml:"type,attr"`
+	Length    int64  `xml:"length,attr,omitempty"`
+	Bitrate   int    `xml:"bitrate,attr,omitempty"`
+	Title     string `xml:"title,attr,omitempty"`
+	Source    podcastSource
+	Integrity *podcastIntegrity
+}
+
+type podcastSource struct {
+	XMLName xml.Name `xml:"podcast:source"`
+	URI     string   `xml:"uri,attr"`
+}
+
+type podcastIntegrity struct {
+	XMLName xml.Name `xml:"podcast:integrity"`
+	Type    string   `xml:"type,attr"`
+	Value   string   `xml:"value,attr"`
+}
+
+// mirrorEnclosure describes the mirrored copy of the audio as
+// a podcast:alternateEnclosure with the checksum to verify it by
+func mirrorEnclosure(f mirroredFile, typ string) *podcastAlternateEnclosure {
+	a := &podcastAlternateEnclosure{
+		Type:    typ,
+		Length:  f.Size,
+		Bitrate: f.Bitrate * 1000,
+		Title:   "Mirror",
+		Source:  podcastSource{URI: f.url},
+	}
+	if v := f.integrity(); v != "" {
+		a.Integrity = &podcastIntegrity{Type: "sri", Value: v}
+	}
+	return a
+}
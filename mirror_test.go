@@ -0,0 +1,276 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestMirrorAudio(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("id") == "404" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("audio " + r.URL.Query().Get("id")))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(u string) { mirrorURL = u }(mirrorURL)
+	mirrorURL = "https://example.com/audio/"
+	defer func(p, f string) { outputPath, ffprobe = p, f }(outputPath, ffprobe)
+	outputPath, ffprobe = dir, ""
+
+	newItems := func() []*feedItem {
+		return []*feedItem{
+			{Item: &feeds.Item{Title: "1", Link: &feeds.Link{}, Enclosure: &feeds.Enclosure{Url: ts.URL + "/download?id=1", Type: "audio/mpeg", Length: "0"}}},
+			{Item: &feeds.Item{Title: "no audio", Link: &feeds.Link{}}},
+			{Item: &feeds.Item{Title: "missing", Link: &feeds.Link{}, Enclosure: &feeds.Enclosure{Url: ts.URL + "/download?id=404", Type: "audio/mpeg", Length: "0"}}},
+		}
+	}
+	brandDir := filepath.Join(dir, "57083")
+
+	items := newItems()
+	files := mirrorAudio(context.Background(), items, dirStore(brandDir))
+	if len(files) != 1 || files[0].File != "1.mp3" || files[0].Size != 7 {
+		t.Fatalf("got %+v", files)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(brandDir, sumsName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != files[0].SHA256+"  1.mp3\n" {
+		t.Errorf("got checksums %q", b)
+	}
+	if requests != 2 {
+		t.Errorf("want 2 requests, got %d", requests)
+	}
+
+	x := string(createFeed(&brandFeed{Feed: feeds.Feed{Title: "t", Link: &feeds.Link{}}, Items: items}))
+	for _, want := range []string{
+		`<podcast:alternateEnclosure type="audio/mpeg" length="7" title="Mirror">`,
+		`<podcast:source uri="https://example.com/audio/57083/1.mp3"></podcast:source>`,
+		`<podcast:integrity type="sri" value="` + files[0].integrity() + `"></podcast:integrity>`,
+	} {
+		if !strings.Contains(x, want) {
+			t.Errorf("no %s in\n%s", want, x)
+		}
+	}
+
+	// mirrored files are not downloaded again
+	again := newItems()
+	if files2 := mirrorAudio(context.Background(), again, dirStore(brandDir)); len(files2) != 1 || files2[0].SHA256 != files[0].SHA256 {
+		t.Errorf("got %+v", files2)
+	}
+	if requests != 3 {
+		t.Errorf("want the missing file only requested again, got %d requests", requests)
+	}
+}
+
+func TestMirrorResolved(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download":
+			http.Redirect(w, r, "/cdn/"+r.URL.Query().Get("id")+".mp3", http.StatusFound)
+		case "/cdn/1.mp3":
+			http.ServeContent(w, r, "1.mp3", time.Time{}, strings.NewReader("audio 1"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p, f string) { outputPath, ffprobe = p, f }(outputPath, ffprobe)
+	outputPath, ffprobe = dir, ""
+
+	item := &feedItem{Item: &feeds.Item{Title: "1", Link: &feeds.Link{}, Enclosure: &feeds.Enclosure{Url: ts.URL + "/download?id=1", Type: "audio/mpeg", Length: "1024"}}}
+	item.audioID = "1"
+	items := []*feedItem{item}
+
+	// the order generate() runs them in with both -resolve-audio and -mirror
+	resolveEnclosures(context.Background(), items, resolveCacheFile(dir, "57083"))
+	if item.Enclosure.Url != ts.URL+"/cdn/1.mp3" {
+		t.Fatalf("enclosure not resolved: %s", item.Enclosure.Url)
+	}
+	if id := audioID(item); id != "1" {
+		t.Errorf("want audio ID 1 after resolving, got %q", id)
+	}
+	files := mirrorAudio(context.Background(), items, dirStore(filepath.Join(dir, "57083")))
+	if len(files) != 1 || files[0].File != "1.mp3" || files[0].Size != 7 {
+		t.Errorf("got %+v", files)
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	// sha256 of the empty string
+	f := mirroredFile{SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}
+	if got, want := f.integrity(), "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestDownloadResumes(t *testing.T) {
+	audio := bytes.Repeat([]byte("0123456789"), 10000)
+	var (
+		requests int
+		ranges   []string
+		ignore   bool
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		ranges = append(ranges, r.Header.Get("Range"))
+		if ignore {
+			r.Header.Del("Range")
+		}
+		if requests == 1 {
+			// a network blip half way through
+			w.Header().Set("Content-Length", strconv.Itoa(len(audio)))
+			_, _ = w.Write(audio[:len(audio)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "audio.mp3", time.Time{}, bytes.NewReader(audio))
+	}))
+	defer ts.Close()
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	dir, err := ioutil.TempDir("", "radiorus-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	want := sha256.Sum256(audio)
+
+	check := func(name string) {
+		t.Helper()
+		size, sum, err := download(context.Background(), ts.URL, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != int64(len(audio)) || sum != hex.EncodeToString(want[:]) {
+			t.Errorf("got %d bytes, checksum %s", size, sum)
+		}
+		if b, err := ioutil.ReadFile(name); err != nil || !bytes.Equal(b, audio) {
+			t.Errorf("wrong file contents, %v", err)
+		}
+		if _, err := os.Stat(partName(name)); !os.IsNotExist(err) {
+			t.Errorf("partial file left: %v", err)
+		}
+	}
+
+	check(filepath.Join(dir, "1.mp3"))
+	if want := []string{"", "bytes=50000-"}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("want ranges %q, got %q", want, ranges)
+	}
+
+	// left from an earlier run
+	name := filepath.Join(dir, "2.mp3")
+	if err := ioutil.WriteFile(partName(name), audio[:30000], 0600); err != nil {
+		t.Fatal(err)
+	}
+	ranges = nil
+	check(name)
+	if want := []string{"bytes=30000-"}; !reflect.DeepEqual(ranges, want) {
+		t.Errorf("want ranges %q, got %q", want, ranges)
+	}
+
+	// the server does not do ranges, the download starts over
+	name = filepath.Join(dir, "3.mp3")
+	if err := ioutil.WriteFile(partName(name), []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ignore = true
+	check(name)
+}
+
+func TestMirrorJobs(t *testing.T) {
+	var (
+		mu               sync.Mutex
+		running, maxSeen int
+		requested        = make(map[string]int)
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		requested[r.URL.Query().Get("id")]++
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("audio " + r.URL.Query().Get("id")))
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p, f string, slots chan struct{}) { outputPath, ffprobe, downloadSlots = p, f, slots }(outputPath, ffprobe, downloadSlots)
+	outputPath, ffprobe, downloadSlots = dir, "", make(chan struct{}, 2)
+
+	var items []*feedItem
+	for _, id := range []string{"1", "2", "3", "4", "1"} {
+		items = append(items, &feedItem{Item: &feeds.Item{Link: &feeds.Link{}, Enclosure: &feeds.Enclosure{Url: ts.URL + "/download?id=" + id}}})
+	}
+	files := mirrorAudio(context.Background(), items, dirStore(filepath.Join(dir, "57083")))
+
+	if len(files) != 4 || files[0].File != "1.mp3" || files[3].File != "4.mp3" {
+		t.Errorf("got %+v", files)
+	}
+	if maxSeen != 2 {
+		t.Errorf("want 2 downloads at once, got %d", maxSeen)
+	}
+	if requested["1"] != 1 {
+		t.Errorf("rerun audio requested %d times", requested["1"])
+	}
+	if items[4].mirrored == nil {
+		t.Error("rerun not marked mirrored")
+	}
+}
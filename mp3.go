@@ -0,0 +1,108 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// mp3Frame is what the first MPEG audio frame header tells
+type mp3Frame struct {
+	offset          int // of the frame in the data
+	bitrate         int // kbit/s
+	sampleRate      int
+	samplesPerFrame int
+	frames          int // as per the Xing/Info header, 0 if none
+}
+
+var (
+	mp3Bitrates = [2][16]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}, // MPEG-1 Layer III
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},     // MPEG-2 and 2.5 Layer III
+	}
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// id3Size returns the size of the ID3v2 tag the data starts with,
+// 0 if there is none
+func id3Size(data []byte) int {
+	if len(data) < 10 || !bytes.HasPrefix(data, []byte("ID3")) {
+		return 0
+	}
+	size := int(data[6]&0x7f)<<21 | int(data[7]&0x7f)<<14 | int(data[8]&0x7f)<<7 | int(data[9]&0x7f)
+	size += 10
+	if data[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size
+}
+
+// parseMP3Frame finds the first Layer III frame in the data and reads
+// its header, along with the Xing/Info header if there is one
+func parseMP3Frame(data []byte) (f mp3Frame, ok bool) {
+	for i := 0; i+4 <= len(data); i++ {
+		if data[i] != 0xff || data[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version, layer := data[i+1]>>3&3, data[i+1]>>1&3
+		rateIdx, srIdx := data[i+2]>>4, data[i+2]>>2&3
+		if version == 1 || layer != 1 || rateIdx == 0 || rateIdx == 15 || srIdx == 3 {
+			continue
+		}
+		f = mp3Frame{offset: i, sampleRate: mp3SampleRates[version][srIdx], samplesPerFrame: 1152}
+		if version == 3 {
+			f.bitrate = mp3Bitrates[0][rateIdx]
+		} else {
+			f.bitrate, f.samplesPerFrame = mp3Bitrates[1][rateIdx], 576
+		}
+
+		mono := data[i+3]>>6 == 3
+		side := 32
+		switch {
+		case version == 3 && mono:
+			side = 17
+		case version != 3 && !mono:
+			side = 17
+		case version != 3 && mono:
+			side = 9
+		}
+		if x := i + 4 + side; x+12 <= len(data) {
+			if tag := string(data[x : x+4]); (tag == "Xing" || tag == "Info") && data[x+7]&1 != 0 {
+				f.frames = int(binary.BigEndian.Uint32(data[x+8:]))
+			}
+		}
+		return f, true
+	}
+	return f, false
+}
+
+// duration returns the duration in seconds of the audio of the given
+// size, counting from the frame; exact if the frame count is known,
+// and assuming constant bitrate otherwise
+func (f mp3Frame) duration(size int64) int {
+	if f.frames > 0 {
+		return int((int64(f.frames)*int64(f.samplesPerFrame) + int64(f.sampleRate)/2) / int64(f.sampleRate))
+	}
+	if size <= 0 || f.bitrate == 0 {
+		return 0
+	}
+	return int((size*8 + int64(f.bitrate)*500) / (int64(f.bitrate) * 1000))
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// helperMP3 makes the beginning of an MP3 file: an ID3v2 tag with
+// the given payload size, a 128 kbit/s 44.1 kHz joint stereo frame
+// header and, if frames is not 0, a Xing header
+func helperMP3(tagSize, frames int) []byte {
+	var b bytes.Buffer
+	if tagSize != 0 {
+		b.WriteString("ID3\x04\x00\x00")
+		b.Write([]byte{byte(tagSize >> 21 & 0x7f), byte(tagSize >> 14 & 0x7f), byte(tagSize >> 7 & 0x7f), byte(tagSize & 0x7f)})
+		b.Write(make([]byte, tagSize))
+	}
+	b.Write([]byte{0xff, 0xfb, 0x90, 0x64})
+	b.Write(make([]byte, 32))
+	if frames != 0 {
+		b.WriteString("Xing")
+		binary.Write(&b, binary.BigEndian, uint32(1))
+		binary.Write(&b, binary.BigEndian, uint32(frames))
+	}
+	b.Write(make([]byte, 400))
+	return b.Bytes()
+}
+
+func TestID3Size(t *testing.T) {
+	if got := id3Size(helperMP3(300, 0)); got != 310 {
+		t.Errorf("want 310, got %d", got)
+	}
+	if got := id3Size(helperMP3(0, 0)); got != 0 {
+		t.Errorf("want 0, got %d", got)
+	}
+}
+
+func TestParseMP3Frame(t *testing.T) {
+	f, ok := parseMP3Frame(append([]byte{0xff, 0x00, 0x12}, helperMP3(0, 0)...))
+	if !ok {
+		t.Fatal("no frame found")
+	}
+	if f.offset != 3 || f.bitrate != 128 || f.sampleRate != 44100 || f.samplesPerFrame != 1152 || f.frames != 0 {
+		t.Errorf("wrong frame %+v", f)
+	}
+	if got := f.duration(1600000); got != 100 {
+		t.Errorf("want CBR duration 100, got %d", got)
+	}
+
+	f, ok = parseMP3Frame(helperMP3(0, 100000))
+	if !ok {
+		t.Fatal("no frame found")
+	}
+	if got := f.duration(1); got != 2612 {
+		t.Errorf("want Xing duration 2612, got %d", got)
+	}
+
+	if _, ok := parseMP3Frame([]byte("not an mp3 at all")); ok {
+		t.Error("frame found in garbage")
+	}
+}
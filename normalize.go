@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeText brings the scraped text to NFC as far as Latin,
+// Greek and Cyrillic letters go, and strips the zero-width and other
+// invisible characters that occasionally end up in titles
+func normalizeText(s string) string {
+	if s == "" {
+		return s
+	}
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if invisible(r) {
+			continue
+		}
+		if n := len(out); n > 0 && unicode.Is(unicode.Mn, r) {
+			if c, ok := compositions[[2]rune{out[n-1], r}]; ok {
+				out[n-1] = c
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// invisible tells whether the rune has no business in the feed text
+func invisible(r rune) bool {
+	switch {
+	case r == '\n' || r == '\r' || r == '\t':
+		return false
+	case r >= 0x200B && r <= 0x200F, // zero-width space, joiners, marks
+		r >= 0x202A && r <= 0x202E, // bidi embeddings and overrides
+		r >= 0x2060 && r <= 0x2064, // word joiner, invisible operators
+		r >= 0x2066 && r <= 0x2069, // bidi isolates
+		r == 0xFEFF,                // byte order mark
+		r == 0x00AD:                // soft hyphen
+		return true
+	}
+	return unicode.IsControl(r)
+}
+
+// normalizeFeed normalizes all the scraped text of the feed
+func normalizeFeed(feed *brandFeed) {
+	feed.Title = normalizeText(feed.Title)
+	feed.Description = normalizeText(feed.Description)
+	if feed.Image != nil {
+		feed.Image.Title = normalizeText(feed.Image.Title)
+	}
+	if feed.Author != nil {
+		feed.Author.Name = normalizeText(feed.Author.Name)
+	}
+	for _, item := range feed.Items {
+		item.Title = normalizeText(item.Title)
+		item.Description = normalizeText(item.Description)
+		if item.Author != nil {
+			item.Author.Name = normalizeText(item.Author.Name)
+		}
+		if tags := item.categories; len(tags) != 0 {
+			normalized := make([]string, len(tags))
+			for i, tag := range tags {
+				normalized[i] = strings.TrimSpace(normalizeText(tag))
+			}
+			item.categories = normalized
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := map[string]string{
+		"Новый выпуск":                  "Новый выпуск",
+		"Чаи\u0306ковскии\u0306":        "Чайковский",
+		"Е\u0308лка и е\u0308жик":       "Ёлка и ёжик",
+		"Cafe\u0301":                    "Café",
+		"Vie\u0323\u0302t":              "Việt",
+		"\ufeffАэро\u200bстат\u00ad":    "Аэростат",
+		"\u202bраз\u202c\u2060два":      "раздва",
+		"строка\nвторая\tтабуляция\x07": "строка\nвторая\tтабуляция",
+		"x\u0353":     "x\u0353",
+		"Бори\u0301с": "Бори\u0301с",
+	}
+	for in, want := range tests {
+		if got := normalizeText(in); got != want {
+			t.Errorf("%q: want %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestNormalizeFeed(t *testing.T) {
+	item := &feedItem{Item: &feeds.Item{
+		Title:       "Аэро\u200bстат",
+		Description: "Чаи\u0306ковскии\u0306",
+		Author:      &feeds.Author{Name: "Бори\u200dс"},
+	}}
+	feed := &brandFeed{
+		Feed: feeds.Feed{
+			Title:       "\ufeffАэростат",
+			Description: "е\u0308",
+			Image:       &feeds.Image{Title: "\u200eАэростат"},
+			Author:      &feeds.Author{Name: "Гребенщико\u200dв"},
+		},
+		Items: []*feedItem{item},
+	}
+	item.categories = []string{"джаз\u200b"}
+
+	normalizeFeed(feed)
+
+	if feed.Title != "Аэростат" || feed.Description != "ё" || feed.Image.Title != "Аэростат" || feed.Author.Name != "Гребенщиков" {
+		t.Errorf("feed not normalized: %q %q %q %q", feed.Title, feed.Description, feed.Image.Title, feed.Author.Name)
+	}
+	if item.Title != "Аэростат" || item.Description != "Чайковский" || item.Author.Name != "Борис" {
+		t.Errorf("item not normalized: %q %q %q", item.Title, item.Description, item.Author.Name)
+	}
+	if got, want := item.categories, []string{"джаз"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	// fileMode is the permissions of the files written
+	fileMode os.FileMode = 0644
+	// fileOwner is who the files written should belong to
+	fileOwner = noOwner
+)
+
+// owner is the user and group IDs to chown the files to, -1 meaning
+// the ID is not to be changed
+type owner struct {
+	uid, gid int
+}
+
+var noOwner = owner{-1, -1}
+
+// modeFlag is an octal file mode flag value
+type modeFlag struct {
+	mode *os.FileMode
+}
+
+func (f modeFlag) String() string {
+	if f.mode == nil {
+		return ""
+	}
+	return fmt.Sprintf("%04o", uint32(*f.mode))
+}
+
+func (f modeFlag) Set(s string) error {
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m > 0777 {
+		return fmt.Errorf("bad file mode %q", s)
+	}
+	*f.mode = os.FileMode(m)
+	return nil
+}
+
+// ownerFlag is a user[:group] flag value, the user and the group given
+// either as names or as numeric IDs
+type ownerFlag struct {
+	owner *owner
+	value string
+}
+
+func (f *ownerFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *ownerFlag) Set(s string) error {
+	o, err := parseOwner(s)
+	if err != nil {
+		return err
+	}
+	*f.owner, f.value = o, s
+	return nil
+}
+
+func parseOwner(s string) (owner, error) {
+	o := noOwner
+	name, group := s, ""
+	if i := strings.Index(s, ":"); i >= 0 {
+		name, group = s[:i], s[i+1:]
+	}
+	if name != "" {
+		uid, err := strconv.Atoi(name)
+		if err != nil {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return o, err
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return o, fmt.Errorf("user %s has non-numeric ID %q", name, u.Uid)
+			}
+		}
+		o.uid = uid
+	}
+	if group != "" {
+		gid, err := strconv.Atoi(group)
+		if err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return o, err
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return o, fmt.Errorf("group %s has non-numeric ID %q", group, g.Gid)
+			}
+		}
+		o.gid = gid
+	}
+	if o == noOwner {
+		return o, fmt.Errorf("bad owner %q", s)
+	}
+	return o, nil
+}
+
+// dirMode is the mode of the directories created for the files of
+// the mode: the directory is searchable by whoever can read the files
+func dirMode(mode os.FileMode) os.FileMode {
+	return mode | (mode&0444)>>2
+}
+
+// writeOutput writes the file with the -file-mode permissions and
+// the -owner ownership
+func writeOutput(filename string, data []byte) error {
+	if err := writeFileAtomic(filename, data, fileMode); err != nil {
+		return err
+	}
+	return chown(filename)
+}
+
+// mkdirOutput creates the directory with the parents as needed,
+// chowning the ones created
+func mkdirOutput(dir string) error {
+	dir = filepath.Clean(dir)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return err
+	}
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := mkdirOutput(parent); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, dirMode(fileMode)); err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	// not to depend on the umask
+	if err := os.Chmod(dir, dirMode(fileMode)); err != nil {
+		return err
+	}
+	return chown(dir)
+}
+
+func chown(name string) error {
+	if fileOwner == noOwner {
+		return nil
+	}
+	return os.Chown(name, fileOwner.uid, fileOwner.gid)
+}
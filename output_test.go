@@ -0,0 +1,120 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestModeFlag(t *testing.T) {
+	var mode os.FileMode
+	f := modeFlag{&mode}
+	if err := f.Set("640"); err != nil {
+		t.Fatal(err)
+	}
+	if mode != 0640 || f.String() != "0640" {
+		t.Errorf("got %o, %q", mode, f.String())
+	}
+	for _, bad := range []string{"", "8", "rw-r--r--", "1777"} {
+		if err := f.Set(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+}
+
+func TestParseOwner(t *testing.T) {
+	tests := map[string]owner{
+		"1000":     {1000, -1},
+		"1000:100": {1000, 100},
+		":100":     {-1, 100},
+		"1000:":    {1000, -1},
+	}
+	for s, want := range tests {
+		got, err := parseOwner(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: want %v, got %v", s, want, got)
+		}
+	}
+	for _, bad := range []string{"", ":", "no-such-user-here"} {
+		if _, err := parseOwner(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+
+	if u, err := user.Current(); err == nil && runtime.GOOS != "windows" {
+		got, err := parseOwner(u.Username)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := os.Getuid(); got.uid != want {
+			t.Errorf("%s: want uid %d, got %d", u.Username, want, got.uid)
+		}
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	for mode, want := range map[os.FileMode]os.FileMode{0644: 0755, 0640: 0750, 0600: 0700, 0664: 0775} {
+		if got := dirMode(mode); got != want {
+			t.Errorf("%04o: want %04o, got %04o", mode, want, got)
+		}
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix permissions on Windows")
+	}
+	dir, err := ioutil.TempDir("", "radiorus-output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(mode os.FileMode, o owner) { fileMode, fileOwner = mode, o }(fileMode, fileOwner)
+	fileMode, fileOwner = 0660, owner{os.Getuid(), os.Getgid()}
+
+	sub := filepath.Join(dir, "a", "b")
+	if err := mkdirOutput(sub + "/"); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(sub, "radiorus-1.rss")
+	if err := writeOutput(file, []byte("<rss/>")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]os.FileMode{
+		filepath.Join(dir, "a"): os.ModeDir | 0770,
+		sub:                     os.ModeDir | 0770,
+		file:                    0660,
+	} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode() != want {
+			t.Errorf("%s: want %v, got %v", name, want, fi.Mode())
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+)
+
+// pageLink makes the descriptions end with the link to the episode page
+// for the clients that do not show the item link
+var pageLink bool
+
+const pageLinkLabel = "Страница выпуска: "
+
+// addPageLinks appends the episode page link to the descriptions of the
+// items, unless it is already there (e.g. restored from the old feed)
+func addPageLinks(items []*feedItem) {
+	for _, item := range items {
+		if item.Link == nil || item.Link.Href == "" {
+			continue
+		}
+		line := pageLinkLabel + item.Link.Href
+		switch {
+		case strings.Contains(item.Description, line):
+		case item.Description == "":
+			item.Description = line
+		default:
+			item.Description += "\n\n" + line
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestAddPageLinks(t *testing.T) {
+	link := "https://www.radiorus.ru/brand/57083/episode/2237849"
+	items := []*feedItem{
+		{Item: &feeds.Item{Link: &feeds.Link{Href: link}, Description: "Описание"}},
+		{Item: &feeds.Item{Link: &feeds.Link{Href: link}}},
+		{Item: &feeds.Item{Link: &feeds.Link{Href: link}, Description: "Описание\n\nСтраница выпуска: " + link}},
+		{Item: &feeds.Item{Description: "Без ссылки"}},
+	}
+	want := []string{
+		"Описание\n\nСтраница выпуска: " + link,
+		"Страница выпуска: " + link,
+		"Описание\n\nСтраница выпуска: " + link,
+		"Без ссылки",
+	}
+
+	addPageLinks(items)
+	for i, item := range items {
+		if item.Description != want[i] {
+			t.Errorf("item %d: want %q, got %q", i, want[i], item.Description)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/feeds"
+)
+
+// mergeParts makes the episodes published in parts a single item
+var mergeParts bool
+
+var (
+	partRe    = regexp.MustCompile(`(?i)^(.*?)[\s.,:;(–—-]+(?:часть|ч\.)\s*(\d+|[[:alpha:]а-яё]+)\)?\.?$`)
+	partWords = map[string]int{"первая": 1, "вторая": 2, "третья": 3, "четвертая": 4, "четвёртая": 4, "пятая": 5}
+)
+
+// episodePart is a part of the episode other than the first one,
+// merged into the item of the first part
+type episodePart struct {
+	title     string
+	enclosure *feeds.Enclosure
+}
+
+// partEnclosure lists the part as a podcast:alternateEnclosure
+func partEnclosure(p episodePart) *podcastAlternateEnclosure {
+	length, _ := strconv.ParseInt(p.enclosure.Length, 10, 64)
+	return &podcastAlternateEnclosure{
+		Type:   p.enclosure.Type,
+		Length: length,
+		Title:  p.title,
+		Source: podcastSource{URI: p.enclosure.Url},
+	}
+}
+
+// splitPart splits the title of the episode published in parts into
+// the title of the whole and the part number, the number is 0 if the
+// title does not name a part
+func splitPart(title string) (string, int) {
+	m := partRe.FindStringSubmatch(strings.TrimSpace(title))
+	if m == nil || m[1] == "" {
+		return title, 0
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		n = partWords[strings.ToLower(m[2])]
+	}
+	if n == 0 {
+		return title, 0
+	}
+	return m[1], n
+}
+
+// mergeEpisodeParts merges the parts of each episode published in parts
+// into the item of the first part: it gets the title of the whole, the
+// descriptions of all the parts, and the audio of the rest of the parts
+// as alternate enclosures; the episodes missing the first part, or
+// with a part number repeated, are left alone
+func mergeEpisodeParts(items []*feedItem) []*feedItem {
+	type part struct {
+		item *feedItem
+		n    int
+	}
+	var (
+		wholes = make(map[string][]part)
+		bases  = make(map[string]string)
+	)
+	for _, item := range items {
+		base, n := splitPart(item.Title)
+		if n == 0 {
+			continue
+		}
+		key := rerunTitle(base)
+		wholes[key] = append(wholes[key], part{item, n})
+		bases[key] = base
+	}
+
+	merged := make(map[*feedItem]bool)
+	for key, parts := range wholes {
+		sort.SliceStable(parts, func(i, j int) bool { return parts[i].n < parts[j].n })
+		if len(parts) < 2 || parts[0].n != 1 {
+			continue
+		}
+		repeated := false
+		for i := 1; i < len(parts); i++ {
+			repeated = repeated || parts[i].n == parts[i-1].n
+		}
+		if repeated {
+			continue
+		}
+		first := parts[0].item
+		first.Title = bases[key]
+		var extra []episodePart
+		for _, p := range parts[1:] {
+			if p.item.Description != "" && !strings.Contains(first.Description, p.item.Description) {
+				first.Description = strings.TrimSpace(first.Description + "\n\n" + p.item.Description)
+			}
+			if p.item.Enclosure != nil && p.item.Enclosure.Url != "" {
+				extra = append(extra, episodePart{title: "Часть " + strconv.Itoa(p.n), enclosure: p.item.Enclosure})
+			}
+			merged[p.item] = true
+		}
+		first.parts = extra
+	}
+
+	var kept []*feedItem
+	for _, item := range items {
+		if !merged[item] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
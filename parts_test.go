@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestSplitPart(t *testing.T) {
+	tests := []struct {
+		title, base string
+		n           int
+	}{
+		{"Новые имена. Часть 1", "Новые имена", 1},
+		{"Новые имена, часть 2", "Новые имена", 2},
+		{"Новые имена (ч. 3)", "Новые имена", 3},
+		{"Новые имена — Часть вторая", "Новые имена", 2},
+		{"Новые имена", "Новые имена", 0},
+		{"Шестая часть света", "Шестая часть света", 0},
+		{"Горькая участь 2", "Горькая участь 2", 0},
+		{"Часть 1", "Часть 1", 0},
+	}
+	for _, tt := range tests {
+		if base, n := splitPart(tt.title); base != tt.base || n != tt.n {
+			t.Errorf("%q: want %q, %d, got %q, %d", tt.title, tt.base, tt.n, base, n)
+		}
+	}
+}
+
+func TestMergeEpisodeParts(t *testing.T) {
+	audio := func(id string) *feeds.Enclosure {
+		return &feeds.Enclosure{Url: "https://audio.vgtrk.com/download?id=" + id, Length: "100", Type: "audio/mpeg"}
+	}
+	link := &feeds.Link{Href: "l"}
+	items := []*feedItem{
+		{Item: &feeds.Item{Title: "Новые имена, часть 2", Description: "Вторая", Enclosure: audio("2"), Link: link}},
+		{Item: &feeds.Item{Title: "The Cure", Enclosure: audio("3"), Link: link}},
+		{Item: &feeds.Item{Title: "Новые имена. Часть 1", Description: "Первая", Enclosure: audio("1"), Link: link}},
+		{Item: &feeds.Item{Title: "Блюз, часть 2", Enclosure: audio("4"), Link: link}},
+	}
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: link}}
+	feed.Items = mergeEpisodeParts(items)
+
+	want := []string{"The Cure", "Новые имена", "Блюз, часть 2"}
+	if got := itemTitles(feed.Items); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if got, want := feed.Items[1].Description, "Первая\n\nВторая"; got != want {
+		t.Errorf("want description %q, got %q", want, got)
+	}
+
+	got := string(createFeed(feed))
+	for _, want := range []string{
+		`<podcast:alternateEnclosure type="audio/mpeg" length="100" title="Часть 2">`,
+		`<podcast:source uri="https://audio.vgtrk.com/download?id=2"></podcast:source>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+	if n := strings.Count(got, "<podcast:alternateEnclosure"); n != 1 {
+		t.Errorf("want 1 alternate enclosure, got %d", n)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// creditRe finds the "Гость: ..." and "Ведущий: ..." credits in the
+// episode descriptions
+var creditRe = regexp.MustCompile(`(?i)(гост[ьия]|ведущ(?:ий|ая|ие))\s*:\s*([^\n.;]+)`)
+
+// podcastPerson is a podcast:person element
+type podcastPerson struct {
+	Role string `xml:"role,attr,omitempty"`
+	Name string `xml:",chardata"`
+}
+
+// creditedPeople finds the hosts and the guests named in the episode
+// description
+func creditedPeople(desc string) (people []podcastPerson) {
+	seen := make(map[string]bool)
+	for _, m := range creditRe.FindAllStringSubmatch(desc, -1) {
+		role := "guest"
+		if strings.HasPrefix(strings.ToLower(m[1]), "ведущ") {
+			role = "host"
+		}
+		for _, name := range splitNames(m[2]) {
+			if !seen[role+name] {
+				seen[role+name] = true
+				people = append(people, podcastPerson{Role: role, Name: name})
+			}
+		}
+	}
+	return
+}
+
+// hosts makes the podcast:person elements for the presenters
+func hosts(names string) (people []podcastPerson) {
+	for _, name := range splitNames(names) {
+		people = append(people, podcastPerson{Role: "host", Name: name})
+	}
+	return
+}
+
+// splitNames splits a list of names like "Иван Петров, Пётр Иванов и
+// Анна Сидорова"
+func splitNames(s string) (names []string) {
+	for _, part := range strings.Split(s, ",") {
+		for _, name := range strings.Split(part, " и ") {
+			if name = strings.Join(strings.Fields(name), " "); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return
+}
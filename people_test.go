@@ -0,0 +1,68 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestCreditedPeople(t *testing.T) {
+	tests := map[string][]podcastPerson{
+		"Без гостей.": nil,
+		"Гость: Андрей Макаревич.\n\nВедущая: Анна Петрова": {
+			{Role: "guest", Name: "Андрей Макаревич"},
+			{Role: "host", Name: "Анна Петрова"},
+		},
+		"В студии гости: Иван Петров, Пётр Иванов и Анна Сидорова; музыка": {
+			{Role: "guest", Name: "Иван Петров"},
+			{Role: "guest", Name: "Пётр Иванов"},
+			{Role: "guest", Name: "Анна Сидорова"},
+		},
+		"ГОСТЬ:Андрей Макаревич. Гость: Андрей Макаревич.": {
+			{Role: "guest", Name: "Андрей Макаревич"},
+		},
+	}
+	for desc, want := range tests {
+		if got := creditedPeople(desc); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %v, got %v", desc, want, got)
+		}
+	}
+}
+
+func TestPeopleRendered(t *testing.T) {
+	feed := &brandFeed{
+		Feed: feeds.Feed{
+			Title:  "f",
+			Link:   &feeds.Link{Href: "l"},
+			Author: &feeds.Author{Name: "Борис Гребенщиков"},
+		},
+		Items: []*feedItem{{Item: &feeds.Item{Title: "t", Link: &feeds.Link{Href: "l"}, Description: "Гость: Андрей Макаревич."}}},
+	}
+	got := string(createFeed(feed))
+	for _, want := range []string{
+		`xmlns:podcast="` + podcastNS + `"`,
+		`<podcast:person role="host">Борис Гребенщиков</podcast:person>`,
+		`<podcast:person role="guest">Андрей Макаревич</podcast:person>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+const podcastNS = "https://podcastindex.org/namespace/1.0"
+
+// podcastNamespaceUUID is the namespace podcast:guid values are
+// derived in, as per the Podcasting 2.0 spec
+var podcastNamespaceUUID = [16]byte{0xea, 0xd4, 0xc2, 0x36, 0xbf, 0x58, 0x58, 0xc6, 0xa2, 0xc6, 0xa6, 0xb2, 0x8d, 0x12, 0x8c, 0xb6}
+
+// uuidV5 makes an RFC 4122 name-based (SHA-1) UUID
+func uuidV5(namespace [16]byte, name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// podcastGUID returns the podcast:guid of the brand; it is derived from
+// the canonical brand URL rather than from where the feed is published
+// so that it survives moving the feed, and is the same whichever site
+// the feed is made from
+func podcastGUID(brand string) string {
+	u := "https://www.radiorus.ru/brand/" + brand
+	u = strings.TrimRight(strings.TrimPrefix(u, "https://"), "/")
+	return uuidV5(podcastNamespaceUUID, u)
+}
+
+// withPodcastGUID adds the podcast:guid of the brand to the channel
+func withPodcastGUID(brand string) extension {
+	return func(doc *rssDoc) {
+		doc.PodcastNamespace = podcastNS
+		doc.Channel.PodcastGUID = podcastGUID(brand)
+	}
+}
+
+// defaultFunding is the podcast:funding from the command line for the
+// brands that do not configure their own
+var defaultFunding funding
+
+// podcastFunding is a podcast:funding element
+type podcastFunding struct {
+	URL     string `xml:"url,attr"`
+	Message string `xml:",chardata"`
+}
+
+// withFunding adds the podcast:funding to the channel
+func withFunding(f funding) extension {
+	return func(doc *rssDoc) {
+		doc.PodcastNamespace = podcastNS
+		doc.Channel.Funding = &podcastFunding{URL: f.URL, Message: f.Message}
+	}
+}
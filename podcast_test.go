@@ -0,0 +1,57 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestUUIDv5(t *testing.T) {
+	// the example from the Podcasting 2.0 namespace documentation
+	if got, want := uuidV5(podcastNamespaceUUID, "podnews.net/rss"), "9b024349-ccf0-5f69-a609-6b82873eab3c"; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestPodcastGUID(t *testing.T) {
+	g := podcastGUID("57083")
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(g) {
+		t.Errorf("%s is not a UUIDv5", g)
+	}
+	if g != podcastGUID("57083") || g == podcastGUID("59798") {
+		t.Error("podcast:guid is not stable per brand")
+	}
+
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}}}
+	got := string(createFeed(feed, withPodcastGUID("57083")))
+	for _, want := range []string{`xmlns:podcast="` + podcastNS + `"`, "<podcast:guid>" + g + "</podcast:guid>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s missing from %s", want, got)
+		}
+	}
+}
+
+func TestFunding(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}}}
+	got := string(createFeed(feed, withFunding(funding{URL: "https://example.com/donate", Message: "Поддержать"})))
+	if want := `<podcast:funding url="https://example.com/donate">Поддержать</podcast:funding>`; !strings.Contains(got, want) {
+		t.Errorf("%s missing from %s", want, got)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fetchLimiter limits the rate of requests across all the brands,
+// nil means unlimited
+var fetchLimiter *limiter
+
+// limiter spaces the events evenly at the configured rate
+type limiter struct {
+	mu       sync.Mutex
+	next     time.Time
+	interval time.Duration
+}
+
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next event is allowed or the context is done
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if at.Equal(now) {
+		return nil
+	}
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// generateAll runs gen for the brands using up to jobs workers,
+// the results and errors are in the order of the brands
+func generateAll(brands []brandConfig, jobs int, gen func(brandConfig) (result, error)) ([]result, []error) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	results := make([]result, len(brands))
+	errs := make([]error, len(brands))
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < len(brands); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i], errs[i] = gen(brands[i])
+			}
+		}()
+	}
+	for i := range brands {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results, errs
+}
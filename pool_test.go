@@ -0,0 +1,93 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGenerateAll(t *testing.T) {
+	var brands []brandConfig
+	for i := 0; i < 10; i++ {
+		brands = append(brands, brandConfig{Brand: fmt.Sprint(i)})
+	}
+
+	var running, maxRunning int32
+	gen := func(bc brandConfig) (result, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if bc.Brand == "7" {
+			return result{}, fmt.Errorf("failed")
+		}
+		return result{brand: bc.Brand}, nil
+	}
+
+	results, errs := generateAll(brands, 3, gen)
+	if maxRunning != 3 {
+		t.Errorf("want 3 brands at a time, got %d", maxRunning)
+	}
+	for i, bc := range brands {
+		if bc.Brand == "7" {
+			if errs[i] == nil {
+				t.Error("error lost")
+			}
+			continue
+		}
+		if errs[i] != nil || results[i].brand != bc.Brand {
+			t.Errorf("brand %s: got %q, %v", bc.Brand, results[i].brand, errs[i])
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var l *limiter
+	if err := l.wait(context.Background()); err != nil {
+		t.Errorf("nil limiter: %v", err)
+	}
+	if newLimiter(0) != nil {
+		t.Error("limiter for zero rate")
+	}
+
+	l = newLimiter(100)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("6 events at 100/s took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l = newLimiter(0.001)
+	_ = l.wait(ctx)
+	if err := l.wait(ctx); err != context.Canceled {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
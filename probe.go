@@ -0,0 +1,255 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeAudio makes the feeds carry the real size and the duration of
+// the audio files, found from the beginning of each file
+var probeAudio bool
+
+// probeHead is how much of the audio file is fetched to probe it
+const probeHead = 64 << 10
+
+// audioInfo is what is known about the audio file
+type audioInfo struct {
+	Length   int64     `json:"length"`
+	Duration int       `json:"duration,omitempty"`
+	Bitrate  int       `json:"bitrate,omitempty"` // kbit/s
+	Probed   time.Time `json:"probed"`
+
+	// Exact is set if the duration is not estimated from the bitrate
+	Exact bool              `json:"exact,omitempty"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+// audioCache keeps the audioInfo by audio ID in between runs; it is
+// shared by all the brands, as the reruns reuse the same audio
+type audioCache struct {
+	mu     sync.Mutex
+	file   string
+	infos  map[string]audioInfo
+	loaded bool
+}
+
+var audioInfos = &audioCache{}
+
+// audioCacheFile returns the file the audio info is kept in
+func audioCacheFile(path string) string {
+	return filepath.Join(path, ".audio.json")
+}
+
+// load reads the cache from the file unless it is already read from it;
+// the caller must hold c.mu
+func (c *audioCache) load(file string) {
+	if c.loaded && c.file == file {
+		return
+	}
+	c.file, c.loaded, c.infos = file, true, make(map[string]audioInfo)
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(b, &c.infos); err != nil {
+		log.Printf("ignoring %s: %v", file, err)
+	}
+}
+
+func (c *audioCache) get(file, id string) (audioInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load(file)
+	info, ok := c.infos[id]
+	return info, ok
+}
+
+// put adds the info to the cache and saves it
+func (c *audioCache) put(file string, infos map[string]audioInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load(file)
+	for id, info := range infos {
+		c.infos[id] = info
+	}
+	b, err := json.MarshalIndent(c.infos, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(file, b, 0644)
+	}
+	if err != nil {
+		log.Printf("could not save %s: %v", file, err)
+	}
+}
+
+// probeEnclosures sets the real sizes of the items' audio files and
+// their durations, probing the files not probed before
+func probeEnclosures(ctx context.Context, items []*feedItem, cacheFile string) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		fresh = make(map[string]audioInfo)
+		infos = make(map[string]audioInfo)
+	)
+	for _, item := range items {
+		id := audioID(item)
+		if id == "" {
+			continue
+		}
+		if info, ok := audioInfos.get(cacheFile, id); ok {
+			infos[id] = info
+			continue
+		}
+		if _, ok := fresh[id]; ok {
+			continue
+		}
+		fresh[id] = audioInfo{}
+
+		wg.Add(1)
+		go func(id, u string) {
+			defer wg.Done()
+			info, err := probeEnclosure(ctx, u)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				warnf(ctx, "could not probe %s: %v", u, err)
+				noteGeoBlocked(ctx, u, err)
+				delete(fresh, id)
+				return
+			}
+			fresh[id] = info
+		}(id, item.Enclosure.Url)
+	}
+	wg.Wait()
+
+	if len(fresh) != 0 {
+		audioInfos.put(cacheFile, fresh)
+	}
+	for id, info := range fresh {
+		infos[id] = info
+	}
+	for _, item := range items {
+		if info, ok := infos[audioID(item)]; ok {
+			applyAudioInfo(item, info)
+		}
+	}
+}
+
+// applyAudioInfo puts what is known of the audio file in the item
+func applyAudioInfo(item *feedItem, info audioInfo) {
+	if info.Length > 0 {
+		item.Enclosure.Length = strconv.FormatInt(info.Length, 10)
+	}
+	if info.Duration > 0 {
+		item.duration = info.Duration
+	}
+}
+
+// probeEnclosure fetches the beginning of the audio file to find its
+// size and duration; the geo-blocking stub is an error
+func probeEnclosure(ctx context.Context, u string) (audioInfo, error) {
+	head, length, err := fetchRange(ctx, u, 0, probeHead)
+	if err != nil {
+		return audioInfo{}, err
+	}
+	if geoStubContent("", "", head) {
+		return audioInfo{}, errGeoBlocked
+	}
+	info, err := headerInfo(head, length, func(off int64, n int) ([]byte, error) {
+		b, _, err := fetchRange(ctx, u, off, int64(n))
+		return b, err
+	})
+	if err == nil && geoStubInfo(info) {
+		return audioInfo{}, errGeoBlocked
+	}
+	return info, err
+}
+
+// headerInfo finds the duration of the MP3 file of the given length
+// from its head, calling readAt for more if the head is all ID3 tag
+func headerInfo(head []byte, length int64, readAt func(off int64, n int) ([]byte, error)) (audioInfo, error) {
+	info := audioInfo{Length: length, Probed: time.Now()}
+	start := id3Size(head)
+	switch {
+	case start < len(head):
+		head = head[start:]
+	case int64(start) < length:
+		// a large cover image in the tag
+		var err error
+		if head, err = readAt(int64(start), 4096); err != nil {
+			return info, err
+		}
+	default:
+		return info, nil
+	}
+	if f, ok := parseMP3Frame(head); ok {
+		info.Bitrate = f.bitrate
+		info.Duration = f.duration(length - int64(start+f.offset))
+		info.Exact = f.frames > 0
+	}
+	return info, nil
+}
+
+// fetchRange fetches up to n bytes of the file from the offset, and
+// returns them along with the size of the whole file
+func fetchRange(ctx context.Context, u string, from, n int64) ([]byte, int64, error) {
+	if err := fetchLimiter.wait(ctx); err != nil {
+		return nil, 0, err
+	}
+	if err := politeWait(ctx, u); err != nil {
+		return nil, 0, err
+	}
+	req, err := newRequest(ctx, "GET", u)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", from, from+n-1))
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	var length int64
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		cr := res.Header.Get("Content-Range")
+		length, _ = strconv.ParseInt(cr[strings.LastIndex(cr, "/")+1:], 10, 64)
+	case http.StatusOK:
+		// the whole file it is
+		length = res.ContentLength
+		if from > 0 {
+			if _, err := io.CopyN(ioutil.Discard, res.Body, from); err != nil {
+				return nil, 0, err
+			}
+		}
+	default:
+		return nil, 0, fmt.Errorf("%s", res.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(res.Body, n))
+	return b, length, err
+}
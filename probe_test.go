@@ -0,0 +1,101 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestProbeEnclosures(t *testing.T) {
+	files := map[string][]byte{
+		"1": append(helperMP3(10, 0), make([]byte, 1600000)...),
+		"2": append(helperMP3(probeHead, 100000), make([]byte, 1000)...),
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		b, ok := files[r.URL.Query().Get("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "audio.mp3", time.Time{}, bytes.NewReader(b))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cacheFile := audioCacheFile(dir)
+
+	items := func() []*feedItem {
+		var items []*feedItem
+		for _, id := range []string{"1", "2", "1", "3"} {
+			items = append(items, &feedItem{Item: &feeds.Item{
+				Title:     id,
+				Link:      &feeds.Link{Href: "l"},
+				Enclosure: &feeds.Enclosure{Url: server.URL + "/download?id=" + id, Length: "1024", Type: "audio/mpeg"},
+			}})
+		}
+		return items
+	}
+
+	run := items()
+	probeEnclosures(context.Background(), run, cacheFile)
+	if n := atomic.LoadInt32(&requests); n != 4 {
+		t.Errorf("want 4 requests, got %d", n)
+	}
+	wantLengths := []string{"1600456", "66994", "1600456", "1024"}
+	wantDurations := []int{100, 2612, 100, 0}
+	for i, item := range run {
+		if item.Enclosure.Length != wantLengths[i] {
+			t.Errorf("%s: want length %s, got %s", item.Title, wantLengths[i], item.Enclosure.Length)
+		}
+		if got := item.duration; got != wantDurations[i] {
+			t.Errorf("%s: want duration %d, got %d", item.Title, wantDurations[i], got)
+		}
+	}
+	got := string(createFeed(&brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}}, Items: run}))
+	assertStringContains(t, got, "<itunes:duration>2612</itunes:duration>")
+
+	// the next run, even a fresh process, reuses what is probed
+	audioInfos = &audioCache{}
+	atomic.StoreInt32(&requests, 0)
+	run = items()
+	probeEnclosures(context.Background(), run, cacheFile)
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("want only the unknown audio requested, got %d requests", n)
+	}
+	if run[1].Enclosure.Length != "66994" || run[1].duration != 2612 {
+		t.Errorf("cached info not applied: %s, %d", run[1].Enclosure.Length, run[1].duration)
+	}
+	if b := helperReadFile(t, cacheFile); !strings.Contains(b, `"duration": 2612`) {
+		t.Errorf("duration not saved: %s", b)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// proxyBrands are the brands /proxy/ serves, "*" allowing any;
+	// the endpoint is disabled if empty
+	proxyBrands string
+	// proxyTTL is how long the proxied feeds are cached for
+	proxyTTL = time.Hour
+	// proxyMax is how many proxied feeds are cached at most
+	proxyMax = 50
+)
+
+// proxyAudioMax is how many resolved audio URLs are cached at most
+const proxyAudioMax = 1000
+
+var (
+	proxyPathRe  = regexp.MustCompile(`^/proxy/(\d+)\.rss$`)
+	proxyAudioRe = regexp.MustCompile(`^/proxy/audio/(\d+)\.mp3$`)
+)
+
+// feedProxy generates the feeds of the brands that are not configured
+// on request, caching them for a while
+type feedProxy struct {
+	allowAll bool
+	allowed  map[string]bool
+	ttl      time.Duration
+	max      int
+
+	// render creates the feed of the brand, returning the IDs of the
+	// audio it links to through /proxy/audio/
+	render func(ctx context.Context, brand string) ([]byte, []string, error)
+	// resolve follows the audio download redirects
+	resolve func(ctx context.Context, u string) (resolvedEnclosure, error)
+
+	audioMu  sync.Mutex
+	audio    map[string]resolvedEnclosure // by audio ID
+	audioMax int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *proxyEntry, most recently used first
+}
+
+type proxyEntry struct {
+	brand string
+
+	// mu is held while the feed is being generated so that concurrent
+	// requests for the brand share the generation
+	mu        sync.Mutex
+	feed      []byte
+	generated time.Time
+	// audio are the IDs of the audio the feed links to, guarded by
+	// the mu of the feedProxy rather than the entry's
+	audio map[string]bool
+}
+
+func newFeedProxy(brands string, ttl time.Duration, max int) *feedProxy {
+	p := &feedProxy{
+		allowed:  make(map[string]bool),
+		ttl:      ttl,
+		max:      max,
+		render:   renderProxied,
+		resolve:  resolveEnclosure,
+		audio:    make(map[string]resolvedEnclosure),
+		audioMax: proxyAudioMax,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+	for _, b := range strings.Split(brands, ",") {
+		switch b = strings.TrimSpace(b); b {
+		case "":
+		case "*":
+			p.allowAll = true
+		default:
+			p.allowed[b] = true
+		}
+	}
+	return p
+}
+
+// entry returns the cache entry of the brand, evicting the least
+// recently used ones if there are too many
+func (p *feedProxy) entry(brand string) *proxyEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.entries[brand]; ok {
+		p.lru.MoveToFront(el)
+		return el.Value.(*proxyEntry)
+	}
+	e := &proxyEntry{brand: brand}
+	p.entries[brand] = p.lru.PushFront(e)
+	for p.lru.Len() > p.max {
+		el := p.lru.Back()
+		p.lru.Remove(el)
+		delete(p.entries, el.Value.(*proxyEntry).brand)
+	}
+	return e
+}
+
+func (p *feedProxy) feed(ctx context.Context, brand string) ([]byte, error) {
+	e := p.entry(brand)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.feed != nil && time.Since(e.generated) < p.ttl {
+		return e.feed, nil
+	}
+	b, ids, err := p.render(ctx, brand)
+	if err != nil {
+		return nil, err
+	}
+	e.feed, e.generated = b, time.Now()
+	audio := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		audio[id] = true
+	}
+	p.mu.Lock()
+	e.audio = audio
+	p.mu.Unlock()
+	return b, nil
+}
+
+// serves checks whether the audio is linked to from any of the cached
+// feeds
+func (p *feedProxy) serves(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, el := range p.entries {
+		if el.Value.(*proxyEntry).audio[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// audioURL returns where the audio is to be downloaded from right now:
+// the signed URL the download redirects to, resolved anew once it is
+// about to expire, or the download URL itself if it fails to resolve
+func (p *feedProxy) audioURL(ctx context.Context, id string) string {
+	download := enclosure(id).Url
+	now := time.Now()
+
+	p.audioMu.Lock()
+	r, ok := p.audio[id]
+	p.audioMu.Unlock()
+	if ok && !r.expired(now) {
+		return r.URL
+	}
+
+	r, err := p.resolve(ctx, download)
+	if err != nil {
+		warnf(ctx, "could not resolve %s: %v", download, err)
+		return download
+	}
+	p.audioMu.Lock()
+	defer p.audioMu.Unlock()
+	for id, old := range p.audio {
+		if old.expired(now) {
+			delete(p.audio, id)
+		}
+	}
+	for len(p.audio) >= p.audioMax {
+		// drop the one resolved the longest ago
+		var first string
+		for id, old := range p.audio {
+			if first == "" || old.Resolved.Before(p.audio[first].Resolved) {
+				first = id
+			}
+		}
+		delete(p.audio, first)
+	}
+	p.audio[id] = r
+	return r.URL
+}
+
+// proxiedEnclosures points the enclosures of the items to /proxy/audio/,
+// so that the links that expire are resolved when the audio is
+// downloaded rather than when the feed is generated; returns the IDs of
+// the audio
+func proxiedEnclosures(items []*feedItem) (ids []string) {
+	for _, item := range items {
+		if id := audioID(item); id != "" {
+			item.Enclosure.Url = baseURL + "proxy/audio/" + id + ".mp3"
+			ids = append(ids, id)
+		}
+	}
+	return
+}
+
+// proxy serves /proxy/{brand}.rss, redirecting to the generated file
+// for the configured brands, and /proxy/audio/{id}.mp3, redirecting to
+// the current location of the audio
+func (d *daemon) proxy(p *feedProxy) http.Handler {
+	configured := make(map[string]bool, len(d.brands))
+	for _, bc := range d.brands {
+		configured[bc.Brand] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := proxyAudioRe.FindStringSubmatch(r.URL.Path); m != nil {
+			if !p.serves(m[1]) {
+				// not a resolver of any audio for anyone
+				http.NotFound(w, r)
+				return
+			}
+			http.Redirect(w, r, p.audioURL(r.Context(), m[1]), http.StatusFound)
+			return
+		}
+		m := proxyPathRe.FindStringSubmatch(r.URL.Path)
+		if m == nil {
+			http.NotFound(w, r)
+			return
+		}
+		brand := m[1]
+		if configured[brand] {
+			http.Redirect(w, r, feedFilename("/", brand), http.StatusFound)
+			return
+		}
+		if !p.allowAll && !p.allowed[brand] {
+			http.Error(w, "brand "+brand+" is not allowed", http.StatusForbidden)
+			return
+		}
+
+		b, err := p.feed(r.Context(), brand)
+		if err != nil {
+			warnf(r.Context(), "proxy brand %s: %v", brand, err)
+			http.Error(w, "could not generate the feed", http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(p.ttl.Seconds())))
+		_, _ = w.Write(b)
+	})
+}
+
+// renderProxied scrapes the brand and creates its feed the way the
+// configured brands' feeds are, with the command line settings, but
+// leaves the output directory alone
+func renderProxied(ctx context.Context, brand string) ([]byte, []string, error) {
+	feed, err := processBrand(ctx, brandURL(brand, false))
+	if err != nil {
+		return nil, nil, err
+	}
+	feed.Created = time.Now()
+	// no season or archive feeds are written for a brand anyone can
+	// ask for
+	exts := documentExtensions(brandConfig{Brand: brand})
+	var ids []string
+	if resolveAudio && baseURL != "" {
+		ids = proxiedEnclosures(feed.Items)
+	}
+	return createFeed(feed, exts...), ids, nil
+}
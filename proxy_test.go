@@ -0,0 +1,241 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestProxy(t *testing.T) {
+	d := helperDaemon("57083")
+	d.proxied = newFeedProxy("60000, 60001,60002", time.Hour, 2)
+	renders := make(map[string]int)
+	d.proxied.render = func(ctx context.Context, brand string) ([]byte, []string, error) {
+		if brand == "60002" {
+			return nil, nil, errors.New("server error")
+		}
+		renders[brand]++
+		return []byte("<rss>" + brand + "</rss>"), nil, nil
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := get("/proxy/60000.rss")
+		if w.Code != http.StatusOK || w.Body.String() != "<rss>60000</rss>" {
+			t.Fatalf("got %d %q", w.Code, w.Body.String())
+		}
+	}
+	if renders["60000"] != 1 {
+		t.Errorf("want the feed cached, rendered %d times", renders["60000"])
+	}
+
+	tests := map[string]int{
+		"/proxy/57083.rss":    http.StatusFound,
+		"/proxy/59798.rss":    http.StatusForbidden,
+		"/proxy/60002.rss":    http.StatusBadGateway,
+		"/proxy/../x.rss":     http.StatusMovedPermanently,
+		"/proxy/brand.rss":    http.StatusNotFound,
+		"/proxy/60000.rss.gz": http.StatusNotFound,
+	}
+	for path, want := range tests {
+		if w := get(path); w.Code != want {
+			t.Errorf("%s: want %d, got %d", path, want, w.Code)
+		}
+	}
+	if w := get("/proxy/57083.rss"); w.Header().Get("Location") != "/radiorus-57083.rss" {
+		t.Errorf("got redirect to %q", w.Header().Get("Location"))
+	}
+
+	// 60002 failed but was cached as an entry, evicting 60000
+	get("/proxy/60001.rss")
+	get("/proxy/60000.rss")
+	if renders["60000"] != 2 {
+		t.Errorf("want the feed evicted, rendered %d times", renders["60000"])
+	}
+}
+
+func TestProxyExpiry(t *testing.T) {
+	p := newFeedProxy("*", 0, 10)
+	n := 0
+	p.render = func(ctx context.Context, brand string) ([]byte, []string, error) {
+		n++
+		return []byte("rss"), nil, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := p.feed(context.Background(), "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n != 2 {
+		t.Errorf("want expired feed regenerated, rendered %d times", n)
+	}
+}
+
+func TestProxyDisabled(t *testing.T) {
+	d := helperDaemon("57083")
+	w := httptest.NewRecorder()
+	d.handler().ServeHTTP(w, httptest.NewRequest("GET", "/proxy/60000.rss", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("want 404, got %d", w.Code)
+	}
+}
+
+func TestProxyAudio(t *testing.T) {
+	d := helperDaemon("57083")
+	d.proxied = newFeedProxy("*", time.Hour, 2)
+	d.proxied.render = func(ctx context.Context, brand string) ([]byte, []string, error) {
+		return []byte("rss"), []string{"1", "404"}, nil
+	}
+	resolved := make(map[string]int)
+	d.proxied.resolve = func(ctx context.Context, u string) (resolvedEnclosure, error) {
+		resolved[u]++
+		if u == enclosure("404").Url {
+			return resolvedEnclosure{}, errors.New("404 Not Found")
+		}
+		expires := time.Now().Add(3 * time.Hour).Unix()
+		return resolvedEnclosure{URL: fmt.Sprintf("https://cdn.example.com/1.mp3?e=%d&md5=x", expires), Resolved: time.Now()}, nil
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// only the audio of the served feeds is resolved
+	if w := get("/proxy/audio/1.mp3"); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 before the feed is served, got %d", w.Code)
+	}
+	get("/proxy/60000.rss")
+
+	for i := 0; i < 2; i++ {
+		w := get("/proxy/audio/1.mp3")
+		if loc := w.Header().Get("Location"); w.Code != http.StatusFound || !strings.HasPrefix(loc, "https://cdn.example.com/1.mp3?") {
+			t.Fatalf("got %d to %q", w.Code, loc)
+		}
+	}
+	if n := resolved[enclosure("1").Url]; n != 1 {
+		t.Errorf("want the signed URL reused, resolved %d times", n)
+	}
+
+	// the one that fails to resolve is downloaded as is
+	w := get("/proxy/audio/404.mp3")
+	if loc := w.Header().Get("Location"); w.Code != http.StatusFound || loc != enclosure("404").Url {
+		t.Errorf("got %d to %q", w.Code, loc)
+	}
+
+	if w := get("/proxy/audio/x.mp3"); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 for a bad audio ID, got %d", w.Code)
+	}
+	if w := get("/proxy/audio/2.mp3"); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 for the audio of no served feed, got %d", w.Code)
+	}
+	if len(resolved) != 2 {
+		t.Errorf("want only the served audio resolved, got %v", resolved)
+	}
+}
+
+func TestProxyAudioMax(t *testing.T) {
+	p := newFeedProxy("*", time.Hour, 2)
+	p.audioMax = 2
+	p.resolve = func(ctx context.Context, u string) (resolvedEnclosure, error) {
+		return resolvedEnclosure{URL: u + "&resolved", Resolved: time.Now()}, nil
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		p.audioURL(context.Background(), id)
+	}
+	if len(p.audio) != 2 {
+		t.Errorf("want 2 cached, got %d", len(p.audio))
+	}
+	if _, ok := p.audio["3"]; !ok {
+		t.Error("the latest one not cached")
+	}
+}
+
+func TestProxiedEnclosures(t *testing.T) {
+	defer func(u string) { baseURL = u }(baseURL)
+	baseURL = "https://example.org/"
+
+	items := []*feedItem{
+		{Item: &feeds.Item{Enclosure: enclosure("2237849")}},
+		{Item: &feeds.Item{Enclosure: &feeds.Enclosure{Url: "https://cdn.example.com/1.mp3"}}},
+		{Item: &feeds.Item{}},
+	}
+	if ids := proxiedEnclosures(items); len(ids) != 1 || ids[0] != "2237849" {
+		t.Errorf("got IDs %v", ids)
+	}
+	if got, want := items[0].Enclosure.Url, "https://example.org/proxy/audio/2237849.mp3"; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	if got := items[1].Enclosure.Url; got != "https://cdn.example.com/1.mp3" {
+		t.Errorf("enclosure without an audio ID changed to %s", got)
+	}
+}
+
+// hostRewriter sends all the requests to the test server
+type hostRewriter struct{ server *url.URL }
+
+func (h hostRewriter) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host, r.Host = h.server.Scheme, h.server.Host, ""
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRenderProxied(t *testing.T) {
+	server := helperMockServer(t)
+	defer server.Close()
+	defer helperCleanupServer(t)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c *http.Client) { httpClient = c }(httpClient)
+	httpClient = &http.Client{Transport: hostRewriter{u}}
+
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p, s, l string, n int) { outputPath, seasons, language, latest = p, s, l, n }(outputPath, seasons, language, latest)
+	outputPath, seasons, language, latest = dir+"/", "year", "ru", 3
+
+	b, _, err := renderProxied(context.Background(), "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStringContains(t, string(b), "<language>ru</language>")
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("proxied feed wrote %d files to the output directory", len(files))
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// publisher uploads the generated files somewhere besides the local
+// output path
+type publisher interface {
+	publish(ctx context.Context, name string, data []byte) error
+}
+
+// publishers are where the generated files are published to
+var publishers []publisher
+
+// publishAll publishes the file with every publisher
+func publishAll(ctx context.Context, name string, data []byte) error {
+	for _, p := range publishers {
+		if err := p.publish(ctx, name, data); err != nil {
+			return fmt.Errorf("could not publish %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// httpPublisher uploads the files with PUT or POST requests; {name} in
+// the URL is replaced with the file name, which is appended to the URL
+// if it ends with a slash
+type httpPublisher struct {
+	url     string
+	method  string
+	headers http.Header
+	client  *http.Client
+}
+
+var (
+	publishURL     string
+	publishMethod  = "PUT"
+	publishHeaders = make(http.Header)
+)
+
+func newHTTPPublisher(u, method string, headers http.Header) (*httpPublisher, error) {
+	method = strings.ToUpper(method)
+	if method != "PUT" && method != "POST" {
+		return nil, fmt.Errorf("unsupported publishing method %q", method)
+	}
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return nil, fmt.Errorf("publishing URL %q is not HTTP", u)
+	}
+	return &httpPublisher{url: u, method: method, headers: headers, client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+func (p *httpPublisher) target(name string) string {
+	switch {
+	case strings.Contains(p.url, "{name}"):
+		return strings.ReplaceAll(p.url, "{name}", name)
+	case strings.HasSuffix(p.url, "/"):
+		return p.url + name
+	default:
+		return p.url
+	}
+}
+
+func (p *httpPublisher) publish(ctx context.Context, name string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, p.method, p.target(name), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	for k, v := range p.headers {
+		req.Header[k] = v
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = ioutil.ReadAll(res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", p.method, req.URL, res.Status)
+	}
+	return nil
+}
+
+// contentType returns the MIME type of the generated file
+func contentType(name string) string {
+	switch filepath.Ext(name) {
+	case ".rss":
+		return "application/rss+xml; charset=utf-8"
+	case ".gz":
+		return "application/gzip"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
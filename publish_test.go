@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPPublisher(t *testing.T) {
+	var method, path, ct, auth, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		ct, auth = r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		if r.URL.Path == "/fail/radiorus-1.rss" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer ts.Close()
+
+	h := make(http.Header)
+	if err := headerFlag(h).Set("Authorization: Bearer secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		url, method, path string
+		fail              bool
+	}{
+		"dir":         {url: ts.URL + "/feeds/", method: "PUT", path: "/feeds/radiorus-1.rss"},
+		"placeholder": {url: ts.URL + "/upload?f={name}", method: "post", path: "/upload"},
+		"fixed":       {url: ts.URL + "/cms", method: "POST", path: "/cms"},
+		"error":       {url: ts.URL + "/fail/", method: "PUT", fail: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := newHTTPPublisher(tc.url, tc.method, h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.publish(context.Background(), "radiorus-1.rss", []byte("<rss/>"))
+			if tc.fail {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method != p.method || path != tc.path || body != "<rss/>" {
+				t.Errorf("got %s %s %q", method, path, body)
+			}
+			if ct != "application/rss+xml; charset=utf-8" || auth != "Bearer secret" {
+				t.Errorf("got Content-Type %q, Authorization %q", ct, auth)
+			}
+		})
+	}
+}
+
+func TestNewHTTPPublisherInvalid(t *testing.T) {
+	if _, err := newHTTPPublisher("http://example.com/", "DELETE", nil); err == nil {
+		t.Error("want error for DELETE")
+	}
+	if _, err := newHTTPPublisher("ftp://example.com/", "PUT", nil); err == nil {
+		t.Error("want error for non-HTTP URL")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const pushJob = "radiorus-rss"
+
+// episodeErrors counts the episode pages that could not be fetched
+var episodeErrors int32
+
+// runMetrics are the metrics of a one-shot run
+type runMetrics struct {
+	duration time.Duration
+	finished time.Time
+	episodes map[string]int // by brand, for the generated feeds
+	failed   []string       // brands that could not be generated
+}
+
+// formatMetrics renders the metrics in the Prometheus text format
+func formatMetrics(m runMetrics) []byte {
+	var buf bytes.Buffer
+	metric := func(name, help, kind string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("radiorus_rss_run_duration_seconds", "Duration of the last run.", "gauge")
+	fmt.Fprintf(&buf, "radiorus_rss_run_duration_seconds %g\n", m.duration.Seconds())
+
+	success := 0
+	if len(m.failed) == 0 {
+		success = 1
+	}
+	metric("radiorus_rss_run_success", "Whether all the feeds were generated in the last run.", "gauge")
+	fmt.Fprintf(&buf, "radiorus_rss_run_success %d\n", success)
+	if success == 1 {
+		metric("radiorus_rss_last_success_timestamp_seconds", "Time of the last successful run.", "gauge")
+		fmt.Fprintf(&buf, "radiorus_rss_last_success_timestamp_seconds %d\n", m.finished.Unix())
+	}
+
+	var brands []string
+	for brand := range m.episodes {
+		brands = append(brands, brand)
+	}
+	sort.Strings(brands)
+	metric("radiorus_rss_episodes", "Episodes in the generated feed.", "gauge")
+	for _, brand := range brands {
+		fmt.Fprintf(&buf, "radiorus_rss_episodes{brand=%q} %d\n", brand, m.episodes[brand])
+	}
+
+	metric("radiorus_rss_errors", "Feeds that could not be generated in the last run.", "gauge")
+	fmt.Fprintf(&buf, "radiorus_rss_errors %d\n", len(m.failed))
+
+	metric("radiorus_rss_episode_errors", "Episode pages that could not be fetched in the last run.", "gauge")
+	fmt.Fprintf(&buf, "radiorus_rss_episode_errors %d\n", atomic.LoadInt32(&episodeErrors))
+
+	return buf.Bytes()
+}
+
+// pushMetrics sends the metrics to the Pushgateway, replacing the ones
+// with the same names pushed before; the last success time is thus
+// retained after a failed run
+func pushMetrics(gateway string, m runMetrics) error {
+	url := strings.TrimRight(gateway, "/") + "/metrics/job/" + pushJob
+	res, err := http.Post(url, "text/plain; version=0.0.4", bytes.NewReader(formatMetrics(m)))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway %s: %s", url, res.Status)
+	}
+	return nil
+}
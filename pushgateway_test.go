@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func helperRunMetrics() runMetrics {
+	return runMetrics{
+		duration: 95 * time.Second,
+		finished: time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+		episodes: map[string]int{"59798": 12, "57083": 250},
+	}
+}
+
+func TestFormatMetrics(t *testing.T) {
+	defer atomic.StoreInt32(&episodeErrors, 0)
+	atomic.StoreInt32(&episodeErrors, 2)
+
+	golden := filepath.Join("testdata", t.Name()+".golden")
+	assertGolden(t, formatMetrics(helperRunMetrics()), golden)
+
+	m := helperRunMetrics()
+	m.failed = []string{"60000"}
+	failed := string(formatMetrics(m))
+	if strings.Contains(failed, "last_success") {
+		t.Error("last success time reported for a failed run")
+	}
+	if !strings.Contains(failed, "radiorus_rss_run_success 0\n") || !strings.Contains(failed, "radiorus_rss_errors 1\n") {
+		t.Errorf("failure not reported:\n%s", failed)
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		if strings.Contains(path, "broken") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := pushMetrics(server.URL+"/", helperRunMetrics()); err != nil {
+		t.Fatal(err)
+	}
+	if method != "POST" || path != "/metrics/job/radiorus-rss" {
+		t.Errorf("want POST /metrics/job/radiorus-rss, got %s %s", method, path)
+	}
+	if body != string(formatMetrics(helperRunMetrics())) {
+		t.Error("pushed metrics differ")
+	}
+
+	if err := pushMetrics(server.URL+"/broken", helperRunMetrics()); err == nil {
+		t.Error("no error for rejected push")
+	}
+}
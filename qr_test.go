@@ -0,0 +1,215 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// HELLO WORLD in the alphanumeric mode, version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestQRFormatBits(t *testing.T) {
+	for mask, want := range []int{
+		0x5412, // 101010000010010
+		0x5125, // 101000100100101
+		0x5E7C, // 101111001111100
+		0x5B4B, // 101101101001011
+		0x45F9, // 100010111111001
+		0x40CE, // 100000011001110
+		0x4F97, // 100111110010111
+		0x4AA0, // 100101010100000
+	} {
+		if got := qrFormatBits(mask); got != want {
+			t.Errorf("mask %d: want %015b, got %015b", mask, want, got)
+		}
+	}
+	if got, want := qrVersionBits(7), 0x07C94; got != want {
+		t.Errorf("version 7: want %018b, got %018b", want, got)
+	}
+}
+
+// decodeQR reads the text back from the code
+func decodeQR(t *testing.T, q *qrCode) string {
+	version := (q.size - 17) / 4
+	b := qrVersions[version-1]
+
+	var format int
+	for i := 14; i >= 9; i-- {
+		format = format<<1 | bit(q.dark(14-i, 8))
+	}
+	format = format<<1 | bit(q.dark(7, 8))
+	format = format<<1 | bit(q.dark(8, 8))
+	format = format<<1 | bit(q.dark(8, 7))
+	for i := 5; i >= 0; i-- {
+		format = format<<1 | bit(q.dark(8, i))
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("bad format %015b", format)
+	}
+
+	r := newQRCode(version)
+	for y := range r.modules {
+		copy(r.modules[y], q.modules[y])
+	}
+	r.applyMask(mask)
+	var bits qrBits
+	for right := r.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < r.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = r.size - 1 - vert
+				}
+				if !r.function[y][x] {
+					bits = append(bits, r.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := qrBits(bits[:len(bits)/8*8]).bytes()
+
+	n := b.count1 + b.count2
+	blocks := make([][]byte, n)
+	i := 0
+	for k := 0; k < b.data1 || k < b.data2; k++ {
+		for j := range blocks {
+			limit := b.data1
+			if j >= b.count1 {
+				limit = b.data2
+			}
+			if k < limit {
+				blocks[j] = append(blocks[j], raw[i])
+				i++
+			}
+		}
+	}
+	var data []byte
+	for j, block := range blocks {
+		ec := make([]byte, b.ec)
+		for k := range ec {
+			ec[k] = raw[i+k*n+j]
+		}
+		if got := rsRemainder(block, rsDivisor(b.ec)); !bytes.Equal(got, ec) {
+			t.Fatalf("block %d: error correction mismatch", j)
+		}
+		data = append(data, block...)
+	}
+
+	var stream qrBits
+	for _, c := range data {
+		stream.append(int(c), 8)
+	}
+	read := func(n int) (v int) {
+		for _, b := range stream[:n] {
+			v = v<<1 | bit(b)
+		}
+		stream = stream[n:]
+		return
+	}
+	if mode := read(4); mode != 4 {
+		t.Fatalf("mode %d", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	var s strings.Builder
+	for n := read(countBits); n > 0; n-- {
+		s.WriteByte(byte(read(8)))
+	}
+	return s.String()
+}
+
+func bit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestEncodeQR(t *testing.T) {
+	for _, tc := range []struct {
+		text    string
+		version int
+	}{
+		{"https://example.com/", 2},
+		{"https://podcasts.example.com/radiorus-57083.rss", 4},
+		{"https://podcasts.example.com/feeds/radiorus-57083.rss?token=" + strings.Repeat("x", 100), 9},
+		{"https://podcasts.example.com/" + strings.Repeat("x", 180), 10},
+	} {
+		q, err := encodeQR(tc.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (q.size - 17) / 4; got != tc.version {
+			t.Errorf("%s: want version %d, got %d", tc.text, tc.version, got)
+		}
+		if got := decodeQR(t, q); got != tc.text {
+			t.Errorf("want %s, got %s", tc.text, got)
+		}
+	}
+	if _, err := encodeQR(strings.Repeat("x", 300)); err == nil {
+		t.Error("no error for too long text")
+	}
+}
+
+func TestQRCapacity(t *testing.T) {
+	// the byte mode capacities at level M
+	for i, max := range []int{14, 26, 42, 62, 84, 106, 122, 152, 180, 213} {
+		for _, n := range []int{max, max + 1} {
+			q, err := encodeQR(strings.Repeat("x", n))
+			switch {
+			case n == max && (err != nil || q.size != 21+4*i):
+				t.Errorf("%d bytes: want version %d, got %v", n, i+1, err)
+			case n > max && err == nil && q.size == 21+4*i:
+				t.Errorf("%d bytes fit version %d", n, i+1)
+			}
+		}
+	}
+}
+
+func TestQRSVG(t *testing.T) {
+	q, err := encodeQR("https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := q.svg()
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 33 33"`) {
+		t.Errorf("got %s", svg)
+	}
+	// the top left finder pattern begins with 7 dark modules
+	if !strings.Contains(svg, `<path d="M4,4h1v1h-1zM5,4h1v1h-1zM6,4h1v1h-1zM7,4h1v1h-1zM8,4h1v1h-1zM9,4h1v1h-1zM10,4h1v1h-1z`) {
+		t.Errorf("no finder pattern in %s", svg)
+	}
+}
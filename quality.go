@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// audioVariants returns the audio IDs of the quality variants the
+// episode page offers, keyed "high" and "low"; the variants are the
+// audio elements marked with data-quality, either by name or by
+// bitrate
+func audioVariants(doc *goquery.Document) map[string]string {
+	variants := make(map[string]string)
+	byRate := make(map[int]string)
+
+	doc.Find(`[data-type="audio"][data-quality]`).Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("data-id")
+		if id == "" {
+			return
+		}
+		q, _ := s.Attr("data-quality")
+		switch q = strings.ToLower(strings.TrimSpace(q)); q {
+		case "high", "hq":
+			variants["high"] = id
+		case "low", "lq":
+			variants["low"] = id
+		default:
+			if rate, err := strconv.Atoi(strings.TrimSuffix(q, "k")); err == nil {
+				byRate[rate] = id
+			}
+		}
+	})
+
+	if len(byRate) > 1 {
+		var rates []int
+		for rate := range byRate {
+			rates = append(rates, rate)
+		}
+		sort.Ints(rates)
+		if _, ok := variants["low"]; !ok {
+			variants["low"] = byRate[rates[0]]
+		}
+		if _, ok := variants["high"]; !ok {
+			variants["high"] = byRate[rates[len(rates)-1]]
+		}
+	}
+	return variants
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAudioVariants(t *testing.T) {
+	tests := map[string]struct {
+		html string
+		want map[string]string
+	}{
+		"none": {
+			html: `<div data-type="audio" data-id="1"></div>`,
+			want: map[string]string{},
+		},
+		"named": {
+			html: `<div data-type="audio" data-id="1" data-quality="HQ"></div>
+<div data-type="audio" data-id="2" data-quality="low"></div>`,
+			want: map[string]string{"high": "1", "low": "2"},
+		},
+		"bitrates": {
+			html: `<div data-type="audio" data-id="1" data-quality="128k"></div>
+<div data-type="audio" data-id="2" data-quality="320k"></div>
+<div data-type="audio" data-id="3" data-quality="64"></div>`,
+			want: map[string]string{"high": "2", "low": "3"},
+		},
+		"single bitrate": {
+			html: `<div data-type="audio" data-id="1" data-quality="128k"></div>`,
+			want: map[string]string{},
+		},
+		"no id": {
+			html: `<div data-type="audio" data-quality="high"></div>`,
+			want: map[string]string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, err := newDocument([]byte(tc.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := audioVariants(doc); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
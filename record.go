@@ -0,0 +1,160 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/feeds"
+)
+
+var (
+	scriptRe = regexp.MustCompile(`(?is)<(script|noscript|iframe)\b[^>]*>.*?</(?:script|noscript|iframe)>`)
+	secretRe = regexp.MustCompile(`(?i)([?&](?:token|sig|signature|session|sessionid|sid|auth|key)=)[^&"'\s<>]*`)
+)
+
+func recordCmd(args []string) {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	smotrim := fs.Bool("smotrim", false, "use smotrim.ru directly")
+	dir := fs.String("dir", "testdata", "directory to write the fixtures to")
+	episodes := fs.Int("episodes", 3, "number of episode pages to record")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s record [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	files, err := recordBrand(context.Background(), brandURL(*brand, *smotrim), *dir, *episodes)
+	for _, f := range files {
+		fmt.Println(f)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// recordBrand saves sanitized copies of the brand's pages into dir,
+// laid out by the URL path so that http.FileServer serves them as the
+// site would; it returns the files written
+func recordBrand(ctx context.Context, brandURL, dir string, episodes int) (files []string, err error) {
+	record := func(u string) ([]byte, string, error) {
+		page, final, err := fetchRaw(ctx, u)
+		if err != nil {
+			return nil, final, err
+		}
+		file, err := saveFixture(dir, final, page)
+		if err != nil {
+			return nil, final, err
+		}
+		files = append(files, file)
+		return page, final, nil
+	}
+
+	page, final, err := record(brandURL)
+	if err != nil {
+		return files, err
+	}
+	feed := &brandFeed{Feed: feeds.Feed{Link: &feeds.Link{Href: final}}}
+	if err := populateFeed(feed, cleanText(page)); err != nil {
+		return files, fmt.Errorf("could not process %s: %w", final, err)
+	}
+
+	if parseSite(feed) != "smotrim.ru" {
+		if _, _, err := record(strings.TrimSuffix(final, "episodes") + "about"); err != nil {
+			return files, err
+		}
+	}
+	for i, item := range feed.Items {
+		if i == episodes {
+			break
+		}
+		if _, _, err := record(item.Link.Href); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// fetchRaw gets the page as is, with no entities replaced
+func fetchRaw(ctx context.Context, u string) ([]byte, string, error) {
+	if err := fetchLimiter.wait(ctx); err != nil {
+		return nil, u, err
+	}
+	req, err := newRequest(ctx, "GET", u)
+	if err != nil {
+		return nil, u, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, u, err
+	}
+	defer res.Body.Close()
+	final := res.Request.URL.String()
+	if res.StatusCode != http.StatusOK {
+		return nil, final, fmt.Errorf("%s: %s", final, res.Status)
+	}
+	page, err := ioutil.ReadAll(res.Body)
+	return page, final, err
+}
+
+// saveFixture writes the sanitized page to dir at its URL path
+func saveFixture(dir, pageURL string, page []byte) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	p := path.Clean("/" + u.Path)
+	if p == "/" {
+		p = "/index.html"
+	}
+	name := filepath.Join(dir, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return "", err
+	}
+	return name, ioutil.WriteFile(name, sanitizePage(page, u.Host), 0644)
+}
+
+// sanitizePage drops the scripts and embedded frames, the session
+// tokens and the like, and makes the links to the site relative, so
+// that the page can be served from a test server
+func sanitizePage(page []byte, host string) []byte {
+	page = scriptRe.ReplaceAll(page, nil)
+	page = secretRe.ReplaceAll(page, []byte("${1}x"))
+	hosts := []string{host}
+	if h := strings.TrimPrefix(host, "www."); h != host {
+		hosts = append(hosts, h)
+	} else {
+		hosts = append(hosts, "www."+host)
+	}
+	for _, h := range hosts {
+		for _, prefix := range []string{"https://", "http://", "//"} {
+			page = []byte(strings.ReplaceAll(string(page), `"`+prefix+h+`/`, `"/`))
+		}
+	}
+	return page
+}
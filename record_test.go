@@ -0,0 +1,76 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordBrand(t *testing.T) {
+	server := helperMockServer(t)
+	defer server.Close()
+	defer helperCleanupServer(t)
+
+	dir, err := ioutil.TempDir("", "radiorus-record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := recordBrand(context.Background(), server.URL+"/brand/57083/episodes", dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := "brand/57083/episodes brand/57083/about brand/57083/episode/2237849 brand/57083/episode/2237781"
+	if strings.Join(got, " ") != want {
+		t.Errorf("want %s, got %s", want, strings.Join(got, " "))
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "brand", "57083", "episodes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "<script") {
+		t.Error("scripts not removed")
+	}
+	if !strings.Contains(string(b), `<a href="/brand/57083/episode/2237849"`) {
+		t.Error("episode links lost")
+	}
+}
+
+func TestSanitizePage(t *testing.T) {
+	page := `<html><head><script src="x.js"></script><SCRIPT>var a = "<b>";</SCRIPT></head>
+<body><a href="https://www.radiorus.ru/brand/1">a</a> <img src="//radiorus.ru/i.png">
+<a href="https://cdn.example.com/x?id=1&token=abc123">b</a><iframe src="https://ads"></iframe>
+<!-- если есть аудио[ --></body></html>`
+	want := `<html><head></head>
+<body><a href="/brand/1">a</a> <img src="/i.png">
+<a href="https://cdn.example.com/x?id=1&token=x">b</a>
+<!-- если есть аудио[ --></body></html>`
+	if got := string(sanitizePage([]byte(page), "www.radiorus.ru")); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+// reportFile is where the JSON report of the run is written to,
+// no report is written if empty
+var reportFile string
+
+// brandReport is what happened to a brand during the run
+type brandReport struct {
+	Brand       string         `json:"brand"`
+	Output      string         `json:"output,omitempty"`
+	Started     time.Time      `json:"started"`
+	Duration    float64        `json:"duration_seconds"`
+	Pages       int            `json:"pages_fetched"`
+	Layout      string         `json:"layout,omitempty"`
+	Episodes    int            `json:"episodes"`
+	NewEpisodes int            `json:"new_episodes"`
+	Mirrored    []mirroredFile `json:"mirrored,omitempty"`
+	GeoBlocked  []string       `json:"geo_blocked,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+	Error       string         `json:"error,omitempty"`
+
+	newTitles []string
+	mu        sync.Mutex
+}
+
+// runReport is the report written to the -report file
+type runReport struct {
+	Finished time.Time      `json:"finished"`
+	Brands   []*brandReport `json:"brands"`
+}
+
+type reportKey struct{}
+
+func newBrandReport(brand string) *brandReport {
+	return &brandReport{Brand: brand, Started: time.Now()}
+}
+
+// withReport makes the context carry the report of the brand being
+// generated, so that the fetches and warnings are recorded in it
+func withReport(ctx context.Context, r *brandReport) context.Context {
+	return context.WithValue(ctx, reportKey{}, r)
+}
+
+func reportFrom(ctx context.Context) *brandReport {
+	r, _ := ctx.Value(reportKey{}).(*brandReport)
+	return r
+}
+
+func (r *brandReport) addPage() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.Pages++
+	r.mu.Unlock()
+}
+
+// addGeoBlocked records the audio the geo-blocking stub was served for
+func (r *brandReport) addGeoBlocked(u string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.GeoBlocked = append(r.GeoBlocked, u)
+	r.mu.Unlock()
+}
+
+// setLayout records the programme page layout recognised
+func (r *brandReport) setLayout(layout string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.Layout = layout
+	r.mu.Unlock()
+}
+
+// finish records the outcome of the brand generation
+func (r *brandReport) finish(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = time.Since(r.Started).Seconds()
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// writeReport writes the report of the brands to the file
+func writeReport(filename string, reports []*brandReport) error {
+	for _, r := range reports {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+	}
+	b, err := json.MarshalIndent(runReport{Finished: time.Now(), Brands: reports}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(filename, b)
+}
+
+// newItems returns the items that were not in the old feed
+func newItems(feed *brandFeed, old *feeds.RssFeed) (items []*feedItem) {
+	seen := make(map[string]bool, len(old.Items))
+	for _, item := range old.Items {
+		seen[item.Guid] = true
+	}
+	for _, item := range feed.Items {
+		if !seen[item.Id] {
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestBrandReport(t *testing.T) {
+	server := helperMockServer(t)
+	defer helperCleanupFile(t, "episodes")
+	helperCleanupFile(t, "about")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer func() { log.SetOutput(os.Stderr) }()
+
+	rep := newBrandReport("57083")
+	feed, err := processBrand(withReport(context.Background(), rep), fmt.Sprintf("%s/brand/57083/episodes", server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.finish(errors.New("oops"))
+
+	// the listing, the missing about page and the episodes
+	if want := 2 + len(feed.Items); rep.Pages != want {
+		t.Errorf("want %d pages fetched, got %d", want, rep.Pages)
+	}
+	if len(rep.Warnings) == 0 || !strings.Contains(rep.Warnings[0], "could not find programme description") {
+		t.Errorf("warning not recorded: %v", rep.Warnings)
+	}
+	if !strings.Contains(buf.String(), rep.Warnings[0]) {
+		t.Error("warning not logged")
+	}
+	if rep.Error != "oops" {
+		t.Errorf("want error recorded, got %q", rep.Error)
+	}
+
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "report.json")
+	if err := writeReport(file, []*brandReport{rep}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got runReport
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Brands) != 1 || got.Brands[0].Brand != "57083" || got.Brands[0].Pages != rep.Pages {
+		t.Errorf("bad report %s", b)
+	}
+}
+
+func TestNewItems(t *testing.T) {
+	feed := &brandFeed{}
+	feed.Add(&feedItem{Item: &feeds.Item{Id: "1"}})
+	feed.Add(&feedItem{Item: &feeds.Item{Id: "2"}})
+	old := &feeds.RssFeed{Items: []*feeds.RssItem{{Guid: "1"}}}
+	if got := newItems(feed, old); len(got) != 1 || got[0].Id != "2" {
+		t.Errorf("want item 2, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// reruns is what to do with the repeat broadcasts of the episodes
+// already in the feed: keep them, drop them, or mark their titles
+var reruns = "keep"
+
+const rerunMark = " (повтор)"
+
+// findReruns returns the items that repeat an earlier item in the list,
+// either by audio ID or by title
+func findReruns(items []*feedItem) map[*feedItem]bool {
+	byDate := make([]*feedItem, len(items))
+	copy(byDate, items)
+	sort.SliceStable(byDate, func(i, j int) bool { return byDate[i].Created.Before(byDate[j].Created) })
+
+	repeats := make(map[*feedItem]bool)
+	seenIDs, seenTitles := make(map[string]bool), make(map[string]bool)
+	for _, item := range byDate {
+		id, title := audioID(item), rerunTitle(item.Title)
+		if (id != "" && seenIDs[id]) || (title != "" && seenTitles[title]) {
+			repeats[item] = true
+		}
+		seenIDs[id], seenTitles[title] = true, true
+	}
+	return repeats
+}
+
+// rerunTitle brings the title to the form the reruns are compared in:
+// lower case letters and digits only, without the rerun mark
+func rerunTitle(title string) string {
+	title = strings.TrimSuffix(strings.TrimSpace(title), strings.TrimSpace(rerunMark))
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+func withoutReruns(items []*feedItem, repeats map[*feedItem]bool) []*feedItem {
+	var kept []*feedItem
+	for _, item := range items {
+		if !repeats[item] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// markReruns appends the rerun mark to the titles of the repeats
+func markReruns(items []*feedItem, repeats map[*feedItem]bool) {
+	for _, item := range items {
+		if repeats[item] && !strings.HasSuffix(item.Title, rerunMark) {
+			item.Title += rerunMark
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestReruns(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 14, 10, 0, 0, moscow) }
+	audio := func(id string) *feeds.Enclosure {
+		return &feeds.Enclosure{Url: "https://audio.vgtrk.com/download?id=" + id}
+	}
+	items := []*feedItem{
+		{Item: &feeds.Item{Title: "Новые имена 27", Created: day(26), Enclosure: audio("4")}},
+		{Item: &feeds.Item{Title: "«The Cure»", Created: day(19), Enclosure: audio("3")}},
+		{Item: &feeds.Item{Title: "Новые имена", Created: day(12), Enclosure: audio("1")}},
+		{Item: &feeds.Item{Title: "The Cure.", Created: day(5), Enclosure: audio("2")}},
+		{Item: &feeds.Item{Title: "Новые имена", Created: day(1), Enclosure: audio("1")}},
+	}
+
+	repeats := findReruns(items)
+	want := map[*feedItem]bool{items[1]: true, items[2]: true}
+	if !reflect.DeepEqual(repeats, want) {
+		t.Errorf("want %v, got %v", want, repeats)
+	}
+
+	if got := withoutReruns(items, repeats); !reflect.DeepEqual(got, []*feedItem{items[0], items[3], items[4]}) {
+		t.Errorf("reruns not dropped: %v", itemTitles(got))
+	}
+
+	markReruns(items, repeats)
+	markReruns(items, repeats)
+	wantTitles := []string{"Новые имена 27", "«The Cure» (повтор)", "Новые имена (повтор)", "The Cure.", "Новые имена"}
+	if got := itemTitles(items); !reflect.DeepEqual(got, wantTitles) {
+		t.Errorf("want %q, got %q", wantTitles, got)
+	}
+}
+
+func TestRerunTitle(t *testing.T) {
+	for _, title := range []string{"«Аэростат»: Новые имена", `"Аэростат". Новые  имена (повтор)`, "аэростат, новые имена"} {
+		if got, want := rerunTitle(title), "аэростат новые имена"; got != want {
+			t.Errorf("%q: want %q, got %q", title, want, got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryParams are the query parameters CDNs put the link expiry
+// time (Unix seconds) in
+var expiryParams = []string{"expires", "Expires", "exp", "e"}
+
+// signatureParams are the query parameters CDNs sign the links with
+var signatureParams = []string{"md5", "sign", "signature", "sig", "token", "hash", "s"}
+
+// signedURL is true for the links that are signed or expire: they stop
+// working after a while, so they are not to be put in the feed
+func signedURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	q := parsed.Query()
+	for _, p := range append(append([]string{}, expiryParams...), signatureParams...) {
+		if q.Get(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvedEnclosure is the final location of the enclosure
+type resolvedEnclosure struct {
+	URL      string    `json:"url"`
+	Length   string    `json:"length,omitempty"`
+	Resolved time.Time `json:"resolved"`
+}
+
+// expired is true if the resolved URL is to be refreshed: it is older
+// than resolveTTL or the link is about to expire
+func (r resolvedEnclosure) expired(now time.Time) bool {
+	if now.Sub(r.Resolved) > resolveTTL {
+		return true
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return true
+	}
+	q := u.Query()
+	for _, p := range expiryParams {
+		if exp, err := strconv.ParseInt(q.Get(p), 10, 64); err == nil && exp > 0 {
+			return now.Add(time.Hour).Unix() >= exp
+		}
+	}
+	return false
+}
+
+// resolveCacheFile returns the file the brand's resolved enclosures
+// are kept in between runs
+func resolveCacheFile(path, brand string) string {
+	return filepath.Join(path, "."+brand+".enclosures.json")
+}
+
+// resolveEnclosures replaces the enclosure URLs of the items with the
+// ones they redirect to, reusing the previously resolved ones until
+// they expire; the items that fail to resolve keep the original URL,
+// and so do the ones that redirect to signed URLs, only getting the
+// size from them
+func resolveEnclosures(ctx context.Context, items []*feedItem, cacheFile string) {
+	cache := make(map[string]resolvedEnclosure)
+	if b, err := ioutil.ReadFile(cacheFile); err == nil {
+		if err := json.Unmarshal(b, &cache); err != nil {
+			warnf(ctx, "ignoring %s: %v", cacheFile, err)
+		}
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		now = time.Now()
+	)
+	fresh := make(map[string]resolvedEnclosure)
+	for _, item := range items {
+		if item.Enclosure == nil || item.Enclosure.Url == "" {
+			continue
+		}
+		original := item.Enclosure.Url
+		if r, ok := cache[original]; ok && !r.expired(now) {
+			fresh[original] = r
+			continue
+		}
+
+		wg.Add(1)
+		go func(original string) {
+			defer wg.Done()
+			r, err := resolveEnclosure(ctx, original)
+			if err != nil {
+				warnf(ctx, "could not resolve %s: %v", original, err)
+				noteGeoBlocked(ctx, original, err)
+				return
+			}
+			mu.Lock()
+			fresh[original] = r
+			mu.Unlock()
+		}(original)
+	}
+	wg.Wait()
+
+	for _, item := range items {
+		if item.Enclosure == nil {
+			continue
+		}
+		if r, ok := fresh[item.Enclosure.Url]; ok {
+			if !signedURL(r.URL) {
+				item.Enclosure.Url = r.URL
+			}
+			if r.Length != "" {
+				item.Enclosure.Length = r.Length
+			}
+		}
+	}
+
+	b, err := json.MarshalIndent(fresh, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(cacheFile, b, 0644)
+	}
+	if err != nil {
+		log.Printf("could not save %s: %v", cacheFile, err)
+	}
+}
+
+// resolveEnclosure follows the redirects from the URL and returns
+// the final location along with its size if known
+func resolveEnclosure(ctx context.Context, u string) (r resolvedEnclosure, err error) {
+	if err = fetchLimiter.wait(ctx); err != nil {
+		return
+	}
+	if err = politeWait(ctx, u); err != nil {
+		return
+	}
+	res, err := audioRequest(ctx, "HEAD", u)
+	if err == nil && res.StatusCode == http.StatusMethodNotAllowed {
+		res, err = audioRequest(ctx, "GET", u)
+	}
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return r, fmt.Errorf("%s", res.Status)
+	}
+	head, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+	if geoStubContent(res.Request.URL.String(), res.Header.Get("Content-Type"), head) {
+		return r, errGeoBlocked
+	}
+
+	r = resolvedEnclosure{URL: res.Request.URL.String(), Resolved: time.Now()}
+	if cr := res.Header.Get("Content-Range"); strings.Contains(cr, "/") {
+		r.Length = cr[strings.LastIndex(cr, "/")+1:]
+	} else if res.ContentLength > 0 {
+		r.Length = strconv.FormatInt(res.ContentLength, 10)
+	}
+	if r.Length == "*" {
+		r.Length = ""
+	}
+	return
+}
+
+// audioRequest requests the audio file, only the first byte of it
+// for GET
+func audioRequest(ctx context.Context, method, u string) (*http.Response, error) {
+	req, err := newRequest(ctx, method, u)
+	if err != nil {
+		return nil, err
+	}
+	if method == "GET" {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	return httpClient.Do(req)
+}
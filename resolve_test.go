@@ -0,0 +1,170 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func helperCDN(t *testing.T, hits *int32) *httptest.Server {
+	t.Helper()
+	expires := time.Now().Add(48 * time.Hour).Unix()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download":
+			atomic.AddInt32(hits, 1)
+			switch id := r.URL.Query().Get("id"); id {
+			case "1":
+				http.Redirect(w, r, "/cdn/1.mp3", http.StatusFound)
+			case "2":
+				http.Redirect(w, r, fmt.Sprintf("/cdn/2.mp3?expires=%d&md5=abc", expires), http.StatusFound)
+			default:
+				http.NotFound(w, r)
+			}
+		case "/cdn/1.mp3":
+			w.Header().Set("Content-Length", "12345")
+		case "/cdn/2.mp3":
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("unexpected range %q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/5000")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte{0})
+		}
+	}))
+}
+
+func TestResolveEnclosures(t *testing.T) {
+	var hits int32
+	server := helperCDN(t, &hits)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := resolveCacheFile(dir, "57083")
+
+	items := func() []*feedItem {
+		var items []*feedItem
+		for id := 1; id <= 3; id++ {
+			items = append(items, &feedItem{Item: &feeds.Item{Enclosure: &feeds.Enclosure{
+				Url:    server.URL + "/download?id=" + strconv.Itoa(id),
+				Length: "1024",
+				Type:   "audio/mpeg",
+			}}})
+		}
+		return append(items, &feedItem{Item: &feeds.Item{Enclosure: &feeds.Enclosure{}}})
+	}
+
+	check := func(items []*feedItem) {
+		t.Helper()
+		want := []struct{ path, length string }{
+			{"/cdn/1.mp3", "12345"},
+			// the signed one is not put in the feed
+			{"/download", "5000"},
+			{"/download", "1024"},
+		}
+		for i, w := range want {
+			e := items[i].Enclosure
+			if e.Url[len(server.URL):len(server.URL)+len(w.path)] != w.path || e.Length != w.length {
+				t.Errorf("item %d: want %s (%s), got %s (%s)", i+1, w.path, w.length, e.Url, e.Length)
+			}
+		}
+		if items[3].Enclosure.Url != "" {
+			t.Error("empty enclosure resolved")
+		}
+	}
+
+	first := items()
+	resolveEnclosures(context.Background(), first, cache)
+	check(first)
+	// the second one is requested twice: HEAD, then GET
+	if hits != 4 {
+		t.Errorf("want 4 requests, got %d", hits)
+	}
+
+	// resolved ones are reused, failed ones retried
+	second := items()
+	resolveEnclosures(context.Background(), second, cache)
+	check(second)
+	if hits != 5 {
+		t.Errorf("want 1 more request, got %d", hits-4)
+	}
+
+	defer func(ttl time.Duration) { resolveTTL = ttl }(resolveTTL)
+	resolveTTL = 0
+	resolveEnclosures(context.Background(), items(), cache)
+	if hits != 9 {
+		t.Errorf("want all resolved again after TTL, got %d requests", hits-5)
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://audio.vgtrk.com/download?id=2237849":                 false,
+		"https://cdn.example.com/1.mp3":                               false,
+		"https://cdn.example.com/1.mp3?expires=1700000000":            true,
+		"https://cdn.example.com/1.mp3?md5=abc&e=1700000000":          true,
+		"https://vgtrk-podcast.cdnvideo.ru/audio/1.mp3?token=abc&x=1": true,
+	}
+	for u, want := range tests {
+		if got := signedURL(u); got != want {
+			t.Errorf("%s: want %v, got %v", u, want, got)
+		}
+	}
+}
+
+func TestResolvedExpired(t *testing.T) {
+	defer func(ttl time.Duration) { resolveTTL = ttl }(resolveTTL)
+	resolveTTL = 24 * time.Hour
+	now := time.Now()
+
+	testdata := []struct {
+		url      string
+		resolved time.Time
+		want     bool
+	}{
+		{"https://cdn.example.com/1.mp3", now.Add(-time.Hour), false},
+		{"https://cdn.example.com/1.mp3", now.Add(-25 * time.Hour), true},
+		{fmt.Sprintf("https://cdn.example.com/1.mp3?expires=%d", now.Add(3*time.Hour).Unix()), now, false},
+		{fmt.Sprintf("https://cdn.example.com/1.mp3?e=%d", now.Add(30*time.Minute).Unix()), now, true},
+		{"https://cdn.example.com/1.mp3?e=garbage", now, false},
+	}
+
+	for _, tc := range testdata {
+		r := resolvedEnclosure{URL: tc.url, Resolved: tc.resolved}
+		if got := r.expired(now); got != tc.want {
+			t.Errorf("%s resolved %v ago: want %v, got %v", tc.url, now.Sub(tc.resolved), tc.want, got)
+		}
+	}
+}
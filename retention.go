@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// mirrorKeep is how many latest episodes of each brand to keep in
+	// the mirror, all of them if zero
+	mirrorKeep int
+	// mirrorMaxSize is how many bytes each brand's mirror may take,
+	// no limit if zero
+	mirrorMaxSize int64
+	// mirrorSince is the date the episodes are kept in the mirror
+	// from, mirrorAfter is it parsed
+	mirrorSince string
+	mirrorAfter time.Time
+)
+
+// retaining tells if any of the mirror retention options is set
+func retaining() bool {
+	return mirrorKeep > 0 || mirrorMaxSize > 0 || !mirrorAfter.IsZero()
+}
+
+// retain returns the audio ids the retention options keep in the
+// mirror, in the same order; the newest episodes are kept first
+func retain(ctx context.Context, ids []string, byID map[string][]*feedItem, store mirrorStore) []string {
+	if !retaining() {
+		return ids
+	}
+
+	// a rerun makes the audio new again
+	dates := make(map[string]time.Time, len(ids))
+	for _, id := range ids {
+		for _, item := range byID[id] {
+			if item.Created.After(dates[id]) {
+				dates[id] = item.Created
+			}
+		}
+	}
+	newest := append([]string(nil), ids...)
+	sort.SliceStable(newest, func(i, j int) bool {
+		return dates[newest[i]].After(dates[newest[j]])
+	})
+
+	keep := make(map[string]bool)
+	var total int64
+	for n, id := range newest {
+		if mirrorKeep > 0 && n >= mirrorKeep {
+			break
+		}
+		if !mirrorAfter.IsZero() && dates[id].Before(mirrorAfter) {
+			break
+		}
+		if mirrorMaxSize > 0 {
+			if total += audioSize(ctx, store, byID[id][0], id+".mp3"); total > mirrorMaxSize {
+				break
+			}
+		}
+		keep[id] = true
+	}
+
+	var kept []string
+	for _, id := range ids {
+		if keep[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// audioSize is the size of the mirrored file, or the one the item's
+// enclosure states if it is not downloaded yet
+func audioSize(ctx context.Context, store mirrorStore, item *feedItem, name string) int64 {
+	if size, _, err := store.stat(ctx, name); err == nil {
+		return size
+	}
+	if item.Enclosure == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(item.Enclosure.Length, 10, 64)
+	return n
+}
+
+// pruneMirror removes the audio files that are not among the kept ids
+// from the store, and the partial downloads of them, and their
+// checksums from sums
+func pruneMirror(ctx context.Context, store mirrorStore, kept []string, sums map[string]string) {
+	keep := make(map[string]bool, len(kept))
+	for _, id := range kept {
+		keep[id+".mp3"] = true
+	}
+	files, err := store.list(ctx)
+	if err != nil {
+		warnf(ctx, "could not list the mirror: %v", err)
+		return
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".mp3") || keep[file] {
+			continue
+		}
+		if err := store.remove(ctx, file); err != nil {
+			warnf(ctx, "could not prune %s: %v", file, err)
+			continue
+		}
+		delete(sums, file)
+		noticef("pruned %s from the mirror", file)
+	}
+	for file := range sums {
+		if strings.HasSuffix(file, ".mp3") && !keep[file] {
+			delete(sums, file)
+		}
+	}
+
+	parts, _ := filepath.Glob(filepath.Join(store.dir(), ".*.mp3.part"))
+	for _, part := range parts {
+		file := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(part), "."), ".part")
+		if keep[file] {
+			continue
+		}
+		if err := os.Remove(part); err != nil {
+			warnf(ctx, "could not prune %s: %v", part, err)
+		}
+	}
+}
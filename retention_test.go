@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestRetain(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, moscow) }
+	byID := map[string][]*feedItem{
+		"1": {{Item: &feeds.Item{Created: day(1), Enclosure: &feeds.Enclosure{Length: "100"}}}},
+		"2": {{Item: &feeds.Item{Created: day(2), Enclosure: &feeds.Enclosure{Length: "100"}}}},
+		// rerun of the oldest one on the latest day
+		"3": {{Item: &feeds.Item{Created: day(3), Enclosure: &feeds.Enclosure{Length: "100"}}}, {Item: &feeds.Item{Created: day(5)}}},
+		"4": {{Item: &feeds.Item{Created: day(4), Enclosure: &feeds.Enclosure{Length: "100"}}}},
+	}
+	ids := []string{"1", "2", "3", "4"}
+
+	defer func(k int, s int64, a time.Time) { mirrorKeep, mirrorMaxSize, mirrorAfter = k, s, a }(mirrorKeep, mirrorMaxSize, mirrorAfter)
+	for _, tc := range []struct {
+		keep  int
+		size  int64
+		after time.Time
+		want  []string
+	}{
+		{want: ids},
+		{keep: 2, want: []string{"3", "4"}},
+		{size: 250, want: []string{"3", "4"}},
+		{size: 300, want: []string{"2", "3", "4"}},
+		{after: day(2), want: []string{"2", "3", "4"}},
+		{keep: 3, after: day(4), want: []string{"3", "4"}},
+		{keep: 10, size: 50},
+	} {
+		mirrorKeep, mirrorMaxSize, mirrorAfter = tc.keep, tc.size, tc.after
+		if got := retain(context.Background(), ids, byID, dirStore("")); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%+v: got %v", tc, got)
+		}
+	}
+}
+
+func TestMirrorRetention(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("audio " + r.URL.Query().Get("id")))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(p, f string) { outputPath, ffprobe = p, f }(outputPath, ffprobe)
+	outputPath, ffprobe = dir, ""
+	defer func(u string) { mirrorURL = u }(mirrorURL)
+	mirrorURL = "https://example.com/audio/"
+	defer func(k int) { mirrorKeep = k }(mirrorKeep)
+	mirrorKeep = 0
+
+	item := func(id string, d int) *feedItem {
+		return &feedItem{Item: &feeds.Item{
+			Title:     id,
+			Link:      &feeds.Link{},
+			Created:   time.Date(2026, 3, d, 12, 0, 0, 0, moscow),
+			Enclosure: &feeds.Enclosure{Url: ts.URL + "/download?id=" + id, Type: "audio/mpeg", Length: "0"},
+		}}
+	}
+	brandDir := filepath.Join(dir, "57083")
+	items := []*feedItem{item("2", 2), item("1", 1)}
+	if files := mirrorAudio(context.Background(), items, dirStore(brandDir)); len(files) != 2 {
+		t.Fatalf("got %+v", files)
+	}
+	if err := ioutil.WriteFile(filepath.Join(brandDir, ".0.mp3.part"), []byte("au"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mirrorKeep = 1
+	items = []*feedItem{item("3", 3), item("2", 2), item("1", 1)}
+	files := mirrorAudio(context.Background(), items, dirStore(brandDir))
+	if len(files) != 1 || files[0].File != "3.mp3" {
+		t.Fatalf("got %+v", files)
+	}
+	left, _ := filepath.Glob(filepath.Join(brandDir, "*"))
+	hidden, _ := filepath.Glob(filepath.Join(brandDir, ".*"))
+	want := []string{filepath.Join(brandDir, "3.mp3"), filepath.Join(brandDir, sumsName)}
+	if !reflect.DeepEqual(append(left, hidden...), want) {
+		t.Errorf("want %v, got %v", want, append(left, hidden...))
+	}
+	b, err := ioutil.ReadFile(filepath.Join(brandDir, sumsName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != files[0].SHA256+"  3.mp3\n" {
+		t.Errorf("got checksums %q", b)
+	}
+	for _, item := range items[1:] {
+		if item.mirrored != nil {
+			t.Errorf("%s still mirrored", item.Title)
+		}
+	}
+}
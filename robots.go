@@ -0,0 +1,181 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsAgent is the name the program goes by in robots.txt
+const robotsAgent = "radiorus-rss"
+
+var (
+	// polite makes the fetches obey robots.txt of the sites
+	polite bool
+
+	errDisallowed = errors.New("disallowed by robots.txt")
+
+	robotsMu    sync.Mutex
+	robotsHosts = make(map[string]*robotsRules)
+)
+
+// robotsRules are the robots.txt rules that apply to the program on a
+// host; the limiter enforces the crawl delay
+type robotsRules struct {
+	allow, disallow []string
+	delay           *limiter
+}
+
+// politeWait checks that robots.txt allows fetching the URL and waits
+// for the crawl delay of its host
+func politeWait(ctx context.Context, u string) error {
+	if !polite {
+		return nil
+	}
+	pu, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	rules := robotsFor(ctx, pu)
+	if !rules.allowed(pu.EscapedPath()) {
+		return fmt.Errorf("%s: %w", u, errDisallowed)
+	}
+	return rules.delay.wait(ctx)
+}
+
+// robotsFor returns the rules for the host of the URL, fetching its
+// robots.txt the first time; a host without robots.txt allows all
+func robotsFor(ctx context.Context, u *url.URL) *robotsRules {
+	host := u.Scheme + "://" + u.Host
+	robotsMu.Lock()
+	defer robotsMu.Unlock()
+	if r, ok := robotsHosts[host]; ok {
+		return r
+	}
+
+	r := &robotsRules{}
+	if b, err := fetchRobots(ctx, host+"/robots.txt"); err == nil {
+		r = parseRobots(b, robotsAgent)
+	}
+	robotsHosts[host] = r
+	return r
+}
+
+func fetchRobots(ctx context.Context, u string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := newRequest(ctx, "GET", u)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("%s: %s", u, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// parseRobots picks the rules for the agent from robots.txt, falling
+// back to the ones for all agents
+func parseRobots(b []byte, agent string) *robotsRules {
+	var (
+		groups  = make(map[string]*robotsRules)
+		current []*robotsRules
+		inAgent bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		if field == "user-agent" {
+			if !inAgent {
+				current = nil
+			}
+			inAgent = true
+			name := strings.ToLower(value)
+			if groups[name] == nil {
+				groups[name] = &robotsRules{}
+			}
+			current = append(current, groups[name])
+			continue
+		}
+		inAgent = false
+		for _, r := range current {
+			switch field {
+			case "allow":
+				if value != "" {
+					r.allow = append(r.allow, value)
+				}
+			case "disallow":
+				if value != "" {
+					r.disallow = append(r.disallow, value)
+				}
+			case "crawl-delay":
+				if d, err := strconv.ParseFloat(value, 64); err == nil && d > 0 {
+					r.delay = newLimiter(1 / d)
+				}
+			}
+		}
+	}
+
+	if r, ok := groups[strings.ToLower(agent)]; ok {
+		return r
+	}
+	if r, ok := groups["*"]; ok {
+		return r
+	}
+	return &robotsRules{}
+}
+
+// allowed tells whether the path may be fetched: the longest matching
+// rule wins, Allow wins a tie; only prefix rules are supported
+func (r *robotsRules) allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	longest := func(rules []string) (n int) {
+		for _, rule := range rules {
+			if strings.HasPrefix(path, rule) && len(rule) > n {
+				n = len(rule)
+			}
+		}
+		return
+	}
+	return longest(r.allow) >= longest(r.disallow)
+}
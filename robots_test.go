@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	txt := []byte(`# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+User-agent: other
+Disallow: /brand/   # no brands
+Allow: /brand/57083/
+Crawl-delay: 2
+`)
+	tests := []struct {
+		agent, path string
+		want        bool
+	}{
+		{"radiorus-rss", "/", true},
+		{"radiorus-rss", "/brand/1/episodes", false},
+		{"radiorus-rss", "/brand/57083/episodes", true},
+		{"googlebot", "/", false},
+		{"other", "/brand/1", false},
+	}
+	for _, tc := range tests {
+		r := parseRobots(txt, tc.agent)
+		if got := r.allowed(tc.path); got != tc.want {
+			t.Errorf("%s %s: want %v, got %v", tc.agent, tc.path, tc.want, got)
+		}
+	}
+
+	if r := parseRobots(txt, "radiorus-rss"); r.delay == nil || r.delay.interval != 2*time.Second {
+		t.Errorf("want 2s crawl delay, got %+v", r.delay)
+	}
+	if r := parseRobots(nil, "radiorus-rss"); !r.allowed("/anything") {
+		t.Error("empty robots.txt disallows")
+	}
+}
+
+func TestPolite(t *testing.T) {
+	var robotsHits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsHits++
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\nCrawl-delay: 0.05\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	defer func(p bool) { polite = p }(polite)
+	polite = true
+	robotsHosts = make(map[string]*robotsRules)
+	defer func() { robotsHosts = make(map[string]*robotsRules) }()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, _, err := fetchPage(context.Background(), server.URL+"/public"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("crawl delay not respected: 3 fetches in %v", d)
+	}
+	if _, _, err := fetchPage(context.Background(), server.URL+"/private/page"); !errors.Is(err, errDisallowed) {
+		t.Errorf("want disallowed, got %v", err)
+	}
+	if robotsHits != 1 {
+		t.Errorf("want robots.txt fetched once, got %d", robotsHits)
+	}
+}
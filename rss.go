@@ -0,0 +1,187 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+const (
+	atomNS = "http://www.w3.org/2005/Atom"
+	fhNS   = "http://purl.org/syndication/history/1.0"
+)
+
+// rssDoc wraps the RSS document gorilla/feeds produces so that elements
+// it does not support can be added, an rssDoc with no extensions
+// marshals exactly like the original
+type rssDoc struct {
+	XMLName          xml.Name `xml:"rss"`
+	Version          string   `xml:"version,attr"`
+	ContentNamespace string   `xml:"xmlns:content,attr"`
+	AtomNamespace    string   `xml:"xmlns:atom,attr,omitempty"`
+	FHNamespace      string   `xml:"xmlns:fh,attr,omitempty"`
+	ItunesNamespace  string   `xml:"xmlns:itunes,attr,omitempty"`
+	DCNamespace      string   `xml:"xmlns:dc,attr,omitempty"`
+	PodcastNamespace string   `xml:"xmlns:podcast,attr,omitempty"`
+	Lang             string   `xml:"xml:lang,attr,omitempty"`
+	Channel          *rssChannel
+}
+
+type rssChannel struct {
+	*feeds.RssFeed
+	ItunesAuthor   string          `xml:"itunes:author,omitempty"`
+	ItunesImage    *itunesImage    `xml:"itunes:image"`
+	ItunesCategory *itunesCategory `xml:"itunes:category"`
+	ItunesExplicit string          `xml:"itunes:explicit,omitempty"`
+	PodcastGUID    string          `xml:"podcast:guid,omitempty"`
+	Funding        *podcastFunding `xml:"podcast:funding"`
+	People         []podcastPerson `xml:"podcast:person"`
+	Archive        *struct{}       `xml:"fh:archive"`
+	AtomLinks      []atomLink      `xml:"atom:link"`
+	Items          []*rssItem      `xml:"item"`
+}
+
+type rssItem struct {
+	*feeds.RssItem
+	Categories          []string                     `xml:"category"`
+	Creator             string                       `xml:"dc:creator,omitempty"`
+	ItunesAuthor        string                       `xml:"itunes:author,omitempty"`
+	People              []podcastPerson              `xml:"podcast:person"`
+	AlternateEnclosures []*podcastAlternateEnclosure `xml:"podcast:alternateEnclosure"`
+	ItunesSeason        int                          `xml:"itunes:season,omitempty"`
+	ItunesDuration      int                          `xml:"itunes:duration,omitempty"`
+	AtomUpdated         string                       `xml:"atom:updated,omitempty"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// extension adds elements to the RSS document
+type extension func(doc *rssDoc)
+
+func newRssDoc(feed *brandFeed) *rssDoc {
+	rf := (&feeds.Rss{Feed: feed.plain()}).RssFeed()
+	ch := &rssChannel{RssFeed: rf}
+	var podcast, itunes, atom bool
+	for i, item := range rf.Items {
+		if item.Enclosure != nil && item.Enclosure.Url == "" {
+			item.Enclosure = nil
+		}
+		e := feed.Items[i]
+		ri := &rssItem{RssItem: item, Categories: e.categories, People: creditedPeople(e.Description)}
+		if m := e.mirrored; m != nil && m.url != "" && item.Enclosure != nil {
+			ri.AlternateEnclosures = append(ri.AlternateEnclosures, mirrorEnclosure(*m, item.Enclosure.Type))
+		}
+		for _, p := range e.parts {
+			ri.AlternateEnclosures = append(ri.AlternateEnclosures, partEnclosure(p))
+		}
+		if ri.ItunesDuration = e.duration; ri.ItunesDuration != 0 {
+			itunes = true
+		}
+		if ri.ItunesSeason = e.season; ri.ItunesSeason != 0 {
+			itunes = true
+		}
+		if updated := e.Updated; !updated.IsZero() {
+			ri.AtomUpdated = updated.Format(time.RFC3339)
+			atom = true
+		}
+		ch.Items = append(ch.Items, ri)
+		if len(ri.People) != 0 || len(ri.AlternateEnclosures) != 0 {
+			podcast = true
+		}
+	}
+	doc := &rssDoc{
+		Version:          "2.0",
+		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		Channel:          ch,
+	}
+	if feed.Author != nil && feed.Author.Email == "" && feed.Author.Name != "" {
+		// the presenter is known by name only, which managingEditor
+		// can not hold
+		rf.ManagingEditor = ""
+		doc.ItunesNamespace, doc.DCNamespace = itunesNS, dcNS
+		ch.ItunesAuthor = feed.Author.Name
+		for _, item := range ch.Items {
+			item.Creator, item.ItunesAuthor = feed.Author.Name, feed.Author.Name
+		}
+		ch.People = hosts(feed.Author.Name)
+		podcast = true
+	}
+	if podcast {
+		doc.PodcastNamespace = podcastNS
+	}
+	if itunes {
+		doc.ItunesNamespace = itunesNS
+	}
+	if atom {
+		doc.AtomNamespace = atomNS
+	}
+	return doc
+}
+
+// withAtomLink adds an atom:link to the channel
+func withAtomLink(rel, href string) extension {
+	return func(doc *rssDoc) {
+		doc.AtomNamespace = atomNS
+		doc.Channel.AtomLinks = append(doc.Channel.AtomLinks, atomLink{Rel: rel, Href: href, Type: "application/rss+xml"})
+	}
+}
+
+// withLanguage sets the language of the channel, both as the RSS
+// language element and as xml:lang for the generic XML tools
+func withLanguage(lang string) extension {
+	return func(doc *rssDoc) {
+		doc.Lang = lang
+		doc.Channel.Language = lang
+	}
+}
+
+// asArchive marks the document as an RFC 5005 archive document
+func asArchive(doc *rssDoc) {
+	doc.FHNamespace = fhNS
+	doc.Channel.Archive = &struct{}{}
+}
+
+// itemFromRss converts an item read from an RSS file back into
+// a generic one
+func itemFromRss(ri *feeds.RssItem) *feedItem {
+	item := &feedItem{Item: &feeds.Item{
+		Title:       ri.Title,
+		Link:        &feeds.Link{Href: ri.Link},
+		Description: ri.Description,
+		Id:          ri.Guid,
+	}}
+	for _, layout := range rfc822Layouts {
+		if t, err := time.Parse(layout, ri.PubDate); err == nil {
+			item.Created = t
+			break
+		}
+	}
+	if ri.Enclosure != nil {
+		item.Enclosure = &feeds.Enclosure{
+			Url:    ri.Enclosure.Url,
+			Length: ri.Enclosure.Length,
+			Type:   ri.Enclosure.Type,
+		}
+	}
+	return item
+}
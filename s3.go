@@ -0,0 +1,336 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// s3Endpoint is the API endpoint of the s3:// -mirror, the AWS one
+	// of s3Region if empty
+	s3Endpoint string
+	s3Region   = "us-east-1"
+)
+
+// s3Client talks to the S3 storage: the storage is not the site, so the
+// client tuned for the site (-bind, -resolver, -ip4, the transport
+// settings and the timeouts) is not used, and no overall timeout is set
+// as the uploads take as long as the audio is large
+var s3Client = &http.Client{}
+
+// emptySHA256 is the checksum of the empty payload
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3Store keeps the mirrored audio in an S3 bucket, addressed in the
+// path style so that any S3-compatible storage works; the audio is
+// downloaded to a local staging directory and removed from there once
+// uploaded
+type s3Store struct {
+	endpoint string
+	bucket   string
+	// prefix is the brand's "directory" in the bucket
+	prefix string
+	brand  string
+	region string
+
+	accessKey, secretKey, token string
+
+	staging string
+	now     func() time.Time
+}
+
+// newS3Store returns the store for the brand in the -mirror given as
+// s3://bucket/prefix, with the credentials from the usual AWS
+// environment variables
+func newS3Store(target, brand string) (*s3Store, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("bad S3 mirror %q", target)
+	}
+	s := &s3Store{
+		endpoint:  strings.TrimSuffix(s3Endpoint, "/"),
+		bucket:    u.Host,
+		brand:     brand,
+		region:    s3Region,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		staging:   filepath.Join(os.TempDir(), "radiorus-rss-mirror", brand),
+		now:       time.Now,
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for the S3 mirror")
+	}
+	if s.endpoint == "" {
+		s.endpoint = "https://s3." + s.region + ".amazonaws.com"
+	}
+	if p := strings.Trim(u.Path, "/"); p != "" {
+		s.prefix = p + "/"
+	}
+	s.prefix += brand + "/"
+	return s, nil
+}
+
+func (s *s3Store) objectURL(name string) string {
+	return s.endpoint + "/" + s.bucket + "/" + s.prefix + name
+}
+
+func (s *s3Store) dir() string {
+	return s.staging
+}
+
+func (s *s3Store) stat(ctx context.Context, name string) (int64, string, error) {
+	res, err := s.do(ctx, "HEAD", s.objectURL(name), nil, 0, emptySHA256, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	if err := s3Status(res, name); err != nil {
+		return 0, "", err
+	}
+	return res.ContentLength, res.Header.Get("X-Amz-Meta-Sha256"), nil
+}
+
+// put uploads the staged file unless there is none, i.e. it is in the
+// bucket already
+func (s *s3Store) put(ctx context.Context, name, sum string) error {
+	local := filepath.Join(s.staging, name)
+	f, err := os.Open(local)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	h := http.Header{}
+	h.Set("Content-Type", contentType(name))
+	h.Set("X-Amz-Meta-Sha256", sum)
+	res, err := s.do(ctx, "PUT", s.objectURL(name), f, fi.Size(), sum, h)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := s3Status(res, name); err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(local)
+}
+
+func (s *s3Store) remove(ctx context.Context, name string) error {
+	res, err := s.do(ctx, "DELETE", s.objectURL(name), nil, 0, emptySHA256, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return s3Status(res, name)
+}
+
+// s3List is the ListObjectsV2 response
+type s3List struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *s3Store) list(ctx context.Context) ([]string, error) {
+	var names []string
+	q := url.Values{"list-type": {"2"}, "prefix": {s.prefix}}
+	for {
+		res, err := s.do(ctx, "GET", s.endpoint+"/"+s.bucket+"?"+q.Encode(), nil, 0, emptySHA256, nil)
+		if err != nil {
+			return nil, err
+		}
+		var l s3List
+		err = s3Status(res, s.prefix)
+		if err == nil {
+			err = xml.NewDecoder(res.Body).Decode(&l)
+		}
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range l.Contents {
+			if name := strings.TrimPrefix(c.Key, s.prefix); !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		if !l.IsTruncated || l.NextContinuationToken == "" {
+			return names, nil
+		}
+		q.Set("continuation-token", l.NextContinuationToken)
+	}
+}
+
+func (s *s3Store) read(ctx context.Context, name string) ([]byte, error) {
+	res, err := s.do(ctx, "GET", s.objectURL(name), nil, 0, emptySHA256, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if err := s3Status(res, name); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (s *s3Store) write(ctx context.Context, name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	h := http.Header{}
+	h.Set("Content-Type", contentType(name))
+	res, err := s.do(ctx, "PUT", s.objectURL(name), bytes.NewReader(data), int64(len(data)), hex.EncodeToString(sum[:]), h)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return s3Status(res, name)
+}
+
+// url is the file under -mirror-url if set, or in the bucket itself
+func (s *s3Store) url(name string) string {
+	if mirrorURL != "" {
+		return mirrorURL + s.brand + "/" + name
+	}
+	return s.objectURL(name)
+}
+
+// do sends the signed request with the body of the length
+func (s *s3Store) do(ctx context.Context, method, u string, body io.Reader, length int64, payloadHash string, h http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = length
+	}
+	for k, v := range h {
+		req.Header[k] = v
+	}
+	s.sign(req, payloadHash)
+	return s3Client.Do(req)
+}
+
+// sign adds the AWS Signature Version 4 to the request, signing all
+// of its headers
+func (s *s3Store) sign(req *http.Request, payloadHash string) {
+	t := s.now().UTC()
+	date, day := t.Format("20060102T150405Z"), t.Format("20060102")
+	req.Header.Set("X-Amz-Date", date)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+
+	values := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		values[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, k := range names {
+		headers.WriteString(k + ":" + values[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	var query []string
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			query = append(query, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	sort.Strings(query)
+	path := s3Escape(req.URL.Path, false)
+	if path == "" {
+		path = "/"
+	}
+
+	canonical := strings.Join([]string{req.Method, path, strings.Join(query, "&"), headers.String(), signed, payloadHash}, "\n")
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{day, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s.accessKey, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+// s3Escape percent-encodes everything but the unreserved characters
+// and, unless in a query, the slashes
+func s3Escape(s string, query bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !query:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Status turns the unsuccessful response into an error, which
+// satisfies os.IsNotExist if there is no such object
+func s3Status(res *http.Response, name string) error {
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode <= 299:
+		return nil
+	case res.StatusCode == http.StatusNotFound:
+		return &os.PathError{Op: strings.ToLower(res.Request.Method), Path: name, Err: os.ErrNotExist}
+	default:
+		return fmt.Errorf("%s %s: %s", res.Request.Method, res.Request.URL, res.Status)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// scheduleURL is the station schedule page for a day, with {yyyy},
+	// {mm} and {dd} standing for the date; the air times of the
+	// episodes that only have a date are looked up there if it is set
+	scheduleURL string
+
+	airTimeRe = regexp.MustCompile(`\b([01]?\d|2[0-3]):([0-5]\d)\b`)
+)
+
+// scheduleURLFor returns the schedule page URL for the day
+func scheduleURLFor(day time.Time) string {
+	return strings.NewReplacer(
+		"{yyyy}", day.Format("2006"),
+		"{mm}", day.Format("01"),
+		"{dd}", day.Format("02"),
+	).Replace(scheduleURL)
+}
+
+// airTimes finds the times the brand is on air in the schedule, in
+// ascending order, as offsets from the beginning of the day; an entry
+// is a link to the brand along with the time in the closest element
+// around it that has one
+func airTimes(doc *goquery.Document, brand string) (times []time.Duration) {
+	seen := make(map[time.Duration]bool)
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if !linksToBrand(href, brand) {
+			return
+		}
+		for p := s; p.Length() != 0; p = p.Parent() {
+			m := airTimeRe.FindStringSubmatch(p.Text())
+			if m == nil {
+				continue
+			}
+			h, _ := strconv.Atoi(m[1])
+			min, _ := strconv.Atoi(m[2])
+			t := time.Duration(h)*time.Hour + time.Duration(min)*time.Minute
+			if !seen[t] {
+				seen[t] = true
+				times = append(times, t)
+			}
+			return
+		}
+	})
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return
+}
+
+func linksToBrand(href, brand string) bool {
+	i := strings.Index(href, "/brand/"+brand)
+	if i < 0 {
+		return false
+	}
+	rest := href[i+len("/brand/"+brand):]
+	return rest == "" || strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, "?")
+}
+
+// dateOnly tells whether the time is just a date, with no air time
+func dateOnly(t time.Time) bool {
+	t = t.In(moscow)
+	return t.Year() > 1970 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+// addAirTimes sets the air times of the items that only have a date
+// from the schedule; the listing goes from the latest, so the same
+// day editions get the air times from the latest one
+func addAirTimes(ctx context.Context, feed *brandFeed, brand string) {
+	days := make(map[time.Time][]*feedItem)
+	var order []time.Time
+	for _, item := range feed.Items {
+		if !dateOnly(item.Created) {
+			continue
+		}
+		day := item.Created.In(moscow)
+		if _, ok := days[day]; !ok {
+			order = append(order, day)
+		}
+		days[day] = append(days[day], item)
+	}
+
+	for _, day := range order {
+		u := scheduleURLFor(day)
+		page, _, err := fetchPage(ctx, u)
+		if err != nil {
+			warnf(ctx, "could not fetch schedule %v: %v", u, err)
+			continue
+		}
+		doc, err := newDocument(page)
+		if err != nil {
+			warnf(ctx, "could not parse schedule %v: %v", u, err)
+			continue
+		}
+		times := airTimes(doc, brand)
+		if len(times) == 0 {
+			warnf(ctx, "brand %s not found in schedule %v", brand, u)
+			continue
+		}
+		items := days[day]
+		for i, item := range items {
+			if j := len(times) - 1 - i; j >= 0 {
+				item.Created = day.Add(times[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+const testSchedule = `<ul class="schedule">
+<li><span class="time">09:00</span> <a href="/brand/1">Новости</a></li>
+<li><span class="time">14:10</span> <a href="/brand/57083/episode/1">"Аэростат"</a></li>
+<li><span class="time">19:30</span> <a href="https://www.radiorus.ru/brand/57083">"Аэростат" (повтор)</a></li>
+<li><span class="time">21:00</span> <a href="/brand/570830">Другая передача</a></li>
+</ul>`
+
+func TestAirTimes(t *testing.T) {
+	doc, err := newDocument([]byte(testSchedule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{14*time.Hour + 10*time.Minute, 19*time.Hour + 30*time.Minute}
+	if got := airTimes(doc, "57083"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestAddAirTimes(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = w.Write([]byte(testSchedule))
+	}))
+	defer server.Close()
+
+	defer func(s string) { scheduleURL = s }(scheduleURL)
+	scheduleURL = server.URL + "/schedule/{dd}-{mm}-{yyyy}"
+
+	day := time.Date(2020, time.January, 19, 0, 0, 0, 0, moscow)
+	exact := time.Date(2020, time.January, 12, 14, 10, 0, 0, moscow)
+	feed := &brandFeed{}
+	feed.Add(&feedItem{Item: &feeds.Item{Created: day}})
+	feed.Add(&feedItem{Item: &feeds.Item{Created: day}})
+	feed.Add(&feedItem{Item: &feeds.Item{Created: exact}})
+
+	addAirTimes(context.Background(), feed, "57083")
+	if want := "/schedule/19-01-2020"; requested != want {
+		t.Errorf("want %s requested, got %s", want, requested)
+	}
+	want := []time.Time{day.Add(19*time.Hour + 30*time.Minute), day.Add(14*time.Hour + 10*time.Minute), exact}
+	for i, item := range feed.Items {
+		if !item.Created.Equal(want[i]) {
+			t.Errorf("item %d: want %v, got %v", i, want[i], item.Created)
+		}
+	}
+}
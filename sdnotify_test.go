@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// helperNotifySocket listens where sdNotify sends to, the returned
+// function cleans up
+func helperNotifySocket(t *testing.T) (*net.UnixConn, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Skip(err)
+	}
+
+	old, set := os.LookupEnv("NOTIFY_SOCKET")
+	os.Setenv("NOTIFY_SOCKET", socket)
+	return conn, func() {
+		if set {
+			os.Setenv("NOTIFY_SOCKET", old)
+		} else {
+			os.Unsetenv("NOTIFY_SOCKET")
+		}
+		conn.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func helperReceive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestSdNotify(t *testing.T) {
+	conn, cleanup := helperNotifySocket(t)
+	defer cleanup()
+	if err := sdNotify("READY=1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := helperReceive(t, conn); got != "READY=1" {
+		t.Errorf("want READY=1, got %q", got)
+	}
+}
+
+func TestSdNotifyDisabled(t *testing.T) {
+	old, set := os.LookupEnv("NOTIFY_SOCKET")
+	os.Unsetenv("NOTIFY_SOCKET")
+	if set {
+		defer os.Setenv("NOTIFY_SOCKET", old)
+	}
+	if err := sdNotify("READY=1"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	defer os.Unsetenv("WATCHDOG_USEC")
+	defer os.Unsetenv("WATCHDOG_PID")
+
+	testdata := []struct {
+		usec, pid string
+		want      time.Duration
+	}{
+		{"", "", 0},
+		{"30000000", "", 30 * time.Second},
+		{"30000000", strconv.Itoa(os.Getpid()), 30 * time.Second},
+		{"30000000", "1", 0},
+		{"garbage", "", 0},
+	}
+
+	for _, tc := range testdata {
+		os.Setenv("WATCHDOG_USEC", tc.usec)
+		os.Setenv("WATCHDOG_PID", tc.pid)
+		if got := watchdogInterval(); got != tc.want {
+			t.Errorf("WATCHDOG_USEC=%s WATCHDOG_PID=%s: want %v, got %v", tc.usec, tc.pid, tc.want, got)
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	conn, cleanup := helperNotifySocket(t)
+	defer cleanup()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runWatchdog(ctx, 20*time.Millisecond)
+
+	if got := helperReceive(t, conn); got != "WATCHDOG=1" {
+		t.Errorf("want WATCHDOG=1 when idle, got %q", got)
+	}
+
+	// busy without progress means stuck, no keep-alives
+	atomic.StoreInt32(&busy, 1)
+	defer atomic.StoreInt32(&busy, 0)
+	atomic.StoreInt32(&progress, 0)
+	time.Sleep(30 * time.Millisecond)
+	for i := 0; ; i++ {
+		if i == 10 {
+			t.Fatal("keep-alives sent while stuck")
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		if _, err := conn.Read(make([]byte, 64)); err != nil {
+			break
+		}
+	}
+
+	markProgress()
+	if got := helperReceive(t, conn); got != "WATCHDOG=1" {
+		t.Errorf("want WATCHDOG=1 on progress, got %q", got)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	searchURL = "https://smotrim.ru/search?q="
+
+	brandLinkRe = regexp.MustCompile(`^(?:https?://[^/]+)?/brand/(\d+)/?$`)
+)
+
+// foundBrand is a programme found by the search
+type foundBrand struct {
+	Number      string
+	Title       string
+	Description string
+}
+
+func searchCmd(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	snippet := fs.Bool("snippet", false, "print the brands found as the \"brands\" list of the -config file instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s search [options] QUERY\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	page, _ := getPage(searchURL + url.QueryEscape(strings.Join(fs.Args(), " ")))
+	brands, err := parseBrands(page)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(brands) == 0 {
+		log.Fatal("nothing found")
+	}
+
+	if *snippet {
+		if err := printSnippets(os.Stdout, brands); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printBrands(os.Stdout, brands)
+}
+
+// parseBrands finds the links to brands on a search results or catalogue
+// page, the title and description are taken from the card the link is in
+func parseBrands(page []byte) (brands []foundBrand, err error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		m := brandLinkRe.FindStringSubmatch(href)
+		if m == nil || seen[m[1]] {
+			return
+		}
+
+		card := s.Closest(`[class$="-card"]`)
+
+		title, _ := s.Attr("title")
+		if title == "" {
+			title = card.Find(`[class*="__title"]`).First().Text()
+		}
+		if title == "" {
+			title = s.Text()
+		}
+		title = strings.Join(strings.Fields(title), " ")
+		if title == "" {
+			return
+		}
+
+		desc := card.Find(`[class*="__anons"], [class*="__description"]`).First().Text()
+
+		seen[m[1]] = true
+		brands = append(brands, foundBrand{
+			Number:      m[1],
+			Title:       title,
+			Description: strings.Join(strings.Fields(desc), " "),
+		})
+	})
+	return
+}
+
+func printBrands(w io.Writer, brands []foundBrand) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "BRAND\tTITLE\tDESCRIPTION")
+	for _, b := range brands {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", b.Number, b.Title, b.Description)
+	}
+	_ = tw.Flush()
+}
+
+// printSnippets prints the brands as the brand list of the -config
+// file, ready to be pasted into it
+func printSnippets(w io.Writer, brands []foundBrand) error {
+	bcs := make([]brandConfig, len(brands))
+	for i, b := range brands {
+		bcs[i] = brandConfig{Brand: b.Number, Smotrim: true}
+	}
+	b, err := json.MarshalIndent(bcs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseBrands(t *testing.T) {
+	page := []byte(`<html><body>
+<div class="brand-card">
+    <a class="brand-card__link" href="/brand/57083"></a>
+    <h3 class="brand-card__title"><span>Аэростат</span></h3>
+    <div class="brand-card__anons">Программу ведёт
+        Борис Гребенщиков</div>
+</div>
+<div class="brand-card">
+    <a class="brand-card__link" href="https://smotrim.ru/brand/57083" title="Аэростат"></a>
+</div>
+<a href="/brand/59798">Мы очень любим оперу</a>
+<a href="/audio/2628425">Выпуск 884</a>
+</body></html>`)
+
+	want := []foundBrand{
+		{"57083", "Аэростат", "Программу ведёт Борис Гребенщиков"},
+		{"59798", "Мы очень любим оперу", ""},
+	}
+
+	got, err := parseBrands(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	var buf bytes.Buffer
+	if err := printSnippets(&buf, got); err != nil {
+		t.Fatal(err)
+	}
+	file := helperConfigFile(t, `{"brands": `+buf.String()+`}`)
+	defer os.Remove(file)
+	cfg, err := loadConfig(file)
+	if err != nil {
+		t.Fatalf("snippet not usable in the config: %v\n%s", err, buf.String())
+	}
+	wantBrands := []brandConfig{{Brand: "57083", Smotrim: true}, {Brand: "59798", Smotrim: true}}
+	if !reflect.DeepEqual(cfg.Brands, wantBrands) {
+		t.Errorf("want %+v, got %+v", wantBrands, cfg.Brands)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/feeds"
+)
+
+// seasons is how the episodes are split into seasons: by the year of
+// broadcast, by the season named in the title, or not at all if empty
+var seasons string
+
+var seasonRe = regexp.MustCompile(`(?i)(?:сезон\s*№?\s*(\d+)|(\d+)[\s-]*(?:й\s+)?сезон)`)
+
+// itemSeason returns the season of the item, 0 if it has none
+func itemSeason(item *feedItem, mode string) int {
+	switch mode {
+	case "year":
+		if !item.Created.IsZero() {
+			return item.Created.In(moscow).Year()
+		}
+	case "title":
+		if m := seasonRe.FindStringSubmatch(item.Title); m != nil {
+			n, _ := strconv.Atoi(m[1] + m[2])
+			return n
+		}
+	}
+	return 0
+}
+
+// seasonFilename returns the name of the brand's feed of the season
+func seasonFilename(path, brand string, season int) string {
+	return feedFilename(path, fmt.Sprintf("%s-season-%d", brand, season))
+}
+
+// writeSeasons marks the feed items with their seasons and writes
+// a feed per season, merging the items with the ones written to it
+// previously and applying the common extensions
+func writeSeasons(feed *brandFeed, path, brand, mode string, common ...extension) error {
+	bySeason := make(map[int][]*feedItem)
+	for _, item := range feed.Items {
+		if n := itemSeason(item, mode); n != 0 {
+			item.season = n
+			bySeason[n] = append(bySeason[n], item)
+		}
+	}
+
+	var numbers []int
+	for n := range bySeason {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	for _, n := range numbers {
+		file := seasonFilename(path, brand, n)
+		season := &brandFeed{
+			Feed: feeds.Feed{
+				Title:       fmt.Sprintf("%s (сезон %d)", feed.Title, n),
+				Link:        feed.Link,
+				Description: feed.Description,
+				Image:       feed.Image,
+				Author:      feed.Author,
+				Created:     feed.Created,
+			},
+			Items: mergeItems(bySeason[n], file),
+		}
+		if mode == "year" {
+			season.Title = fmt.Sprintf("%s (%d)", feed.Title, n)
+		}
+		for _, item := range season.Items {
+			if item.season == 0 {
+				item.season = n
+			}
+		}
+		exts := append([]extension{withAtomLink("related", feedURL(feedFilename(path, brand)))}, common...)
+		if err := writeOutput(file, createFeed(season, exts...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
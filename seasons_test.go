@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestItemSeason(t *testing.T) {
+	tests := []struct {
+		title, mode string
+		want        int
+	}{
+		{"Сезон 2. Новые имена", "title", 2},
+		{"Новые имена (3-й сезон)", "title", 3},
+		{"Новые имена, сезон №4", "title", 4},
+		{"Новые имена", "title", 0},
+		{"Новые имена", "year", 2020},
+		{"Новые имена", "", 0},
+	}
+	for _, tt := range tests {
+		item := &feedItem{Item: &feeds.Item{Title: tt.title, Created: time.Date(2019, 12, 31, 22, 0, 0, 0, time.UTC)}}
+		if got := itemSeason(item, tt.mode); got != tt.want {
+			t.Errorf("%q (%s): want %d, got %d", tt.title, tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestWriteSeasons(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir += "/"
+
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
+	}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "episodes"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeSeasons(feed, dir, "57083", "year"); err != nil {
+		t.Fatal(err)
+	}
+	b := helperReadFile(t, seasonFilename(dir, "57083", 2020))
+	assertStringContains(t, b, `<atom:link rel="related" href="radiorus-57083.rss"`)
+	assertStringContains(t, b, "<itunes:season>2020</itunes:season>")
+	helperAssertItems(t, seasonFilename(dir, "57083", 2020), 4)
+	helperAssertItems(t, seasonFilename(dir, "57083", 2019), 6)
+
+	current := string(createFeed(feed))
+	assertStringContains(t, current, "<itunes:season>2019</itunes:season>")
+
+	// a later run only sees the latest items, the seasons must keep the rest
+	feed.Items = feed.Items[:2]
+	if err := writeSeasons(feed, dir, "57083", "year"); err != nil {
+		t.Fatal(err)
+	}
+	helperAssertItems(t, seasonFilename(dir, "57083", 2020), 4)
+	helperAssertItems(t, seasonFilename(dir, "57083", 2019), 6)
+}
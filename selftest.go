@@ -0,0 +1,115 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// selfCheck is the outcome of checking one of the extractors
+type selfCheck struct {
+	name   string
+	ok     bool
+	detail string
+}
+
+func selftestCmd(args []string) {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	brand := fs.String("brand", "57083", "known-good brand number to check against (defaults to Aerostat)")
+	smotrim := fs.Bool("smotrim", false, "use smotrim.ru directly")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s selftest [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	feed, err := processBrand(fetchCtx, brandURL(*brand, *smotrim))
+	if err != nil {
+		fmt.Printf("FAIL\tbrand page\t%v\n", err)
+		os.Exit(1)
+	}
+	if !printChecks(os.Stdout, selftestChecks(feed)) {
+		os.Exit(1)
+	}
+}
+
+// selftestChecks checks that every extractor found something in
+// the feed of a brand that is known to have it all
+func selftestChecks(feed *brandFeed) []selfCheck {
+	checks := []selfCheck{
+		{name: "programme title", ok: feed.Title != ""},
+		{name: "programme description", ok: feed.Description != ""},
+		{name: "programme image", ok: feed.Image != nil && feed.Image.Url != ""},
+		{name: "episodes", ok: len(feed.Items) != 0, detail: fmt.Sprintf("%d found", len(feed.Items))},
+	}
+	if len(feed.Items) == 0 {
+		return checks
+	}
+
+	// every episode is expected to have these
+	all := func(name string, has func(*feedItem) bool) {
+		missing := 0
+		for _, item := range feed.Items {
+			if !has(item) {
+				missing++
+			}
+		}
+		c := selfCheck{name: name, ok: missing == 0}
+		if missing != 0 {
+			c.detail = fmt.Sprintf("missing in %d of %d", missing, len(feed.Items))
+		}
+		checks = append(checks, c)
+	}
+	all("episode titles", func(item *feedItem) bool { return item.Title != "" })
+	all("episode dates", func(item *feedItem) bool { return !item.Created.IsZero() })
+	all("audio IDs", func(item *feedItem) bool { return audioID(item) != "" })
+
+	// some episodes may lack these, but not all of them
+	some := func(name string, has func(*feedItem) bool) {
+		found := 0
+		for _, item := range feed.Items {
+			if has(item) {
+				found++
+			}
+		}
+		checks = append(checks, selfCheck{name: name, ok: found != 0, detail: fmt.Sprintf("found in %d of %d", found, len(feed.Items))})
+	}
+	some("episode descriptions", func(item *feedItem) bool { return item.Description != "" })
+	if parseSite(feed) != "smotrim.ru" {
+		// the smotrim.ru listing has no episode images
+		some("episode images", func(item *feedItem) bool { return item.image != "" })
+	}
+	return checks
+}
+
+// printChecks prints the checks, returning true if all of them passed
+func printChecks(w io.Writer, checks []selfCheck) bool {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	passed := true
+	for _, c := range checks {
+		status := "ok"
+		if !c.ok {
+			status, passed = "FAIL", false
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", status, c.name, c.detail)
+	}
+	_ = tw.Flush()
+	return passed
+}
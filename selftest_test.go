@@ -0,0 +1,59 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestSelftestChecks(t *testing.T) {
+	server := helperMockServer(t)
+	defer server.Close()
+	defer helperCleanupServer(t)
+
+	feed, err := processBrand(context.Background(), server.URL+"/brand/57083/episodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if !printChecks(&out, selftestChecks(feed)) {
+		t.Errorf("checks failed on the fixtures:\n%s", out.String())
+	}
+
+	feed.Image = nil
+	feed.Items[1].Enclosure = nil
+	failed := make(map[string]string)
+	for _, c := range selftestChecks(feed) {
+		if !c.ok {
+			failed[c.name] = c.detail
+		}
+	}
+	if len(failed) != 2 || failed["audio IDs"] != "missing in 1 of 10" {
+		t.Errorf("got failures %v", failed)
+	}
+	if _, ok := failed["programme image"]; !ok {
+		t.Errorf("got failures %v", failed)
+	}
+
+	if checks := selftestChecks(&brandFeed{Feed: feeds.Feed{Title: "x"}}); printChecks(&out, checks) {
+		t.Error("want an empty feed to fail")
+	}
+}
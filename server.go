@@ -0,0 +1,207 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// brandStatus is the outcome of the brand's recent generations
+type brandStatus struct {
+	LastSuccess   *time.Time `json:"last_success,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorTime *time.Time `json:"last_error_time,omitempty"`
+	Episodes      int        `json:"episodes,omitempty"`
+	NewEpisodes   int        `json:"new_episodes,omitempty"`
+	NextRun       *time.Time `json:"next_run,omitempty"`
+}
+
+// healthy is true if the brand's last generation succeeded
+func (s brandStatus) healthy() bool {
+	return s.LastSuccess != nil && (s.LastErrorTime == nil || s.LastSuccess.After(*s.LastErrorTime))
+}
+
+// handler serves the generated files along with the service endpoints
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", withCORS(d.withAuth(withPublished(withPrecompressed(withETag(http.FileServer(noListing{http.Dir(outputPath)})))))))
+	mux.HandleFunc("/healthz", d.healthz)
+	mux.Handle("/status", d.withAuth(http.HandlerFunc(d.statusPage)))
+	mux.Handle("/status/", withCORS(d.withAuth(http.HandlerFunc(d.statusJSON))))
+	mux.HandleFunc("/admin/refresh", d.adminRefresh)
+	mux.Handle("/api/", withCORS(d.withAuth(d.api())))
+	if d.proxied != nil {
+		mux.Handle("/proxy/", withCORS(d.withAuth(d.proxy(d.proxied))))
+	}
+	return withCompression(mux)
+}
+
+// adminRefresh triggers immediate regeneration of the brand given
+// as a parameter, or of all the brands if none is given
+func (d *daemon) adminRefresh(w http.ResponseWriter, r *http.Request) {
+	if adminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !secretsEqual(r.Header.Get("Authorization"), "Bearer "+adminToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	brands := []string{r.URL.Query().Get("brand")}
+	if brands[0] == "" {
+		brands = brands[:0]
+		for _, bc := range d.brands {
+			brands = append(brands, bc.Brand)
+		}
+	}
+	for _, brand := range brands {
+		if !d.trigger(brand) {
+			http.Error(w, "unknown brand "+brand, http.StatusNotFound)
+			return
+		}
+		noticef("brand %s: refresh requested", brand)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// healthz reports the status of every brand, it answers 503 unless
+// the latest generation of all the brands succeeded
+func (d *daemon) healthz(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	report := make(map[string]brandStatus, len(d.brands))
+	code := http.StatusOK
+	for _, bc := range d.brands {
+		s := d.status[bc.Brand]
+		if !s.healthy() {
+			code = http.StatusServiceUnavailable
+		}
+		report[bc.Brand] = s
+	}
+	d.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+}
+
+// withPublished lets through only the requests for the files the
+// program publishes: the index, the stylesheet, and the files of the
+// brands, never the state kept alongside them in the hidden files
+func withPublished(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !published(r.URL.Path) {
+			http.NotFound(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// published checks whether the file is one the program publishes
+func published(urlPath string) bool {
+	for _, part := range strings.Split(urlPath, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	switch path.Clean("/" + urlPath) {
+	case "/", "/index.html", "/" + defaultXSLTName:
+		return true
+	}
+	return fileBrand(urlPath) != ""
+}
+
+// noListing is the file system that refuses the directories with no
+// index.html, so that the file server never lists their files
+type noListing struct{ http.FileSystem }
+
+func (fs noListing) Open(name string) (http.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil && fi.IsDir() {
+		index, err := fs.FileSystem.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+// withETag sets the ETag of the file being served, so that the file
+// server answers conditional requests with 304 for ETags as it does
+// for modification times
+func withETag(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join(outputPath, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		if fi, err := os.Stat(name); err == nil && fi.Mode().IsRegular() {
+			w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// serve runs the HTTP server until the context is cancelled
+func (d *daemon) serve(ctx context.Context, addr string) {
+	srv := &http.Server{Addr: addr, Handler: d.handler()}
+	if tlsCert != "" {
+		l, err := newCertLoader(tlsCert, tlsKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.TLSConfig = &tls.Config{GetCertificate: l.getCertificate, MinVersion: tls.VersionTLS12}
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	var err error
+	if srv.TLSConfig != nil {
+		noticef("serving HTTPS on %s", addr)
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		noticef("serving on %s", addr)
+		err = srv.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func helperDaemon(brands ...string) *daemon {
+	var bcs []brandConfig
+	for _, b := range brands {
+		bcs = append(bcs, brandConfig{Brand: b})
+	}
+	return newDaemon(bcs)
+}
+
+// helperServedFeed publishes TestServedFeed.golden as the feed of the
+// brand 57083 in a temporary output path, returning its URL path
+func helperServedFeed(t testing.TB) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := outputPath
+	outputPath = dir + "/"
+	file := feedFilename(outputPath, "57083")
+	if err := ioutil.WriteFile(file, helperLoadBytes(t, "TestServedFeed.golden"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return "/" + filepath.Base(file), func() {
+		outputPath = old
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	d := helperDaemon("57083", "59798")
+	earlier, later := time.Now().Add(-time.Hour), time.Now()
+
+	testdata := []struct {
+		name   string
+		status map[string]brandStatus
+		want   int
+	}{
+		{"not generated yet", nil, http.StatusServiceUnavailable},
+		{"all fine", map[string]brandStatus{
+			"57083": {LastSuccess: &later},
+			"59798": {LastSuccess: &earlier},
+		}, http.StatusOK},
+		{"recovered", map[string]brandStatus{
+			"57083": {LastSuccess: &later, LastError: "server error", LastErrorTime: &earlier},
+			"59798": {LastSuccess: &later},
+		}, http.StatusOK},
+		{"failing", map[string]brandStatus{
+			"57083": {LastSuccess: &earlier, LastError: "server error", LastErrorTime: &later},
+			"59798": {LastSuccess: &later},
+		}, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testdata {
+		d.status = make(map[string]brandStatus)
+		for b, s := range tc.status {
+			d.status[b] = s
+		}
+
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: want %d, got %d", tc.name, tc.want, w.Code)
+		}
+
+		var report map[string]brandStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(report) != 2 {
+			t.Errorf("%s: want 2 brands reported, got %d", tc.name, len(report))
+		}
+		if s := tc.status["57083"]; report["57083"].LastError != s.LastError {
+			t.Errorf("%s: want error %q, got %q", tc.name, s.LastError, report["57083"].LastError)
+		}
+	}
+}
+
+func TestServeFiles(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	helperDaemon("57083").handler().ServeHTTP(w, httptest.NewRequest("GET", feed, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", w.Code)
+	}
+	if want := helperLoadBytes(t, "TestServedFeed.golden"); w.Body.String() != string(want) {
+		t.Error("served file differs")
+	}
+}
+
+func TestServePublishedOnly(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+	for _, name := range []string{".57083.guids.json", ".audio.json", "notes.txt", defaultXSLTName} {
+		if err := ioutil.WriteFile(outputPath+name, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for p, want := range map[string]int{
+		feed:                       http.StatusOK,
+		"/" + defaultXSLTName:      http.StatusOK,
+		"/.57083.guids.json":       http.StatusNotFound,
+		"/.audio.json":             http.StatusNotFound,
+		"/notes.txt":               http.StatusNotFound,
+		"/radiorus-57083.rss.part": http.StatusNotFound,
+		"/":                        http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		helperDaemon("57083").handler().ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if w.Code != want {
+			t.Errorf("%s: want %d, got %d", p, want, w.Code)
+		}
+	}
+
+	// the directory is only served by its index
+	if err := ioutil.WriteFile(outputPath+"index.html", []byte("<html></html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	helperDaemon("57083").handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "radiorus-57083.rss") {
+		t.Errorf("want the index served, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminRefresh(t *testing.T) {
+	defer func(token string) { adminToken = token }(adminToken)
+
+	testdata := []struct {
+		name, token, method, auth, query string
+		want                             int
+		triggered                        []string
+	}{
+		{"disabled", "", "POST", "Bearer ", "", http.StatusNotFound, nil},
+		{"no auth", "secret", "POST", "", "", http.StatusUnauthorized, nil},
+		{"wrong token", "secret", "POST", "Bearer wrong", "", http.StatusUnauthorized, nil},
+		{"GET", "secret", "GET", "Bearer secret", "", http.StatusMethodNotAllowed, nil},
+		{"unknown brand", "secret", "POST", "Bearer secret", "?brand=1", http.StatusNotFound, nil},
+		{"one brand", "secret", "POST", "Bearer secret", "?brand=59798", http.StatusAccepted, []string{"59798"}},
+		{"all brands", "secret", "POST", "Bearer secret", "", http.StatusAccepted, []string{"57083", "59798"}},
+	}
+
+	for _, tc := range testdata {
+		adminToken = tc.token
+		d := helperDaemon("57083", "59798")
+
+		req := httptest.NewRequest(tc.method, "/admin/refresh"+tc.query, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: want %d, got %d", tc.name, tc.want, w.Code)
+		}
+
+		var triggered []string
+		for _, bc := range d.brands {
+			if len(d.triggers[bc.Brand]) != 0 {
+				triggered = append(triggered, bc.Brand)
+			}
+		}
+		if fmt.Sprint(triggered) != fmt.Sprint(tc.triggered) {
+			t.Errorf("%s: want %v triggered, got %v", tc.name, tc.triggered, triggered)
+		}
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	feed, cleanup := helperServedFeed(t)
+	defer cleanup()
+	h := helperDaemon("57083").handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", feed, nil))
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("want ETag and Last-Modified, got %q and %q", etag, modified)
+	}
+
+	for _, header := range []struct{ name, value string }{
+		{"If-None-Match", etag},
+		{"If-Modified-Since", modified},
+	} {
+		req := httptest.NewRequest("GET", feed, nil)
+		req.Header.Set(header.name, header.value)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("%s: want 304, got %d", header.name, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("%s: want empty body, got %d bytes", header.name, w.Body.Len())
+		}
+	}
+
+	req := httptest.NewRequest("GET", feed, nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("stale ETag: want 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// siteDir is where to render the static website of the brands to,
+	// none is rendered if empty
+	siteDir string
+	// siteTemplatesDir holds the brand.html and episode.html templates
+	// overriding the default ones
+	siteTemplatesDir string
+
+	siteTemplates *template.Template
+)
+
+const defaultBrandTemplate = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="alternate" type="application/rss+xml" title="{{.Title}}" href="{{.Feed}}">
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Image}}<img src="{{.}}" alt="" width="300">
+{{end}}<p style="white-space: pre-line">{{.Description}}</p>
+<p><a href="{{.Feed}}">RSS</a> · <a href="{{.Link}}">{{.Link}}</a></p>
+<ul>
+{{range .Episodes}}<li>{{date .Date}} <a href="{{.Page}}">{{.Title}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`
+
+const defaultEpisodeTemplate = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} — {{.Brand.Title}}</title>
+</head>
+<body>
+<p><a href="index.html">{{.Brand.Title}}</a></p>
+<h1>{{.Title}}</h1>
+<p>{{date .Date}}</p>
+{{with .Image}}<img src="{{.}}" alt="" width="300">
+{{end}}{{with .Audio}}<audio controls preload="none" src="{{.}}"></audio>
+<p><a href="{{.}}" download>Скачать</a></p>
+{{end}}<p style="white-space: pre-line">{{.Description}}</p>
+{{with .Tags}}<p>{{range .}}#{{.}} {{end}}</p>
+{{end}}<p><a href="{{.Link}}">{{.Link}}</a></p>
+</body>
+</html>
+`
+
+var siteFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.In(moscow).Format("02.01.2006")
+	},
+}
+
+// siteBrand is what the brand.html template is executed with
+type siteBrand struct {
+	Title       string
+	Description string
+	Link        string
+	Image       string
+	Feed        string
+	Episodes    []*siteEpisode
+}
+
+// siteEpisode is what the episode.html template is executed with
+type siteEpisode struct {
+	Brand       *siteBrand
+	Title       string
+	Description string
+	Link        string
+	Date        time.Time
+	Image       string
+	Audio       string
+	Tags        []string
+	Page        string
+}
+
+// parseSiteTemplates parses the default templates, replacing them with
+// the ones found in dir
+func parseSiteTemplates(dir string) (*template.Template, error) {
+	t := template.New("site").Funcs(siteFuncs)
+	for name, def := range map[string]string{
+		"brand.html":   defaultBrandTemplate,
+		"episode.html": defaultEpisodeTemplate,
+	} {
+		text := def
+		if dir != "" {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			switch {
+			case err == nil:
+				text = string(b)
+			case !os.IsNotExist(err):
+				return nil, err
+			}
+		}
+		if _, err := t.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// newSiteBrand prepares the data to render the site of the feed with
+func newSiteBrand(feed *brandFeed, feedLink string) *siteBrand {
+	b := &siteBrand{
+		Title:       feed.Title,
+		Description: feed.Description,
+		Feed:        feedLink,
+	}
+	if feed.Link != nil {
+		b.Link = feed.Link.Href
+	}
+	if feed.Image != nil {
+		b.Image = feed.Image.Url
+	}
+	for _, item := range feed.Items {
+		e := &siteEpisode{
+			Brand:       b,
+			Title:       item.Title,
+			Description: item.Description,
+			Date:        item.Created,
+			Page:        episodePage(item),
+		}
+		if item.Link != nil {
+			e.Link = item.Link.Href
+		}
+		if item.Enclosure != nil {
+			e.Audio = item.Enclosure.Url
+		}
+		e.Image, e.Tags = item.image, item.categories
+		b.Episodes = append(b.Episodes, e)
+	}
+	return b
+}
+
+// episodePage returns the file name of the episode's page
+func episodePage(item *feedItem) string {
+	name := item.Id
+	if item.Link != nil {
+		name = item.Link.Href
+	}
+	name = path.Base(strings.TrimSuffix(name, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '?' || r == '#' || r == ':' {
+			return '-'
+		}
+		return r
+	}, name)
+	return name + ".html"
+}
+
+// writeSite renders the static site of the brand into dir/brand
+func writeSite(t *template.Template, feed *brandFeed, dir, brand, feedFile string) error {
+	dir = filepath.Join(dir, brand)
+	if err := mkdirOutput(dir); err != nil {
+		return err
+	}
+
+	feedLink := feedURL(feedFile)
+	if baseURL == "" {
+		if rel, err := filepath.Rel(dir, feedFile); err == nil {
+			feedLink = filepath.ToSlash(rel)
+		}
+	}
+	b := newSiteBrand(feed, feedLink)
+
+	render := func(name, file string, data interface{}) error {
+		var buf bytes.Buffer
+		if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+			return err
+		}
+		return writeOutput(filepath.Join(dir, file), buf.Bytes())
+	}
+	if err := render("brand.html", "index.html", b); err != nil {
+		return err
+	}
+	for _, e := range b.Episodes {
+		if err := render("episode.html", e.Page, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
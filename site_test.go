@@ -0,0 +1,99 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestWriteSite(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Link: &feeds.Link{Href: "http://www.radiorus.ru/brand/57083/episodes"},
+	}}
+	if err := populateFeed(feed, cleanText(helperLoadBytes(t, "episodes"))); err != nil {
+		t.Fatal(err)
+	}
+	feed.Items = feed.Items[:2]
+	feed.Items[0].Description = "Первая строка\nвторая <строка>"
+	feed.Items[0].categories = []string{"музыка"}
+
+	dir, err := ioutil.TempDir("", "radiorus-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmpl, err := parseSiteTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSite(tmpl, feed, filepath.Join(dir, "site"), "57083", filepath.Join(dir, "radiorus-57083.rss")); err != nil {
+		t.Fatal(err)
+	}
+
+	page := episodePage(feed.Items[0])
+	if page != "2237849.html" {
+		t.Errorf("got episode page %q", page)
+	}
+	for name, file := range map[string]string{"index": "index.html", "episode": page} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "site", "57083", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, b, filepath.Join("testdata", t.Name()+"."+name+".golden"))
+	}
+}
+
+func TestSiteTemplateOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "episode.html"), []byte(`{{.Title}} {{date .Date}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := parseSiteTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Lookup("brand.html") == nil {
+		t.Error("default brand.html missing")
+	}
+	e := &siteEpisode{Title: "Выпуск", Date: time.Date(2020, time.January, 26, 22, 0, 0, 0, time.UTC)}
+	var got bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&got, "episode.html", e); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "Выпуск 27.01.2020" {
+		t.Errorf("got %q", got.String())
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "brand.html"), []byte(`{{.Title`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSiteTemplates(dir); err == nil {
+		t.Error("want error for a broken template")
+	}
+}
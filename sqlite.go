@@ -0,0 +1,283 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The SQLite database is written by hand to keep the program free of
+// cgo: the tables are plain rowid b-trees packed once, there are no
+// indices and no free pages, see https://www.sqlite.org/fileformat.html
+
+const (
+	sqlitePageSize = 4096
+	sqliteHeader   = 100 // the database header on page 1
+
+	sqliteInteriorTable = 0x05
+	sqliteLeafTable     = 0x0d
+)
+
+// sqliteTable is a table of the database: the CREATE TABLE statement
+// and the rows of int64, string and nil values, the rowids counting from
+// 1; an INTEGER PRIMARY KEY column is the rowid and is to be nil
+type sqliteTable struct {
+	name   string
+	schema string
+	rows   [][]interface{}
+}
+
+var errSQLiteSchema = errors.New("schema does not fit the first page")
+
+// sqliteDB is the database being assembled, page by page
+type sqliteDB struct {
+	pages [][]byte
+}
+
+// writeSQLite writes the tables as an SQLite 3 database file
+func writeSQLite(w io.Writer, tables []sqliteTable) error {
+	db := &sqliteDB{}
+	db.newPage() // sqlite_master, filled in last
+
+	var master [][]byte
+	for i, t := range tables {
+		root := db.writeTable(t.rows)
+		row := []interface{}{"table", t.name, t.name, int64(root), t.schema}
+		master = append(master, db.leafCell(int64(i+1), sqliteRecord(row)))
+	}
+	if !fillSQLitePage(db.pages[0], sqliteHeader, sqliteLeafTable, master, 0) {
+		return errSQLiteSchema
+	}
+
+	h := db.pages[0][:sqliteHeader]
+	copy(h, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(h[16:], sqlitePageSize)
+	h[18], h[19] = 1, 1 // legacy journal
+	h[21], h[22], h[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(h[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(h[28:], uint32(len(db.pages)))
+	binary.BigEndian.PutUint32(h[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(h[44:], 4) // schema format
+	binary.BigEndian.PutUint32(h[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(h[92:], 1) // the size above is valid
+	binary.BigEndian.PutUint32(h[96:], 3031001)
+
+	for _, p := range db.pages {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newPage adds an empty page, returning its number
+func (db *sqliteDB) newPage() (int, []byte) {
+	p := make([]byte, sqlitePageSize)
+	db.pages = append(db.pages, p)
+	return len(db.pages), p
+}
+
+// sqliteChild is a page of the b-tree being built and the largest rowid
+// stored under it
+type sqliteChild struct {
+	page  int
+	rowid int64
+}
+
+// writeTable writes the rows as a table b-tree, returning its root page
+func (db *sqliteDB) writeTable(rows [][]interface{}) int {
+	var (
+		level []sqliteChild
+		cells [][]byte
+		used  = 8
+	)
+	flush := func(rowid int64) {
+		n, p := db.newPage()
+		fillSQLitePage(p, 0, sqliteLeafTable, cells, 0)
+		level = append(level, sqliteChild{n, rowid})
+		cells, used = nil, 8
+	}
+	for i, row := range rows {
+		cell := db.leafCell(int64(i+1), sqliteRecord(row))
+		if used+len(cell)+2 > sqlitePageSize {
+			flush(int64(i))
+		}
+		cells = append(cells, cell)
+		used += len(cell) + 2
+	}
+	if len(cells) != 0 || len(level) == 0 {
+		flush(int64(len(rows)))
+	}
+
+	// an interior cell takes at most 2+4+9 bytes, the right-most child
+	// needs no cell
+	const fanout = (sqlitePageSize-12)/15 + 1
+	for len(level) > 1 {
+		parents := (len(level) + fanout - 1) / fanout
+		var next []sqliteChild
+		for i := 0; i < parents; i++ {
+			children := level[i*len(level)/parents : (i+1)*len(level)/parents]
+			var cells [][]byte
+			for _, c := range children[:len(children)-1] {
+				cell := make([]byte, 4, 13)
+				binary.BigEndian.PutUint32(cell, uint32(c.page))
+				cells = append(cells, appendSQLiteVarint(cell, uint64(c.rowid)))
+			}
+			last := children[len(children)-1]
+			n, p := db.newPage()
+			fillSQLitePage(p, 0, sqliteInteriorTable, cells, last.page)
+			next = append(next, sqliteChild{n, last.rowid})
+		}
+		level = next
+	}
+	return level[0].page
+}
+
+// leafCell returns the table leaf cell of the record, spilling the part
+// of it that does not fit into overflow pages
+func (db *sqliteDB) leafCell(rowid int64, payload []byte) []byte {
+	cell := appendSQLiteVarint(nil, uint64(len(payload)))
+	cell = appendSQLiteVarint(cell, uint64(rowid))
+	local := sqliteLocalPayload(len(payload))
+	cell = append(cell, payload[:local]...)
+	if local == len(payload) {
+		return cell
+	}
+
+	first := make([]byte, 4)
+	prev := first
+	for rest := payload[local:]; len(rest) != 0; {
+		n, p := db.newPage()
+		binary.BigEndian.PutUint32(prev, uint32(n))
+		rest = rest[copy(p[4:], rest):]
+		prev = p[:4]
+	}
+	return append(cell, first...)
+}
+
+// sqliteLocalPayload returns how much of the payload of the size is kept
+// in the table leaf cell itself
+func sqliteLocalPayload(size int) int {
+	const (
+		u = sqlitePageSize
+		x = u - 35
+		m = (u-12)*32/255 - 23
+	)
+	if size <= x {
+		return size
+	}
+	if k := m + (size-m)%(u-4); k <= x {
+		return k
+	}
+	return m
+}
+
+// fillSQLitePage lays out the b-tree page starting at the offset: the
+// header, the cell pointers and the cells packed at the end of the page;
+// it reports whether the cells fit
+func fillSQLitePage(p []byte, offset int, kind byte, cells [][]byte, right int) bool {
+	header := 8
+	if kind == sqliteInteriorTable {
+		header = 12
+		binary.BigEndian.PutUint32(p[offset+8:], uint32(right))
+	}
+	ptr, content := offset+header, len(p)
+	for _, cell := range cells {
+		content -= len(cell)
+		if content < ptr+2 {
+			return false
+		}
+		copy(p[content:], cell)
+		binary.BigEndian.PutUint16(p[ptr:], uint16(content))
+		ptr += 2
+	}
+	p[offset] = kind
+	binary.BigEndian.PutUint16(p[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(p[offset+5:], uint16(content))
+	return true
+}
+
+// sqliteRecord encodes the values in the record format
+func sqliteRecord(values []interface{}) []byte {
+	var types, body []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case int64:
+			switch n := sqliteIntSize(v); {
+			case v == 0:
+				types = append(types, 8)
+			case v == 1:
+				types = append(types, 9)
+			default:
+				types = appendSQLiteVarint(types, sqliteIntTypes[n])
+				var b [8]byte
+				binary.BigEndian.PutUint64(b[:], uint64(v))
+				body = append(body, b[8-n:]...)
+			}
+		case string:
+			types = appendSQLiteVarint(types, uint64(13+2*len(v)))
+			body = append(body, v...)
+		default:
+			types = append(types, 0)
+		}
+	}
+	size := len(types) + 1
+	for size != len(types)+len(appendSQLiteVarint(nil, uint64(size))) {
+		size++
+	}
+	record := appendSQLiteVarint(nil, uint64(size))
+	record = append(record, types...)
+	return append(record, body...)
+}
+
+// sqliteIntTypes are the serial types of the integers by their size
+var sqliteIntTypes = map[int]uint64{1: 1, 2: 2, 3: 3, 4: 4, 6: 5, 8: 6}
+
+// sqliteIntSize returns the number of bytes the integer is stored in
+func sqliteIntSize(v int64) int {
+	for _, n := range []int{1, 2, 3, 4, 6} {
+		if lim := int64(1) << uint(8*n-1); v >= -lim && v < lim {
+			return n
+		}
+	}
+	return 8
+}
+
+// appendSQLiteVarint appends the big-endian variable-length integer: 7
+// bits a byte with the high bit set on all but the last byte, the ninth
+// byte holding full 8 bits
+func appendSQLiteVarint(b []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v != 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
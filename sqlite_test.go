@@ -0,0 +1,117 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteVarint(t *testing.T) {
+	tests := map[uint64]string{
+		0:          "00",
+		127:        "7f",
+		128:        "8100",
+		16383:      "ff7f",
+		16384:      "818000",
+		1<<56 - 1:  "ffffffffffffff7f",
+		1 << 56:    "80c080808080808000",
+		^uint64(0): "ffffffffffffffffff",
+	}
+	for v, want := range tests {
+		if got := hex.EncodeToString(appendSQLiteVarint(nil, v)); got != want {
+			t.Errorf("for %d want %s, got %s", v, want, got)
+		}
+	}
+}
+
+func TestSQLiteRecord(t *testing.T) {
+	got := sqliteRecord([]interface{}{nil, int64(0), int64(1), int64(-2), int64(300), "ab"})
+	want := []byte{7, 0, 8, 9, 1, 2, 17, 0xfe, 0x01, 0x2c, 'a', 'b'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("want % x, got % x", want, got)
+	}
+}
+
+func TestSQLiteLocalPayload(t *testing.T) {
+	tests := map[int]int{
+		100:   100,
+		4061:  4061,
+		4062:  489,
+		8000:  3908,
+		10000: 1816,
+	}
+	for size, want := range tests {
+		if got := sqliteLocalPayload(size); got != want {
+			t.Errorf("for %d want %d, got %d", size, want, got)
+		}
+	}
+}
+
+func TestWriteSQLite(t *testing.T) {
+	var rows [][]interface{}
+	for i := 1; i <= 3000; i++ {
+		desc := strings.Repeat("описание ", i%7*400)
+		rows = append(rows, []interface{}{nil, fmt.Sprintf("выпуск %d", i), int64(i * 1000), desc})
+	}
+	tables := []sqliteTable{
+		{"episodes", "CREATE TABLE episodes (id INTEGER PRIMARY KEY, title TEXT, n INTEGER, description TEXT)", rows},
+		{"empty", "CREATE TABLE empty (x TEXT)", nil},
+	}
+	var buf bytes.Buffer
+	if err := writeSQLite(&buf, tables); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len()%sqlitePageSize != 0 {
+		t.Fatalf("database size %d is not a multiple of the page size", buf.Len())
+	}
+
+	sqlite, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 not found")
+	}
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "test.sqlite")
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	query := "PRAGMA integrity_check; SELECT count(*), sum(n), sum(length(description)) FROM episodes; " +
+		"SELECT title FROM episodes WHERE id = 2345; SELECT count(*) FROM empty;"
+	out, err := exec.Command(sqlite, file, query).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	var sum int
+	for i := 1; i <= 3000; i++ {
+		sum += len([]rune(strings.Repeat("описание ", i%7*400)))
+	}
+	want := fmt.Sprintf("ok\n3000|4501500000|%d\nвыпуск 2345\n0\n", sum)
+	if string(out) != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, out)
+	}
+}
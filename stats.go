@@ -0,0 +1,175 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// brandStats sums up what is known of a brand's episodes
+type brandStats struct {
+	Brand         string    `json:"brand"`
+	Title         string    `json:"title"`
+	Episodes      int       `json:"episodes"`
+	First         time.Time `json:"first"`
+	Last          time.Time `json:"last"`
+	CadenceDays   float64   `json:"cadenceDays,omitempty"`
+	NoAudio       int       `json:"noAudio"`
+	NoDescription int       `json:"noDescription"`
+}
+
+func statsCmd(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	path := fs.String("path", "./", "path the feeds are in")
+	brand := fs.String("brand", "", "brand numbers, comma-separated (defaults to all the feeds found)")
+	format := fs.String("format", "table", "output format (table or json)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s stats [options]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	var brands []string
+	if *brand != "" {
+		for _, b := range strings.Split(*brand, ",") {
+			brands = append(brands, strings.TrimSpace(b))
+		}
+	} else {
+		brands = feedBrands(*path)
+	}
+
+	var stats []brandStats
+	for _, b := range brands {
+		s, err := statsFor(*path, b)
+		if err != nil {
+			log.Fatal(err)
+		}
+		stats = append(stats, s)
+	}
+	if err := printStats(os.Stdout, stats, *format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// feedBrands finds the brands that have feeds in the path
+func feedBrands(path string) (brands []string) {
+	files, _ := filepath.Glob(feedFilename(path, "*"))
+	for _, file := range files {
+		// not the archives or the seasons
+		name := filepath.Base(file)
+		if m := brandFileRe.FindStringSubmatch(name); m != nil && filepath.Base(feedFilename("", m[1])) == name {
+			brands = append(brands, m[1])
+		}
+	}
+	sort.Strings(brands)
+	return
+}
+
+// brandArchives returns the per-year archive files of the brand
+func brandArchives(path, brand string) (archives []string) {
+	files, _ := filepath.Glob(feedFilename(path, brand+"-*"))
+	for _, file := range files {
+		if _, ok := archiveYear(file, brand); ok {
+			archives = append(archives, file)
+		}
+	}
+	return
+}
+
+// statsFor gathers the statistics of the brand from its feed and the
+// archives, the generated files being all that is kept of the episodes
+func statsFor(path, brand string) (s brandStats, err error) {
+	s.Brand = brand
+	feed, err := readFeed(feedFilename(path, brand))
+	if err != nil {
+		return
+	}
+	s.Title = feed.Title
+
+	items := feed.Items
+	for _, file := range brandArchives(path, brand) {
+		if a, err := readFeed(file); err == nil {
+			items = append(items, a.Items...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var dates []time.Time
+	for _, ri := range items {
+		if seen[ri.Guid] {
+			continue
+		}
+		seen[ri.Guid] = true
+		item := itemFromRss(ri)
+		s.Episodes++
+		if item.Enclosure == nil || item.Enclosure.Url == "" {
+			s.NoAudio++
+		}
+		if strings.TrimSpace(item.Description) == "" {
+			s.NoDescription++
+		}
+		if !item.Created.IsZero() {
+			dates = append(dates, item.Created)
+		}
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	if len(dates) != 0 {
+		s.First, s.Last = dates[0], dates[len(dates)-1]
+	}
+	if len(dates) > 1 {
+		s.CadenceDays = s.Last.Sub(s.First).Hours() / 24 / float64(len(dates)-1)
+	}
+	return
+}
+
+func printStats(w io.Writer, stats []brandStats, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case "table":
+		date := func(t time.Time) string {
+			if t.IsZero() {
+				return "-"
+			}
+			return t.In(moscow).Format("2006-01-02")
+		}
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "BRAND\tEPISODES\tFIRST\tLAST\tCADENCE\tNO AUDIO\tNO DESCRIPTION\tTITLE")
+		for _, s := range stats {
+			cadence := "-"
+			if s.CadenceDays != 0 {
+				cadence = fmt.Sprintf("%.1fd", s.CadenceDays)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%d\t%d\t%s\n", s.Brand, s.Episodes, date(s.First), date(s.Last), cadence, s.NoAudio, s.NoDescription, s.Title)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus-rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := dir + string(filepath.Separator)
+
+	feed := helperLoadBytes(t, "TestServedFeed.golden")
+	writeFile(feed, feedFilename(path, "57083"))
+	// the archive repeats the episodes of the feed, they are counted once
+	writeFile(feed, archiveFilename(path, "57083", 2019))
+	// the seasons are neither brands nor archives
+	writeFile(feed, seasonFilename(path, "57083", 2020))
+
+	if got, want := feedBrands(path), []string{"57083"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want brands %v, got %v", want, got)
+	}
+
+	if got, want := brandArchives(path, "57083"), []string{archiveFilename(path, "57083", 2019)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want archives %v, got %v", want, got)
+	}
+
+	s, err := statsFor(path, "57083")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := printStats(&buf, []brandStats{s}, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if err := printStats(&buf, []brandStats{s}, "json"); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, buf.Bytes(), filepath.Join("testdata", t.Name()+".golden"))
+
+	if _, err := statsFor(path, "1"); err == nil {
+		t.Error("no error for a missing feed")
+	}
+}
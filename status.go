@@ -0,0 +1,151 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+var statusTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"time": func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return t.In(moscow).Format("02.01.2006 15:04")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Состояние лент</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+.brand { border-left: 4px solid #2a2; padding: 0 .5em; margin: 0 0 1em; }
+.brand.failing { border-color: #c22; }
+.error { color: #c22; }
+dl { display: grid; grid-template-columns: auto 1fr; gap: 0 1em; margin: 0; }
+dd { margin: 0; }
+</style>
+</head>
+<body>
+<h1>Состояние лент</h1>
+<p>{{.Healthy}} из {{len .Brands}} в порядке, {{time .Now}}</p>
+{{range .Brands}}<div class="brand{{if not .Healthy}} failing{{end}}">
+<h2>{{if .Title}}{{.Title}} ({{.Brand}}){{else}}{{.Brand}}{{end}}</h2>
+<dl>
+<dt>Последнее обновление</dt><dd>{{time .LastRefresh}}</dd>
+<dt>Выпусков</dt><dd>{{.Episodes}}</dd>
+<dt>Следующее обновление</dt><dd>{{time .NextRun}}</dd>
+{{if .LastError}}<dt>Ошибка</dt><dd class="error">{{.LastError}} ({{time .LastErrorTime}})</dd>
+{{end}}</dl>
+</div>
+{{end}}</body>
+</html>
+`))
+
+// statusEntry is a brand as shown by the status page
+type statusEntry struct {
+	brandStatus
+	Brand       string
+	Title       string
+	Healthy     bool
+	LastRefresh *time.Time
+}
+
+// statusPage shows the state of every brand for a human to check
+func (d *daemon) statusPage(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	page := struct {
+		Now     *time.Time
+		Healthy int
+		Brands  []statusEntry
+	}{Now: &now}
+
+	d.mu.Lock()
+	for _, bc := range d.brands {
+		e := statusEntry{brandStatus: d.status[bc.Brand], Brand: bc.Brand}
+		if res, ok := d.results[bc.Brand]; ok && res.feed != nil {
+			e.Title = res.feed.Title
+		}
+		if e.Healthy = e.healthy(); e.Healthy {
+			page.Healthy++
+		}
+		e.LastRefresh = e.LastSuccess
+		if e.LastErrorTime != nil && !e.Healthy {
+			e.LastRefresh = e.LastErrorTime
+		}
+		page.Brands = append(page.Brands, e)
+	}
+	d.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := statusTemplate.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// feedStatus is the brand's state as served by /status/{brand}.json
+type feedStatus struct {
+	Brand              string     `json:"brand"`
+	Healthy            bool       `json:"healthy"`
+	LastSuccess        *time.Time `json:"lastSuccess"`
+	LastError          string     `json:"lastError,omitempty"`
+	LastErrorTime      *time.Time `json:"lastErrorTime,omitempty"`
+	NewEpisodesLastRun int        `json:"newEpisodesLastRun"`
+	ItemCount          int        `json:"itemCount"`
+	NextRun            *time.Time `json:"nextRun,omitempty"`
+}
+
+// statusJSON serves the brand's state for the monitoring scripts
+func (d *daemon) statusJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	m := statusFileRe.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if _, ok := d.triggers[m[1]]; !ok {
+		http.Error(w, "unknown brand "+m[1], http.StatusNotFound)
+		return
+	}
+
+	d.mu.Lock()
+	s := d.status[m[1]]
+	d.mu.Unlock()
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, feedStatus{
+		Brand:              m[1],
+		Healthy:            s.healthy(),
+		LastSuccess:        s.LastSuccess,
+		LastError:          s.LastError,
+		LastErrorTime:      s.LastErrorTime,
+		NewEpisodesLastRun: s.NewEpisodes,
+		ItemCount:          s.Episodes,
+		NextRun:            s.NextRun,
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestStatusPage(t *testing.T) {
+	d := helperDaemon("57083", "59798", "60000")
+	earlier := time.Date(2026, 3, 1, 9, 0, 0, 0, moscow)
+	later, next := earlier.Add(time.Hour), earlier.Add(2*time.Hour)
+	d.status["57083"] = brandStatus{LastSuccess: &later, Episodes: 42, NextRun: &next}
+	d.status["59798"] = brandStatus{LastSuccess: &earlier, LastError: "server returned 503 <Service Unavailable>", LastErrorTime: &later}
+	d.results["57083"] = result{feed: &brandFeed{Feed: feeds.Feed{Title: "Аэростат"}}}
+
+	w := httptest.NewRecorder()
+	d.handler().ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("got Content-Type %s", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"<p>1 из 3 в порядке",
+		`<div class="brand">
+<h2>Аэростат (57083)</h2>`,
+		"<dd>01.03.2026 10:00</dd>\n<dt>Выпусков</dt><dd>42</dd>\n<dt>Следующее обновление</dt><dd>01.03.2026 11:00</dd>",
+		`<div class="brand failing">
+<h2>59798</h2>`,
+		`<dd class="error">server returned 503 &lt;Service Unavailable&gt; (01.03.2026 10:00)</dd>`,
+		`<h2>60000</h2>
+<dl>
+<dt>Последнее обновление</dt><dd>—</dd>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("no %q in\n%s", want, body)
+		}
+	}
+}
+
+func TestStatusPageAuth(t *testing.T) {
+	defer func(a string) { basicAuth = a }(basicAuth)
+	basicAuth = "admin:pw"
+
+	w := httptest.NewRecorder()
+	helperDaemon("57083").handler().ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("want 401, got %d", w.Code)
+	}
+}
+
+func TestScheduled(t *testing.T) {
+	d := helperDaemon("57083")
+	next := time.Now().Add(time.Hour)
+	d.scheduled("57083", next)
+	if s := d.status["57083"]; s.NextRun == nil || !s.NextRun.Equal(next) {
+		t.Errorf("got %+v", s)
+	}
+	d.scheduled("57083", time.Time{})
+	if s := d.status["57083"]; s.NextRun != nil {
+		t.Errorf("got %+v", s)
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	d := newDaemon([]brandConfig{{Brand: "57083"}, {Brand: "59798", Token: "s3cret"}})
+	earlier := time.Date(2026, 3, 1, 9, 0, 0, 0, moscow)
+	later := earlier.Add(time.Hour)
+	d.status["57083"] = brandStatus{LastSuccess: &later, LastError: "timeout", LastErrorTime: &earlier, Episodes: 42, NewEpisodes: 2}
+
+	testdata := []struct {
+		path string
+		want int
+		body string
+	}{
+		{"/status/57083.json", http.StatusOK, `{
+  "brand": "57083",
+  "healthy": true,
+  "lastSuccess": "2026-03-01T10:00:00+03:00",
+  "lastError": "timeout",
+  "lastErrorTime": "2026-03-01T09:00:00+03:00",
+  "newEpisodesLastRun": 2,
+  "itemCount": 42
+}
+`},
+		{"/status/59798.json", http.StatusUnauthorized, ""},
+		{"/status/59798.json?token=s3cret", http.StatusOK, `{
+  "brand": "59798",
+  "healthy": false,
+  "lastSuccess": null,
+  "newEpisodesLastRun": 0,
+  "itemCount": 0
+}
+`},
+		{"/status/60000.json", http.StatusNotFound, ""},
+		{"/status/57083", http.StatusNotFound, ""},
+	}
+	for _, tc := range testdata {
+		w := httptest.NewRecorder()
+		d.handler().ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: want %d, got %d", tc.path, tc.want, w.Code)
+			continue
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Errorf("%s: got\n%s", tc.path, w.Body.String())
+		}
+	}
+}
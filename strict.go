@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// strictModes are the checks enabled with -strict: "apple" fails the
+// feeds Apple Podcasts would reject, "extract" fails the feeds where
+// anything could not be extracted
+var strictModes = make(map[string]bool)
+
+// parseStrict parses the comma-separated -strict modes
+func parseStrict(s string) (map[string]bool, error) {
+	modes := make(map[string]bool)
+	for _, mode := range strings.Split(s, ",") {
+		switch mode = strings.TrimSpace(mode); mode {
+		case "":
+		case "apple", "extract":
+			modes[mode] = true
+		default:
+			return nil, fmt.Errorf("unknown strict mode %q", mode)
+		}
+	}
+	return modes, nil
+}
+
+// extractionGaps lists what is missing from the feed that should
+// have been found on the site
+func extractionGaps(feed *brandFeed) (gaps []string) {
+	if feed.Description == "" {
+		gaps = append(gaps, "no programme description")
+	}
+	for _, item := range feed.Items {
+		link := item.Id
+		if item.Link != nil {
+			link = item.Link.Href
+		}
+		if item.Description == "" {
+			gaps = append(gaps, fmt.Sprintf("%s: no description", link))
+		}
+		if item.Created.IsZero() {
+			gaps = append(gaps, fmt.Sprintf("%s: no date", link))
+		}
+		if item.Enclosure == nil || item.Enclosure.Url == "" {
+			gaps = append(gaps, fmt.Sprintf("%s: no audio", link))
+		}
+	}
+	return
+}
+
+// artworkProblems checks the dimensions of the feed's artwork unless it
+// is the one of the feed written before: the image is not downloaded
+// on every run, only when it changes
+func artworkProblems(feed *brandFeed, file string) []string {
+	if feed.Image == nil || feed.Image.Url == "" {
+		return nil
+	}
+	if old, err := readFeed(file); err == nil && old.Image != nil && old.Image.Url == feed.Image.Url {
+		return nil
+	}
+	if err := checkImage(feed.Image.Url); err != nil {
+		return []string{fmt.Sprintf("channel itunes:image %s: %v", feed.Image.Url, err)}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestParseStrict(t *testing.T) {
+	tests := map[string]map[string]bool{
+		"":               {},
+		"apple":          {"apple": true},
+		"apple, extract": {"apple": true, "extract": true},
+	}
+	for s, want := range tests {
+		got, err := parseStrict(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want %v, got %v", s, want, got)
+		}
+	}
+	if _, err := parseStrict("apple,google"); err == nil {
+		t.Error("no error for unknown mode")
+	}
+}
+
+func TestExtractionGaps(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{Description: "Передача"}}
+	feed.Add(&feedItem{Item: &feeds.Item{
+		Link:        &feeds.Link{Href: "full"},
+		Description: "Выпуск",
+		Created:     time.Now(),
+		Enclosure:   enclosure("1"),
+	}})
+	if gaps := extractionGaps(feed); len(gaps) != 0 {
+		t.Errorf("want no gaps, got %v", gaps)
+	}
+
+	feed.Description = ""
+	feed.Add(&feedItem{Item: &feeds.Item{Link: &feeds.Link{Href: "empty"}, Enclosure: enclosure("")}})
+	want := []string{"no programme description", "empty: no description", "empty: no date", "empty: no audio"}
+	if gaps := extractionGaps(feed); !reflect.DeepEqual(gaps, want) {
+		t.Errorf("want %v, got %v", want, gaps)
+	}
+}
+
+func TestArtworkProblems(t *testing.T) {
+	var fetched int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		_ = png.Encode(w, image.NewGray(image.Rect(0, 0, 300, 300)))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "feed.rss")
+
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}, Image: &feeds.Image{Url: server.URL + "/image.png"}}}
+	got := artworkProblems(feed, file)
+	if len(got) != 1 || !strings.Contains(got[0], "image is 300x300") {
+		t.Errorf("want the small artwork reported, got %v", got)
+	}
+
+	// the artwork of the feed written before is not fetched again
+	writeFile(createFeed(feed), file)
+	if got := artworkProblems(feed, file); len(got) != 0 || fetched != 1 {
+		t.Errorf("want the published artwork skipped, got %v after %d fetches", got, fetched)
+	}
+	feed.Image.Url = server.URL + "/new.png"
+	if got := artworkProblems(feed, file); len(got) != 1 || fetched != 2 {
+		t.Errorf("want the changed artwork checked, got %v after %d fetches", got, fetched)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// subscribePage tells to generate the page with the subscribe links
+// for each feed
+var subscribePage bool
+
+var subscribeTemplate = template.Must(template.New("subscribe").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Подписаться: {{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 40em; margin: 1em auto; padding: 0 1em; }
+img.cover { max-width: 200px; }
+ul.apps { list-style: none; padding: 0; }
+ul.apps li { margin: .5em 0; }
+ul.apps a { display: inline-block; padding: .5em 1em; border: 1px solid #888; border-radius: .3em; text-decoration: none; }
+.qr { width: 200px; height: 200px; }
+input { width: 100%; }
+</style>
+</head>
+<body>
+{{with .Image}}<img class="cover" src="{{.}}" alt="">
+{{end}}<h1>{{.Title}}</h1>
+{{with .Description}}<p>{{.}}</p>
+{{end}}<h2>Подписаться</h2>
+<ul class="apps">
+<li><a href="{{.Apple}}">Apple Podcasts</a></li>
+<li><a href="{{.Overcast}}">Overcast</a></li>
+<li><a href="{{.AntennaPod}}">AntennaPod</a></li>
+<li><a href="{{.PodcastURI}}">Другое приложение для подкастов</a></li>
+<li><a href="{{.Feed}}">RSS</a></li>
+</ul>
+<p>Или скопируйте адрес ленты в своё приложение:</p>
+<p><input type="text" readonly value="{{.Feed}}" onclick="this.select()"></p>
+{{with .QR}}<p>Или наведите камеру телефона:</p>
+<div class="qr">{{.}}</div>
+{{end}}</body>
+</html>
+`))
+
+// subscribeLinks is what the subscribe page is rendered with
+type subscribeLinks struct {
+	Title       string
+	Description string
+	Image       string
+	Feed        string
+	Apple       template.URL
+	Overcast    template.URL
+	AntennaPod  string
+	PodcastURI  template.URL
+	QR          template.HTML
+}
+
+// subscribeFilename is the subscribe page of the brand
+func subscribeFilename(path, brand string) string {
+	return path + "radiorus-" + brand + ".html"
+}
+
+// newSubscribeLinks makes the links to subscribe to the feed at the
+// URL with
+func newSubscribeLinks(feed *brandFeed, feedLink string) subscribeLinks {
+	rest := feedLink
+	if i := strings.Index(rest, "://"); i >= 0 {
+		rest = rest[i+3:]
+	}
+	l := subscribeLinks{
+		Title:       feed.Title,
+		Description: feed.Description,
+		Feed:        feedLink,
+		Apple:       template.URL("podcasts://" + rest),
+		Overcast:    template.URL("overcast://x-callback-url/add?url=" + url.QueryEscape(feedLink)),
+		AntennaPod:  "https://antennapod.org/deeplink/subscribe?url=" + url.QueryEscape(feedLink) + "&title=" + url.QueryEscape(feed.Title),
+		PodcastURI:  template.URL("podcast://" + rest),
+	}
+	if feed.Image != nil {
+		l.Image = feed.Image.Url
+	}
+	if q, err := encodeQR(feedLink); err == nil {
+		l.QR = template.HTML(q.svg())
+	}
+	return l
+}
+
+// createSubscribePage renders the page with the subscribe links and
+// the QR code of the feed at the URL
+func createSubscribePage(feed *brandFeed, feedLink string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := subscribeTemplate.Execute(&buf, newSubscribeLinks(feed, feedLink)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
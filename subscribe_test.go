@@ -0,0 +1,54 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestCreateSubscribePage(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{
+		Title:       "Аэростат & Co",
+		Description: "Программа Бориса Гребенщикова",
+		Image:       &feeds.Image{Url: "https://example.com/cover.jpg"},
+	}}
+	page, err := createSubscribePage(feed, "https://podcasts.example.com/radiorus-57083.rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", t.Name()+".golden")
+	assertGolden(t, page, golden)
+}
+
+func TestSubscribeLinks(t *testing.T) {
+	l := newSubscribeLinks(&brandFeed{Feed: feeds.Feed{Title: "Аэростат"}}, "http://example.com/radiorus-57083.rss")
+	for got, want := range map[string]string{
+		string(l.Apple):      "podcasts://example.com/radiorus-57083.rss",
+		string(l.PodcastURI): "podcast://example.com/radiorus-57083.rss",
+		string(l.Overcast):   "overcast://x-callback-url/add?url=http%3A%2F%2Fexample.com%2Fradiorus-57083.rss",
+		l.AntennaPod:         "https://antennapod.org/deeplink/subscribe?url=http%3A%2F%2Fexample.com%2Fradiorus-57083.rss&title=%D0%90%D1%8D%D1%80%D0%BE%D1%81%D1%82%D0%B0%D1%82",
+	} {
+		if got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+	if l.QR == "" {
+		t.Error("no QR code")
+	}
+}
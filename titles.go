@@ -0,0 +1,182 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+var (
+	// titlePrefix is the -strip-prefix option: when set without
+	// a value the feed title is stripped from the item titles
+	titlePrefix prefixFlag
+
+	// titleTemplate builds the item titles if set, it is parsed
+	// from titleTmpl
+	titleTmpl     string
+	titleTemplate *template.Template
+
+	episodeNumberRe = regexp.MustCompile(`№\s*(\d+)`)
+	trailingNumRe   = regexp.MustCompile(`(\d+)\s*$`)
+)
+
+type prefixFlag struct {
+	on     bool
+	prefix string
+}
+
+func (p *prefixFlag) String() string {
+	if p == nil || !p.on {
+		return ""
+	}
+	return p.prefix
+}
+
+func (p *prefixFlag) Set(s string) error {
+	switch s {
+	case "true":
+		p.on, p.prefix = true, ""
+	case "false":
+		p.on, p.prefix = false, ""
+	default:
+		p.on, p.prefix = true, s
+	}
+	return nil
+}
+
+func (p *prefixFlag) IsBoolFlag() bool { return true }
+
+// stripPrefixes removes the prefix, the feed title if empty, from the
+// titles of the items
+func stripPrefixes(feed *brandFeed, prefix string) {
+	if prefix == "" {
+		prefix = feed.Title
+	}
+	for _, item := range feed.Items {
+		item.Title = stripPrefix(item.Title, prefix)
+	}
+}
+
+// stripPrefix removes the prefix from the title, ignoring case and
+// quotes, along with the punctuation that separates them; the title
+// is left alone if nothing would remain of it
+func stripPrefix(title, prefix string) string {
+	isQuote := func(r rune) bool { return strings.ContainsRune(`"'«»“”„`, r) }
+	prefix = strings.TrimFunc(prefix, func(r rune) bool { return isQuote(r) || unicode.IsSpace(r) })
+	if prefix == "" {
+		return title
+	}
+
+	rest := strings.TrimLeftFunc(title, isQuote)
+	if len(rest) < len(prefix) || !strings.EqualFold(rest[:len(prefix)], prefix) {
+		return title
+	}
+	rest = rest[len(prefix):]
+	if r := []rune(rest); len(r) != 0 && (unicode.IsLetter(r[0]) || unicode.IsDigit(r[0])) {
+		// the prefix is only the beginning of a word
+		return title
+	}
+	rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+		return isQuote(r) || unicode.IsSpace(r) || strings.ContainsRune(".,:;-–—|", r)
+	})
+	if rest == "" {
+		return title
+	}
+	return rest
+}
+
+// titleFields are what the -title-template can use
+type titleFields struct {
+	Date           titleDate
+	ProgrammeTitle string
+	RawTitle       string
+	Title          string
+	EpisodeNumber  string
+}
+
+// titleDate prints as the date of broadcast in Moscow, the time
+// methods are still available to format it otherwise
+type titleDate struct {
+	time.Time
+}
+
+func (d titleDate) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.In(moscow).Format("02.01.2006")
+}
+
+// parseTitleTemplate parses the -title-template option and tries it
+// out, so that unknown fields are reported right away
+func parseTitleTemplate(s string) (*template.Template, error) {
+	t, err := template.New("title").Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(ioutil.Discard, titleFields{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// applyTitleTemplate replaces the titles of the items with the ones
+// made from the template; raw are the titles as found on the site
+func applyTitleTemplate(feed *brandFeed, tmpl *template.Template, raw []string) {
+	for i, item := range feed.Items {
+		f := titleFields{
+			Date:           titleDate{item.Created},
+			ProgrammeTitle: strings.Trim(feed.Title, `"«»`),
+			RawTitle:       raw[i],
+			Title:          item.Title,
+			EpisodeNumber:  episodeNumber(raw[i]),
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, f); err != nil {
+			noticef("could not make title for %s: %v", item.Link.Href, err)
+			continue
+		}
+		if t := strings.TrimSpace(buf.String()); t != "" {
+			item.Title = t
+		}
+	}
+}
+
+// episodeNumber finds the episode number in the title, either marked
+// with № or trailing
+func episodeNumber(title string) string {
+	if m := episodeNumberRe.FindStringSubmatch(title); m != nil {
+		return m[1]
+	}
+	if m := trailingNumRe.FindStringSubmatch(title); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func itemTitles(items []*feedItem) []string {
+	titles := make([]string, len(items))
+	for i, item := range items {
+		titles[i] = item.Title
+	}
+	return titles
+}
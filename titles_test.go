@@ -0,0 +1,115 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		title, prefix, want string
+	}{
+		{`"Аэростат". Новые имена 27`, `"Аэростат"`, "Новые имена 27"},
+		{"АЭРОСТАТ: The Cure", "Аэростат", "The Cure"},
+		{"«Аэростат» — Новогодние притчи", `"Аэростат"`, "Новогодние притчи"},
+		{"Новые имена 27", "Аэростат", "Новые имена 27"},
+		{"Аэростатика", "Аэростат", "Аэростатика"},
+		{"Аэростат", "Аэростат", "Аэростат"},
+		{"Аэростат 27", "", "Аэростат 27"},
+	}
+	for _, tc := range tests {
+		if got := stripPrefix(tc.title, tc.prefix); got != tc.want {
+			t.Errorf("%q without %q: want %q, got %q", tc.title, tc.prefix, tc.want, got)
+		}
+	}
+}
+
+func TestStripPrefixes(t *testing.T) {
+	feed := &brandFeed{Feed: feeds.Feed{Title: `"Аэростат"`}}
+	feed.Add(&feedItem{Item: &feeds.Item{Title: "Аэростат. The Cure"}})
+	stripPrefixes(feed, "")
+	if want := "The Cure"; feed.Items[0].Title != want {
+		t.Errorf("want %q, got %q", want, feed.Items[0].Title)
+	}
+}
+
+func TestPrefixFlag(t *testing.T) {
+	tests := map[string]prefixFlag{
+		"":                    {},
+		"-strip-prefix":       {on: true},
+		"-strip-prefix=БГ":    {on: true, prefix: "БГ"},
+		"-strip-prefix=false": {},
+	}
+	for arg, want := range tests {
+		var p prefixFlag
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		fs.Var(&p, "strip-prefix", "")
+		var args []string
+		if arg != "" {
+			args = []string{arg}
+		}
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		if p != want {
+			t.Errorf("%q: want %+v, got %+v", arg, want, p)
+		}
+	}
+}
+
+func TestTitleTemplate(t *testing.T) {
+	for _, bad := range []string{"{{.Date", "{{.Nope}}"} {
+		if _, err := parseTitleTemplate(bad); err == nil {
+			t.Errorf("%q: no error", bad)
+		}
+	}
+
+	tmpl, err := parseTitleTemplate(`{{.Date}} {{.ProgrammeTitle}} #{{.EpisodeNumber}}: {{.Title}} ({{.Date.Format "2006"}})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := &brandFeed{Feed: feeds.Feed{Title: `"Аэростат"`}}
+	feed.Add(&feedItem{Item: &feeds.Item{
+		Title:   "British Blues",
+		Link:    &feeds.Link{Href: "l"},
+		Created: time.Date(2020, time.October, 18, 14, 10, 0, 0, moscow),
+	}})
+	applyTitleTemplate(feed, tmpl, []string{"Выпуск № 805. British Blues"})
+	if want := "18.10.2020 Аэростат #805: British Blues (2020)"; feed.Items[0].Title != want {
+		t.Errorf("want %q, got %q", want, feed.Items[0].Title)
+	}
+}
+
+func TestEpisodeNumber(t *testing.T) {
+	tests := map[string]string{
+		"Выпуск № 805. British Blues": "805",
+		"Выпуск №12":                  "12",
+		"Новые имена 27":              "27",
+		"The Cure":                    "",
+	}
+	for title, want := range tests {
+		if got := episodeNumber(title); got != want {
+			t.Errorf("%q: want %q, got %q", title, want, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// certLoader serves the certificate from the files, reloading it when
+// the files change so that renewed certificates are picked up without
+// a restart
+type certLoader struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func newCertLoader(certFile, keyFile string) (*certLoader, error) {
+	l := &certLoader{certFile: certFile, keyFile: keyFile}
+	if _, err := l.getCertificate(nil); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// getCertificate is suitable for tls.Config.GetCertificate
+func (l *certLoader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modified := l.modTime()
+	if l.cert != nil && !modified.After(l.modified) {
+		return l.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		if l.cert != nil {
+			// probably caught in the middle of renewal, keep the old one
+			return l.cert, nil
+		}
+		return nil, err
+	}
+	l.cert, l.modified = &cert, modified
+	return l.cert, nil
+}
+
+// modTime returns the latest modification time of the files
+func (l *certLoader) modTime() (t time.Time) {
+	for _, file := range []string{l.certFile, l.keyFile} {
+		if fi, err := os.Stat(file); err == nil && fi.ModTime().After(t) {
+			t = fi.ModTime()
+		}
+	}
+	return
+}
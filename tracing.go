@@ -0,0 +1,215 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLP span kinds
+const (
+	spanInternal = 1
+	spanClient   = 3
+)
+
+// span is a unit of work traced and exported via OTLP
+type span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+	end      time.Time
+	attrs    [][2]string
+	err      error
+}
+
+type spanKey struct{}
+
+var (
+	spansMu sync.Mutex
+	// spans are the finished spans waiting to be exported
+	spans []*span
+)
+
+// startSpan starts a span as a child of the one in the context, if any;
+// it returns nil span if tracing is disabled
+func startSpan(ctx context.Context, name string, kind int, attrs ...string) (context.Context, *span) {
+	if otlpEndpoint == "" {
+		return ctx, nil
+	}
+
+	s := &span{name: name, kind: kind, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else {
+		_, _ = rand.Read(s.traceID[:])
+	}
+	_, _ = rand.Read(s.spanID[:])
+	for i := 0; i+1 < len(attrs); i += 2 {
+		s.setAttr(attrs[i], attrs[i+1])
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *span) setAttr(key, value string) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, [2]string{key, value})
+}
+
+// finish ends the span with the error, if any, and queues it for export
+func (s *span) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.end, s.err = time.Now(), err
+	spansMu.Lock()
+	spans = append(spans, s)
+	spansMu.Unlock()
+}
+
+// flushSpans exports the finished spans to the OTLP/HTTP endpoint
+func flushSpans() error {
+	spansMu.Lock()
+	batch := spans
+	spans = nil
+	spansMu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpRequest(batch))
+	if err != nil {
+		return err
+	}
+	url := strings.TrimRight(otlpEndpoint, "/") + "/v1/traces"
+	res, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP endpoint %s: %s", url, res.Status)
+	}
+	return nil
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+func keyValue(key, value string) (kv otlpKeyValue) {
+	kv.Key = key
+	kv.Value.StringValue = value
+	return
+}
+
+// otlpRequest builds the OTLP/JSON export request for the spans
+func otlpRequest(batch []*span) interface{} {
+	var out []otlpSpan
+	for _, s := range batch {
+		o := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != [8]byte{} {
+			o.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, a := range s.attrs {
+			o.Attributes = append(o.Attributes, keyValue(a[0], a[1]))
+		}
+		if s.err != nil {
+			o.Status = otlpStatus{Code: 2, Message: s.err.Error()}
+		}
+		out = append(out, o)
+	}
+
+	type scope struct {
+		Name string `json:"name"`
+	}
+	type scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	type resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	type resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	return struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}{[]resourceSpans{{
+		Resource:   resource{Attributes: []otlpKeyValue{keyValue("service.name", "radiorus-rss")}},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: "radiorus-rss"}, Spans: out}},
+	}}}
+}
+
+// exportSpans flushes the spans periodically until the context is
+// cancelled
+func exportSpans(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := flushSpans(); err != nil {
+				log.Printf("could not export spans: %v", err)
+			}
+		}
+	}
+}
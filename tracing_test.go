@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+type helperOTLPSpans struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Spans []otlpSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func TestSpansDisabled(t *testing.T) {
+	defer func(e string) { otlpEndpoint = e }(otlpEndpoint)
+	otlpEndpoint = ""
+
+	ctx, sp := startSpan(context.Background(), "test", spanInternal)
+	if sp != nil || ctx != context.Background() {
+		t.Error("span started with tracing disabled")
+	}
+	sp.setAttr("key", "value")
+	sp.finish(nil)
+	if len(spans) != 0 {
+		t.Error("span queued with tracing disabled")
+	}
+}
+
+func TestTracing(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write(helperLoadBytes(t, "smotrim.57083"))
+	}))
+	defer upstream.Close()
+
+	var exported helperOTLPSpans
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("spans exported to %s", r.URL.Path)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &exported); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer collector.Close()
+
+	defer func(e string) { otlpEndpoint = e }(otlpEndpoint)
+	otlpEndpoint = collector.URL
+
+	ctx, root := startSpan(context.Background(), "generate", spanInternal, "brand", "57083")
+	item := &feedItem{Item: &feeds.Item{Link: &feeds.Link{Href: upstream.URL + "/episode"}}}
+	if err := describeEpisode(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := fetchPage(ctx, upstream.URL+"/broken")
+	root.finish(err)
+
+	if err := flushSpans(); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.ResourceSpans) != 1 || len(exported.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected export structure: %+v", exported)
+	}
+
+	got := make(map[string]otlpSpan)
+	for _, s := range exported.ResourceSpans[0].ScopeSpans[0].Spans {
+		got[s.Name+" "+fmt.Sprint(s.Status.Code)] = s
+		if s.TraceID != exported.ResourceSpans[0].ScopeSpans[0].Spans[0].TraceID {
+			t.Errorf("span %s in a different trace", s.Name)
+		}
+	}
+	if len(got) != 4 {
+		t.Fatalf("want 4 distinct spans, got %v", got)
+	}
+
+	generate, describe := got["generate 2"], got["describeEpisode 0"]
+	if generate.ParentSpanID != "" {
+		t.Error("root span has a parent")
+	}
+	if describe.ParentSpanID != generate.SpanID {
+		t.Error("describeEpisode is not a child of generate")
+	}
+	if got["getPage 0"].ParentSpanID != describe.SpanID {
+		t.Error("getPage is not a child of describeEpisode")
+	}
+	if got["getPage 2"].ParentSpanID != generate.SpanID {
+		t.Error("failed getPage is not a child of generate")
+	}
+	if got["getPage 2"].Kind != spanClient {
+		t.Error("getPage is not a client span")
+	}
+
+	if err := flushSpans(); err != nil {
+		t.Error(err)
+	}
+}
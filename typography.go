@@ -0,0 +1,99 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// typography makes the quotes, dashes and ellipses in the titles and
+// descriptions consistent, the site mixes all the styles there are
+var typography bool
+
+var dashRe = regexp.MustCompile(`(^|\s)(?:--?|–|—)(\s)`)
+
+// typographFeed applies typographText to the titles and descriptions
+// of the feed and its items
+func typographFeed(feed *brandFeed) {
+	feed.Title = typographText(feed.Title)
+	feed.Description = typographText(feed.Description)
+	for _, item := range feed.Items {
+		item.Title = typographText(item.Title)
+		item.Description = typographText(item.Description)
+	}
+}
+
+// typographText turns the quotes into «ёлочки», with „лапки“ inside,
+// the hyphens and en dashes standing alone into em dashes, and three
+// dots into an ellipsis
+func typographText(s string) string {
+	s = strings.Replace(s, "...", "…", -1)
+	s = dashRe.ReplaceAllString(s, "$1—$2")
+	return typographQuotes(s)
+}
+
+// typographQuotes makes the quotes of the text consistent; the
+// ambiguous quotes open after a space, a bracket or a dash, close
+// after anything else, and follow the quote right before them
+func typographQuotes(s string) string {
+	var (
+		b      strings.Builder
+		depth  int
+		prev   rune = ' '
+		quoted bool // prev is a quote, opening if opened
+		opened bool
+	)
+	b.Grow(len(s))
+	for _, r := range s {
+		var opening bool
+		switch r {
+		case '«', '„':
+			opening = true
+		case '»':
+		case '"', '“', '”':
+			if quoted {
+				opening = opened
+			} else {
+				opening = unicode.IsSpace(prev) || strings.ContainsRune("([{—", prev)
+			}
+		default:
+			b.WriteRune(r)
+			prev, quoted = r, false
+			continue
+		}
+		if opening {
+			if depth == 0 {
+				b.WriteRune('«')
+			} else {
+				b.WriteRune('„')
+			}
+			depth++
+		} else {
+			if depth > 0 {
+				depth--
+			}
+			if depth == 0 {
+				b.WriteRune('»')
+			} else {
+				b.WriteRune('“')
+			}
+		}
+		prev, quoted, opened = r, true, opening
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestTypographText(t *testing.T) {
+	tests := map[string]string{
+		`Ансамбль "Pied Pipers"`:               "Ансамбль «Pied Pipers»",
+		`Фильм "Ирония "судьбы""`:              "Фильм «Ирония „судьбы“»",
+		`""Аэростат""`:                         "«„Аэростат“»",
+		`“Аэростат” и „Аэростат“ и «Аэростат»`: "«Аэростат» и «Аэростат» и «Аэростат»",
+		`"Здравствуйте!" - сказал он...`:       "«Здравствуйте!» — сказал он…",
+		`- Кто там?`:                           "— Кто там?",
+		"Новые имена -- 27 – 28":               "Новые имена — 27 — 28",
+		"1941-1945, рок-н-ролл":                "1941-1945, рок-н-ролл",
+		`(“цитата”)`:                           "(«цитата»)",
+		"":                                     "",
+	}
+	for in, want := range tests {
+		if got := typographText(in); got != want {
+			t.Errorf("%q: want %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestTypographFeed(t *testing.T) {
+	item := &feedItem{Item: &feeds.Item{Title: `"Новые имена" - 27`, Description: "Слушайте..."}}
+	feed := &brandFeed{Feed: feeds.Feed{Title: `"Аэростат"`, Description: `Передача - "Аэростат"`}, Items: []*feedItem{item}}
+
+	typographFeed(feed)
+
+	if feed.Title != "«Аэростат»" || feed.Description != "Передача — «Аэростат»" {
+		t.Errorf("feed not typographed: %q %q", feed.Title, feed.Description)
+	}
+	if item.Title != "«Новые имена» — 27" || item.Description != "Слушайте…" {
+		t.Errorf("item not typographed: %q %q", item.Title, item.Description)
+	}
+}
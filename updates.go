@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// trackUpdates makes the items edited on the site after publication
+// carry the time the change was noticed
+var trackUpdates bool
+
+// itemState is what is remembered about an item between runs
+type itemState struct {
+	Hash    string    `json:"hash"`
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+// itemsStateFile returns the file the brand's item states are kept in
+// between runs
+func itemsStateFile(path, brand string) string {
+	return filepath.Join(path, "."+brand+".items.json")
+}
+
+// contentHash is the hash of what the listeners see of the item
+func contentHash(item *feedItem) string {
+	h := sha256.New()
+	h.Write([]byte(item.Title))
+	h.Write([]byte{0})
+	h.Write([]byte(item.Description))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// markUpdated compares the items with their states from the previous
+// run and sets the Updated time of the ones that changed since they
+// were first seen; the items that lack the description this time keep
+// their state, as the page must have failed to load
+func markUpdated(items []*feedItem, stateFile string, now time.Time) {
+	states := make(map[string]itemState)
+	if b, err := ioutil.ReadFile(stateFile); err == nil {
+		if err := json.Unmarshal(b, &states); err != nil {
+			log.Printf("ignoring %s: %v", stateFile, err)
+		}
+	}
+
+	fresh := make(map[string]itemState, len(items))
+	for _, item := range items {
+		if item.Id == "" {
+			continue
+		}
+		st, ok := states[item.Id]
+		if item.Description != "" {
+			h := contentHash(item)
+			if ok && st.Hash != h {
+				st.Updated = now
+			}
+			st.Hash = h
+		}
+		if st.Hash == "" {
+			continue
+		}
+		item.Updated = st.Updated
+		fresh[item.Id] = st
+	}
+
+	b, err := json.MarshalIndent(fresh, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(stateFile, b, 0644)
+	}
+	if err != nil {
+		log.Printf("could not save %s: %v", stateFile, err)
+	}
+}
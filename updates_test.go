@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+func TestMarkUpdated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radiorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := itemsStateFile(dir, "57083")
+	if got, want := file, filepath.Join(dir, ".57083.items.json"); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	items := func(descriptions ...string) []*feedItem {
+		var items []*feedItem
+		for i, d := range descriptions {
+			items = append(items, &feedItem{Item: &feeds.Item{Id: string(rune('a' + i)), Title: "t", Description: d}})
+		}
+		return items
+	}
+	first := time.Date(2020, 1, 19, 14, 10, 0, 0, moscow)
+	second, third := first.Add(time.Hour), first.Add(2*time.Hour)
+
+	run := items("one", "two")
+	markUpdated(run, file, first)
+	for _, item := range run {
+		if !item.Updated.IsZero() {
+			t.Errorf("%s: new item marked updated at %v", item.Id, item.Updated)
+		}
+	}
+
+	run = items("one", "two, edited")
+	markUpdated(run, file, second)
+	if !run[0].Updated.IsZero() || !run[1].Updated.Equal(second) {
+		t.Errorf("want b updated at %v, got %v and %v", second, run[0].Updated, run[1].Updated)
+	}
+
+	// a failed page does not count as an edit, the update time is kept
+	run = items("", "two, edited")
+	markUpdated(run, file, third)
+	if !run[1].Updated.Equal(second) {
+		t.Errorf("want b updated at %v, got %v", second, run[1].Updated)
+	}
+	run = items("one", "two, edited")
+	markUpdated(run, file, third)
+	if !run[0].Updated.IsZero() {
+		t.Errorf("a marked updated at %v", run[0].Updated)
+	}
+
+	feed := &brandFeed{Feed: feeds.Feed{Title: "f", Link: &feeds.Link{Href: "l"}}, Items: run}
+	for _, item := range run {
+		item.Link = &feeds.Link{Href: "l"}
+	}
+	got := string(createFeed(feed))
+	if want := "<atom:updated>2020-01-19T15:10:00+03:00</atom:updated>"; !strings.Contains(got, want) || strings.Count(got, "<atom:updated>") != 1 {
+		t.Errorf("want one %s in %s", want, got)
+	}
+	assertStringContains(t, got, `xmlns:atom="http://www.w3.org/2005/Atom"`)
+}
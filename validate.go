@@ -0,0 +1,299 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+// minImageSize and maxImageSize are the artwork dimensions podcast
+// directories expect
+const (
+	minImageSize = 1400
+	maxImageSize = 3000
+)
+
+// rfc822Layouts are the date formats acceptable in RSS 2.0
+var rfc822Layouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+func validateCmd(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	path := fs.String("path", "./", "path to look for the RSS file in")
+	brand := fs.String("brand", "57083", "brand number (defaults to Aerostat)")
+	offline := fs.Bool("offline", false, "do not check enclosure and image URLs")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate [options] [file ...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{feedFilename(*path, *brand)}
+	}
+
+	var failed bool
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, w := range validateFeed(b, !*offline) {
+			failed = true
+			fmt.Printf("%s: %s\n", file, w)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// validateFeed checks the RSS document against RSS 2.0 and common podcast
+// requirements, returns the list of problems found; online checks
+// involve fetching the enclosures and the image
+func validateFeed(b []byte, online bool) (warnings []string) {
+	warn := func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+
+	channel, err := parseFeed(b)
+	if err != nil {
+		warn("not a valid RSS document: %v", err)
+		return
+	}
+
+	if channel.Title == "" {
+		warn("channel has no title")
+	}
+	if channel.Link == "" {
+		warn("channel has no link")
+	}
+	if channel.Description == "" {
+		warn("channel has no description, podcast directories require one")
+	}
+	if channel.PubDate != "" && !isRFC822(channel.PubDate) {
+		warn("channel pubDate %q is not an RFC 822 date", channel.PubDate)
+	}
+
+	if channel.Image == nil || channel.Image.Url == "" {
+		warn("channel has no image, podcast directories require artwork")
+	} else if online {
+		if err := checkImage(channel.Image.Url); err != nil {
+			warn("channel image %s: %v", channel.Image.Url, err)
+		}
+	}
+
+	if len(channel.Items) == 0 {
+		warn("channel has no items")
+	}
+
+	guids := make(map[string]bool, len(channel.Items))
+	for i, item := range channel.Items {
+		name := fmt.Sprintf("item %d (%q)", i+1, item.Title)
+		if item.Title == "" && item.Description == "" {
+			warn("%s has neither title nor description", name)
+		}
+		if item.Guid == "" {
+			warn("%s has no guid, clients will not be able to track it", name)
+		} else if guids[item.Guid] {
+			warn("%s has a duplicate guid %s", name, item.Guid)
+		}
+		guids[item.Guid] = true
+		if item.PubDate == "" {
+			warn("%s has no pubDate", name)
+		} else if !isRFC822(item.PubDate) {
+			warn("%s pubDate %q is not an RFC 822 date", name, item.PubDate)
+		}
+		if item.Enclosure == nil {
+			warn("%s has no enclosure, podcast clients will not be able to play it", name)
+			continue
+		}
+		for _, w := range checkEnclosure(item.Enclosure, online) {
+			warn("%s enclosure %s", name, w)
+		}
+	}
+
+	return
+}
+
+func isRFC822(s string) bool {
+	for _, layout := range rfc822Layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func checkEnclosure(enc *feeds.RssEnclosure, online bool) (warnings []string) {
+	if enc.Url == "" {
+		return []string{"has no URL"}
+	}
+	if enc.Type == "" {
+		warnings = append(warnings, "has no type")
+	}
+	if _, err := strconv.ParseInt(enc.Length, 10, 64); err != nil {
+		warnings = append(warnings, fmt.Sprintf("length %q is not a number", enc.Length))
+	}
+	if online {
+		if err := checkURL(enc.Url); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", enc.Url, err))
+		}
+	}
+	return
+}
+
+var checkClient = &http.Client{Timeout: 30 * time.Second}
+
+// checkURL makes sure the URL resolves to something that can be downloaded
+func checkURL(u string) error {
+	res, err := checkClient.Head(u)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("unavailable: %s", res.Status)
+	}
+	return nil
+}
+
+// checkImage makes sure the image can be downloaded and is of size
+// acceptable for podcast directories
+func checkImage(u string) error {
+	res, err := checkClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("unavailable: %s", res.Status)
+	}
+	cfg, _, err := image.DecodeConfig(res.Body)
+	if err != nil {
+		return fmt.Errorf("could not decode image: %w", err)
+	}
+	if cfg.Width != cfg.Height {
+		return fmt.Errorf("image is %dx%d, should be square", cfg.Width, cfg.Height)
+	}
+	if cfg.Width < minImageSize || cfg.Width > maxImageSize {
+		return fmt.Errorf("image is %dx%d, should be between %dx%[3]d and %dx%[4]d", cfg.Width, cfg.Height, minImageSize, maxImageSize)
+	}
+	return nil
+}
+
+// appleCategories are the top-level categories Apple Podcasts accepts
+var appleCategories = map[string]bool{
+	"Arts":                    true,
+	"Business":                true,
+	"Comedy":                  true,
+	"Education":               true,
+	"Fiction":                 true,
+	"Government":              true,
+	"Health & Fitness":        true,
+	"History":                 true,
+	"Kids & Family":           true,
+	"Leisure":                 true,
+	"Music":                   true,
+	"News":                    true,
+	"Religion & Spirituality": true,
+	"Science":                 true,
+	"Society & Culture":       true,
+	"Sports":                  true,
+	"TV & Film":               true,
+	"Technology":              true,
+	"True Crime":              true,
+}
+
+// itunesFeed holds the parts of the feed Apple Podcasts is picky about
+type itunesFeed struct {
+	Channel struct {
+		Language string `xml:"language"`
+		Image    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Categories []struct {
+			Text string `xml:"text,attr"`
+		} `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd category"`
+		Explicit   string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+		Author     string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+		OwnerEmail string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd owner>email"`
+	} `xml:"channel"`
+}
+
+// validateApple checks the RSS document against Apple Podcasts
+// requirements on top of the generic ones, returns the list of problems
+// that would get the feed rejected
+func validateApple(b []byte, online bool) []string {
+	warnings := validateFeed(b, online)
+	warn := func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+
+	var feed itunesFeed
+	if err := xml.Unmarshal(b, &feed); err != nil {
+		return warnings
+	}
+	ch := feed.Channel
+
+	if ch.Language == "" {
+		warn("channel has no language")
+	}
+	if ch.Explicit == "" {
+		warn("channel has no itunes:explicit")
+	} else if e := strings.ToLower(ch.Explicit); e != "true" && e != "false" && e != "yes" && e != "no" && e != "clean" {
+		warn("channel itunes:explicit %q should be true or false", ch.Explicit)
+	}
+	if len(ch.Categories) == 0 {
+		warn("channel has no itunes:category")
+	}
+	for _, c := range ch.Categories {
+		if !appleCategories[c.Text] {
+			warn("channel itunes:category %q is not an Apple Podcasts category", c.Text)
+		}
+	}
+	if ch.Image.Href == "" {
+		warn("channel has no itunes:image")
+	} else if online {
+		if err := checkImage(ch.Image.Href); err != nil {
+			warn("channel itunes:image %s: %v", ch.Image.Href, err)
+		}
+	}
+
+	return warnings
+}
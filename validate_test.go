@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateGolden(t *testing.T) {
+	page := helperLoadBytes(t, "TestServedFeed.golden")
+	if got := validateFeed(page, false); len(got) != 0 {
+		t.Fatalf("want no warnings, got %v", got)
+	}
+}
+
+func TestValidateFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			_ = png.Encode(w, image.NewGray(image.Rect(0, 0, 300, 300)))
+		case "/audio.mp3":
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0">
+  <channel>
+    <title>Аэростат</title>
+    <link>https://smotrim.ru/brand/57083</link>
+    <description></description>
+    <image>
+      <url>%[1]s/image.png</url>
+    </image>
+    <item>
+      <title>good</title>
+      <guid>1</guid>
+      <pubDate>Sun, 24 Nov 2019 14:10:00 +0300</pubDate>
+      <enclosure url="%[1]s/audio.mp3" length="1024" type="audio/mpeg"></enclosure>
+    </item>
+    <item>
+      <title>bad</title>
+      <guid>1</guid>
+      <pubDate>24.11.2019</pubDate>
+      <enclosure url="%[1]s/missing.mp3" length="" type="audio/mpeg"></enclosure>
+    </item>
+    <item>
+      <title>worse</title>
+    </item>
+  </channel>
+</rss>`, server.URL)
+
+	want := []string{
+		"channel has no description",
+		"channel image " + server.URL + "/image.png: image is 300x300",
+		`item 2 ("bad") has a duplicate guid 1`,
+		`item 2 ("bad") pubDate "24.11.2019" is not an RFC 822 date`,
+		`item 2 ("bad") enclosure length "" is not a number`,
+		`item 2 ("bad") enclosure ` + server.URL + "/missing.mp3: unavailable: 404",
+		`item 3 ("worse") has no guid`,
+		`item 3 ("worse") has no pubDate`,
+		`item 3 ("worse") has no enclosure`,
+	}
+
+	got := validateFeed([]byte(feed), true)
+	if len(got) != len(want) {
+		t.Fatalf("want %d warnings, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("want %q, got %q", want[i], got[i])
+		}
+	}
+}
+
+func TestValidateNotRSS(t *testing.T) {
+	got := validateFeed([]byte("<html></html>"), false)
+	if len(got) != 1 {
+		t.Fatalf("want a single warning, got %v", got)
+	}
+	assertStringContains(t, got[0], "not a valid RSS document")
+}
+
+func TestValidateApple(t *testing.T) {
+	page := helperLoadBytes(t, "TestServedFeed.golden")
+	got := validateApple(page, false)
+	want := []string{
+		"channel has no language",
+		"channel has no itunes:explicit",
+		"channel has no itunes:category",
+		"channel has no itunes:image",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	page = bytes.Replace(page, []byte("<channel>"), []byte(`<channel>
+    <language>ru</language>
+    <itunes:explicit>false</itunes:explicit>
+    <itunes:category text="Music"></itunes:category>
+    <itunes:category text="Музыка"></itunes:category>
+    <itunes:image href="https://example.com/image.jpg"></itunes:image>`), 1)
+	page = bytes.Replace(page, []byte("<rss "), []byte(`<rss xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" `), 1)
+	got = validateApple(page, false)
+	if len(got) != 1 {
+		t.Fatalf("want a single warning, got %v", got)
+	}
+	assertStringContains(t, got[0], `"Музыка" is not an Apple Podcasts category`)
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// warcFile is where the fetched pages are archived to, they are
+	// not archived if empty
+	warcFile string
+
+	pageArchive *warcWriter
+)
+
+// warcWriter appends WARC 1.1 records to a file; every record is
+// a separate gzip member if the file name ends with .gz, as is
+// customary for .warc.gz
+type warcWriter struct {
+	mu sync.Mutex
+	f  *os.File
+	gz bool
+}
+
+func openWARC(name string) (*warcWriter, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
+	if err != nil {
+		return nil, err
+	}
+	w := &warcWriter{f: f, gz: strings.HasSuffix(name, ".gz")}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fi.Size() == 0 {
+		info := "software: radiorus-rss\r\nformat: WARC File Format 1.1\r\n"
+		err = w.write("warcinfo", "", "", "application/warc-fields", []byte(info), time.Now(), nil)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// archive records the request and the response with the body as read;
+// the headers are the ones Go's HTTP client left after decompressing
+// the body, if it did
+func (w *warcWriter) archive(res *http.Response, body []byte) error {
+	now := time.Now()
+	u := res.Request.URL.String()
+
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "%s %s HTTP/1.1\r\nHost: %s\r\n", res.Request.Method, res.Request.URL.RequestURI(), res.Request.URL.Host)
+	_ = res.Request.Header.Write(&req)
+	req.WriteString("\r\n")
+
+	var resp bytes.Buffer
+	fmt.Fprintf(&resp, "%s %s\r\n", res.Proto, res.Status)
+	_ = res.Header.Write(&resp)
+	resp.WriteString("\r\n")
+	resp.Write(body)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := newRecordID()
+	if err := w.write("response", id, u, "application/http;msgtype=response", resp.Bytes(), now, nil); err != nil {
+		return err
+	}
+	return w.write("request", newRecordID(), u, "application/http;msgtype=request", req.Bytes(), now, []string{"WARC-Concurrent-To: " + id})
+}
+
+// write appends a record; the caller must hold w.mu once the writer
+// is shared
+func (w *warcWriter) write(typ, id, u, contentType string, block []byte, date time.Time, extra []string) error {
+	if id == "" {
+		id = newRecordID()
+	}
+	digest := sha1.Sum(block)
+
+	var rec bytes.Buffer
+	rec.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&rec, "WARC-Type: %s\r\n", typ)
+	fmt.Fprintf(&rec, "WARC-Record-ID: %s\r\n", id)
+	fmt.Fprintf(&rec, "WARC-Date: %s\r\n", date.UTC().Format(time.RFC3339))
+	if u != "" {
+		fmt.Fprintf(&rec, "WARC-Target-URI: %s\r\n", u)
+	}
+	for _, h := range extra {
+		rec.WriteString(h + "\r\n")
+	}
+	fmt.Fprintf(&rec, "WARC-Block-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	fmt.Fprintf(&rec, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&rec, "Content-Length: %d\r\n\r\n", len(block))
+	rec.Write(block)
+	rec.WriteString("\r\n\r\n")
+
+	if !w.gz {
+		_, err := w.f.Write(rec.Bytes())
+		return err
+	}
+	// the member is compressed in memory to be appended at once
+	var z bytes.Buffer
+	zw := gzip.NewWriter(&z)
+	if _, err := zw.Write(rec.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	_, err := w.f.Write(z.Bytes())
+	return err
+}
+
+func (w *warcWriter) Close() error {
+	return w.f.Close()
+}
+
+// newRecordID makes a random (version 4) UUID record ID
+func newRecordID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
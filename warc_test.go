@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWARC(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html>Аэростат</html>"))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "radiorus-warc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"pages.warc", "pages.warc.gz"} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(dir, name)
+			for i := 0; i < 2; i++ {
+				// the second run appends to the file
+				w, err := openWARC(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pageArchive = w
+				_, _, err = fetchPage(context.Background(), ts.URL+"/brand/57083/episodes")
+				pageArchive = nil
+				w.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var b []byte
+			if strings.HasSuffix(name, ".gz") {
+				zr, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err = ioutil.ReadAll(zr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if b, err = ioutil.ReadAll(f); err != nil {
+				t.Fatal(err)
+			}
+			warc := string(b)
+
+			for typ, want := range map[string]int{"warcinfo": 1, "response": 2, "request": 2} {
+				if got := strings.Count(warc, "WARC-Type: "+typ+"\r\n"); got != want {
+					t.Errorf("want %d %s records, got %d", want, typ, got)
+				}
+			}
+			for _, want := range []string{
+				"WARC-Target-URI: " + ts.URL + "/brand/57083/episodes\r\n",
+				"HTTP/1.1 200 OK\r\n",
+				"GET /brand/57083/episodes HTTP/1.1\r\n",
+				"\r\n\r\n<html>Аэростат</html>\r\n\r\n",
+			} {
+				if !strings.Contains(warc, want) {
+					t.Errorf("no %q in\n%s", want, warc)
+				}
+			}
+			id := regexp.MustCompile(`WARC-Type: response\r\nWARC-Record-ID: (<urn:uuid:[0-9a-f-]{36}>)`).FindStringSubmatch(warc)
+			if id == nil || !strings.Contains(warc, "WARC-Concurrent-To: "+id[1]) {
+				t.Error("request record not linked to the response")
+			}
+		})
+	}
+}
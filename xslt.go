@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// defaultXSLTName is the file name the bundled stylesheet is written to
+// when -xslt is set to "default"
+const defaultXSLTName = "radiorus.xsl"
+
+// defaultXSLT renders the feed as a readable page in a browser
+const defaultXSLT = `<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:output method="html" encoding="UTF-8" indent="yes"/>
+  <xsl:template match="/rss/channel">
+    <html lang="ru">
+      <head>
+        <meta charset="utf-8"/>
+        <title><xsl:value-of select="title"/></title>
+        <style>
+          body { font-family: sans-serif; max-width: 50em; margin: 0 auto; padding: 1em; }
+          .item { border-top: 1px solid #ccc; padding: 1em 0; }
+          .date { color: #666; }
+        </style>
+      </head>
+      <body>
+        <p>Это RSS-лента подкаста. Чтобы подписаться, скопируйте адрес этой страницы в приложение для подкастов.</p>
+        <xsl:if test="image/url">
+          <img src="{image/url}" alt="{image/title}" width="200"/>
+        </xsl:if>
+        <h1><a href="{link}"><xsl:value-of select="title"/></a></h1>
+        <p><xsl:value-of select="description"/></p>
+        <xsl:for-each select="item">
+          <div class="item">
+            <h2><a href="{link}"><xsl:value-of select="title"/></a></h2>
+            <p class="date"><xsl:value-of select="pubDate"/></p>
+            <p><xsl:value-of select="description"/></p>
+            <xsl:if test="enclosure/@url">
+              <audio controls="controls" preload="none" src="{enclosure/@url}"></audio>
+            </xsl:if>
+          </div>
+        </xsl:for-each>
+      </body>
+    </html>
+  </xsl:template>
+</xsl:stylesheet>
+`
+
+// addStylesheet inserts the xml-stylesheet processing instruction
+// right after the XML declaration
+func addStylesheet(output []byte, href string) []byte {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(href))
+	pi := fmt.Sprintf(`<?xml-stylesheet type="text/xsl" href="%s"?>`, buf.String())
+
+	decl := []byte(xml.Header[:len(xml.Header)-1])
+	if !bytes.HasPrefix(output, decl) {
+		return append([]byte(pi), output...)
+	}
+	res := make([]byte, 0, len(output)+len(pi))
+	res = append(res, decl...)
+	res = append(res, pi...)
+	return append(res, output[len(decl):]...)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2026 Evgeny Kuznetsov (evgeny@kuznetsov.md)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAddStylesheet(t *testing.T) {
+	page := helperLoadBytes(t, "TestServedFeed.golden")
+	got := addStylesheet(page, "/feeds/style.xsl?a=1&b=2")
+
+	want := `<?xml version="1.0" encoding="UTF-8"?><?xml-stylesheet type="text/xsl" href="/feeds/style.xsl?a=1&amp;b=2"?><rss version="2.0"`
+	if !strings.HasPrefix(string(got), want) {
+		t.Fatalf("want prefix %s, got %s", want, got[:len(want)])
+	}
+
+	if _, err := parseFeed(got); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultXSLT(t *testing.T) {
+	d := xml.NewDecoder(strings.NewReader(defaultXSLT))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}